@@ -8,7 +8,9 @@ require (
 	github.com/josharian/impl v1.0.0 // indirect
 	github.com/k-jun/northpole v0.1.7
 	github.com/stretchr/testify v1.6.1
+	golang.org/x/crypto v0.0.0-20201217014255-9d1352758620
 	golang.org/x/mod v0.4.0 // indirect
+	golang.org/x/net v0.0.0-20201021035429-f5854403a974
 	golang.org/x/tools v0.0.0-20210104081019-d8d6ddbec6ee // indirect
 	google.golang.org/appengine v1.4.0
 )
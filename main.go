@@ -2,20 +2,103 @@ package main
 
 import (
 	"log"
+	"mahjong/model/agent"
+	"mahjong/model/bot"
 	"mahjong/server"
 	"net"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "simulate":
+			simulate(os.Args[2:])
+			return
+		case "verify":
+			verify(os.Args[2:])
+			return
+		case "replay":
+			replay(os.Args[2:])
+			return
+		}
+	}
+	serve()
+}
+
+func serve() {
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
 	}
+	// the wait before the bots fill the empty seats, 0 never
+	botWait := 30 * time.Second
+	if env := os.Getenv("BOT_WAIT"); env != "" {
+		d, err := time.ParseDuration(env)
+		if err != nil {
+			log.Fatal(err)
+		}
+		botWait = d
+	}
+	// deal every board from the seed to reproduce a reported game, for debugging
+	var seed int64
+	if env := os.Getenv("SEED"); env != "" {
+		n, err := strconv.ParseInt(env, 10, 64)
+		if err != nil {
+			log.Fatal(err)
+		}
+		seed = n
+	}
+	// the directory of the paifu files, empty not to write them
+	paifuDir := os.Getenv("PAIFU_DIR")
+	// the external program playing the bot seats, the baseline bot if empty
+	newAgent := agentFunc(os.Getenv("AGENT"))
 	ln, err := net.Listen("tcp", ":"+port)
 	if err != nil {
 		log.Fatal(err)
 	}
-	s := server.New(ln)
+	// the port of the browser client, empty not to serve it
+	var webLn net.Listener
+	if webPort := os.Getenv("WEB_PORT"); webPort != "" {
+		if webLn, err = net.Listen("tcp", ":"+webPort); err != nil {
+			log.Fatal(err)
+		}
+	}
+	// the port of the ssh server, empty not to serve it
+	var sshLn net.Listener
+	var hostKey ssh.Signer
+	if sshPort := os.Getenv("SSH_PORT"); sshPort != "" {
+		path := os.Getenv("SSH_HOST_KEY")
+		if path == "" {
+			path = "ssh_host_key"
+		}
+		if hostKey, err = server.LoadHostKey(path); err != nil {
+			log.Fatal(err)
+		}
+		if sshLn, err = net.Listen("tcp", ":"+sshPort); err != nil {
+			log.Fatal(err)
+		}
+	}
+	// the host of the browser client in the address bar, the websocket from the other sites is refused
+	webHost := os.Getenv("WEB_HOST")
+	s := server.New(ln, webLn, webHost, sshLn, hostKey, botWait, seed, paifuDir, newAgent)
 	s.Run()
 }
+
+// agentFunc makes the agent running the command line, the baseline bot for empty or `bot`.
+func agentFunc(command string) func() (agent.Agent, error) {
+	fields := strings.Fields(command)
+	if len(fields) == 0 || command == "bot" {
+		return func() (agent.Agent, error) {
+			return bot.New(), nil
+		}
+	}
+	return func() (agent.Agent, error) {
+		return agent.NewProcess(fields[0], fields[1:]...)
+	}
+}
@@ -0,0 +1,271 @@
+package agent
+
+import (
+	"mahjong/model/board"
+	"mahjong/model/hai"
+	"mahjong/model/player"
+	"strconv"
+	"time"
+)
+
+// Agent decides the action of a seat, it can be a bot in this server or a program outside.
+type Agent interface {
+	// Choose returns the index of the action to take
+	Choose(*State, []*Action) (int, error)
+}
+
+// State is the board seen from the player.
+type State struct {
+	Bakaze   string   `json:"bakaze"`
+	Kyoku    int      `json:"kyoku"`
+	Honba    int      `json:"honba"`
+	Kyoutaku int      `json:"kyoutaku"`
+	Dora     []string `json:"dora"`
+	Tehai    []string `json:"tehai"`
+	Tsumohai string   `json:"tsumohai"`
+	// the player first, then shimocha, toimen and kamicha
+	Seats []*Seat `json:"seats"`
+	// the thinking time left for the decision, 0 for no limit
+	Budget time.Duration `json:"-"`
+}
+
+type Seat struct {
+	// the nickname of the player, omitted for no name
+	Name     string     `json:"name,omitempty"`
+	Jikaze   string     `json:"jikaze"`
+	Point    int        `json:"point"`
+	IsRiichi bool       `json:"is_riichi"`
+	Kawa     []string   `json:"kawa"`
+	Naki     [][]string `json:"naki"`
+}
+
+// Action is one of the legal actions, Command is the input same as a user types.
+type Action struct {
+	Type    board.ActionType `json:"type"`
+	Hais    []string         `json:"hais"`
+	Command string           `json:"command"`
+}
+
+// Decide asks the agent and returns the command of the chosen action, empty when the player has nothing to do.
+// The state and the actions are read with the board locked, the timers change the board meanwhile.
+func Decide(a Agent, b board.Board, p player.Player) (string, error) {
+	base, reserve, err := b.ThinkingTime(p)
+	if err != nil {
+		return "", err
+	}
+	var s *State
+	var actions []*Action
+	if err := b.View(func() error {
+		var err error
+		if actions, err = Actions(b, p); err != nil || len(actions) == 0 {
+			return err
+		}
+		s, err = NewState(b, p)
+		return err
+	}); err != nil || len(actions) == 0 {
+		return "", err
+	}
+	s.Budget = base + reserve
+	idx, err := a.Choose(s, actions)
+	if err != nil {
+		return "", err
+	}
+	if idx < 0 || idx >= len(actions) {
+		return "", AgentInvalidChoiceErr
+	}
+	return actions[idx].Command, nil
+}
+
+// NewState reads the board, call it in board.View not to read it changing.
+func NewState(b board.Board, p player.Player) (*State, error) {
+	idx, err := b.MyTurn(p)
+	if err != nil {
+		return nil, err
+	}
+	s := &State{
+		Bakaze:   b.Bakaze().Name(),
+		Kyoku:    b.Match().Kyoku(),
+		Honba:    b.Match().Honba(),
+		Kyoutaku: b.Match().Kyoutaku(),
+		Dora:     names(b.Yama().OmoteDora()),
+		Tehai:    names(p.Tehai().Hais()),
+		Seats:    []*Seat{},
+	}
+	if p.Tsumohai() != nil {
+		s.Tsumohai = p.Tsumohai().Name()
+	}
+
+	players := b.Players()
+	for i := range players {
+		tp := players[(idx+i)%len(players)]
+		jikaze, err := b.Jikaze(tp.Player)
+		if err != nil {
+			return nil, err
+		}
+		seat := &Seat{
+			Name:     tp.Name(),
+			Jikaze:   jikaze.Name(),
+			Point:    tp.Point(),
+			IsRiichi: tp.IsRiichi(),
+			Kawa:     names(tp.Kawa().Hais()),
+			Naki:     [][]string{},
+		}
+		for _, m := range tp.Naki().Chiis() {
+			seat.Naki = append(seat.Naki, names(append([]*hai.Hai{}, m[:]...)))
+		}
+		for _, m := range tp.Naki().Pons() {
+			seat.Naki = append(seat.Naki, names(append([]*hai.Hai{}, m[:]...)))
+		}
+		for _, m := range tp.Naki().MinKans() {
+			seat.Naki = append(seat.Naki, names(append([]*hai.Hai{}, m[:]...)))
+		}
+		for _, m := range tp.Naki().AnKans() {
+			seat.Naki = append(seat.Naki, names(append([]*hai.Hai{}, m[:]...)))
+		}
+		s.Seats = append(s.Seats, seat)
+	}
+	return s, nil
+}
+
+// Actions lists the legal actions of the player, the same choices shown to a user.
+// call it in board.View as NewState.
+func Actions(b board.Board, p player.Player) ([]*Action, error) {
+	actions := []*Action{}
+	if b.Result() != nil {
+		if !b.Match().IsEnd() {
+			actions = append(actions, &Action{Type: board.Ready, Hais: []string{}, Command: string(board.Ready)})
+		}
+		return actions, nil
+	}
+
+	turnIdx, err := b.MyTurn(p)
+	if err != nil {
+		return actions, err
+	}
+	if b.CurrentTurn() != turnIdx {
+		return callActions(b, p)
+	}
+	if len(b.ActionPlayers()) != 0 {
+		// waiting for the others
+		return actions, nil
+	}
+	if p.Tsumohai() == nil && len(p.Tehai().Hais())%3 != 2 {
+		return actions, nil
+	}
+	return turnActions(p)
+}
+
+func turnActions(p player.Player) ([]*Action, error) {
+	actions := []*Action{}
+	ok, err := p.CanTsumoAgari()
+	if err != nil {
+		return actions, err
+	}
+	if ok {
+		actions = append(actions, &Action{Type: board.Tsumo, Hais: []string{p.Tsumohai().Name()}, Command: string(board.Tsumo)})
+	}
+	if p.IsRiichi() {
+		// tsumogiri only
+		h := p.Tsumohai()
+		return append(actions, &Action{Type: board.Normal, Hais: []string{h.Name()}, Command: h.Name()}), nil
+	}
+
+	ok, err = p.CanRiichi()
+	if err != nil {
+		return actions, err
+	}
+	if ok {
+		hais, err := p.Tehai().RiichiHais(p.Tsumohai())
+		if err != nil {
+			return actions, err
+		}
+		for i, h := range hais {
+			actions = append(actions, &Action{Type: board.Riichi, Hais: []string{h.Name()}, Command: command(board.Riichi, i)})
+		}
+	}
+	ok, err = p.CanAnKan()
+	if err != nil {
+		return actions, err
+	}
+	if ok {
+		pairs, err := p.Tehai().AnKanPairs(p.Tsumohai())
+		if err != nil {
+			return actions, err
+		}
+		for i, pair := range pairs {
+			actions = append(actions, &Action{Type: board.Kan, Hais: names(append([]*hai.Hai{}, pair[:]...)), Command: command(board.Kan, i)})
+		}
+	}
+	for i, h := range p.KakanHais() {
+		actions = append(actions, &Action{Type: board.Kakan, Hais: []string{h.Name()}, Command: command(board.Kakan, i)})
+	}
+
+	hais := append([]*hai.Hai{}, p.Tehai().Hais()...)
+	if p.Tsumohai() != nil {
+		hais = append(hais, p.Tsumohai())
+	}
+	seen := map[*hai.Hai]bool{}
+	for _, h := range hais {
+		if seen[h] {
+			continue
+		}
+		seen[h] = true
+		actions = append(actions, &Action{Type: board.Normal, Hais: []string{h.Name()}, Command: h.Name()})
+	}
+	return actions, nil
+}
+
+func callActions(b board.Board, p player.Player) ([]*Action, error) {
+	actions := []*Action{}
+	types, err := b.MyAction(p)
+	if err != nil || len(types) == 0 {
+		return actions, err
+	}
+	inHai, err := b.LastKawa()
+	if err != nil {
+		return actions, err
+	}
+	for _, t := range types {
+		switch t {
+		case board.Chii:
+			pairs, err := p.Tehai().ChiiPairs(inHai)
+			if err != nil {
+				return actions, err
+			}
+			for i, pair := range pairs {
+				actions = append(actions, &Action{Type: t, Hais: names(append([]*hai.Hai{}, pair[:]...)), Command: command(t, i)})
+			}
+		case board.Pon:
+			pairs, err := p.Tehai().PonPairs(inHai)
+			if err != nil {
+				return actions, err
+			}
+			for i, pair := range pairs {
+				actions = append(actions, &Action{Type: t, Hais: names(append([]*hai.Hai{}, pair[:]...)), Command: command(t, i)})
+			}
+		case board.Kan:
+			pairs, err := p.Tehai().MinKanPairs(inHai)
+			if err != nil {
+				return actions, err
+			}
+			for i, pair := range pairs {
+				actions = append(actions, &Action{Type: t, Hais: names(append([]*hai.Hai{}, pair[:]...)), Command: command(t, i)})
+			}
+		case board.Ron:
+			actions = append(actions, &Action{Type: t, Hais: []string{inHai.Name()}, Command: string(t)})
+		}
+	}
+	return append(actions, &Action{Type: board.Cancel, Hais: []string{}, Command: string(board.Cancel)}), nil
+}
+
+func command(t board.ActionType, idx int) string {
+	return string(t) + " " + strconv.Itoa(idx)
+}
+
+func names(hais []*hai.Hai) []string {
+	strs := []string{}
+	for _, h := range hais {
+		strs = append(strs, h.Name())
+	}
+	return strs
+}
@@ -0,0 +1,11 @@
+package agent
+
+import "errors"
+
+var (
+	AgentInvalidChoiceErr  = errors.New("the agent chose an action not in the list")
+	AgentNoActionErr       = errors.New("no action to choose")
+	AgentProcessExitedErr  = errors.New("the agent process exited without an answer")
+	AgentProcessTimeoutErr = errors.New("the agent process did not answer in the thinking time")
+	AgentProcessKilledErr  = errors.New("the agent process did not exit and was killed")
+)
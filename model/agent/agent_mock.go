@@ -0,0 +1,12 @@
+package agent
+
+var _ Agent = &AgentMock{}
+
+type AgentMock struct {
+	IntMock   int
+	ErrorMock error
+}
+
+func (a *AgentMock) Choose(_ *State, _ []*Action) (int, error) {
+	return a.IntMock, a.ErrorMock
+}
@@ -0,0 +1,167 @@
+package agent
+
+import (
+	"io"
+	"mahjong/model/board"
+	"mahjong/model/hai"
+	"mahjong/model/kawa"
+	"mahjong/model/naki"
+	"mahjong/model/player"
+	"mahjong/model/tehai"
+	"mahjong/model/yama"
+	"mahjong/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTurnActions(t *testing.T) {
+	cases := []struct {
+		name       string
+		inHais     []*hai.Hai
+		inTsumohai *hai.Hai
+		outTypes   []board.ActionType
+		outCommand []string
+	}{
+		{
+			name: "success: tsumo agari",
+			inHais: []*hai.Hai{
+				hai.Manzu2, hai.Manzu3, hai.Manzu4, hai.Pinzu2, hai.Pinzu3, hai.Pinzu4,
+				hai.Souzu2, hai.Souzu3, hai.Souzu4, hai.Souzu6, hai.Souzu7, hai.Pinzu8,
+				hai.Pinzu8,
+			},
+			inTsumohai: hai.Souzu5,
+			outTypes:   []board.ActionType{board.Tsumo},
+			outCommand: []string{"tsumo"},
+		},
+		{
+			name: "success: riichi",
+			inHais: []*hai.Hai{
+				hai.Manzu2, hai.Manzu3, hai.Manzu4, hai.Pinzu2, hai.Pinzu3, hai.Pinzu4,
+				hai.Souzu2, hai.Souzu3, hai.Souzu4, hai.Souzu6, hai.Souzu7, hai.Pinzu8,
+				hai.Pinzu8,
+			},
+			inTsumohai: hai.Ton,
+			outTypes:   []board.ActionType{board.Riichi},
+			outCommand: []string{"riichi 0"},
+		},
+		{
+			name: "success: discard without duplicates",
+			inHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu1, hai.Manzu1, hai.Pinzu1, hai.Pinzu4, hai.Pinzu7,
+				hai.Souzu1, hai.Souzu4, hai.Souzu7, hai.Ton, hai.Nan, hai.Sha,
+				hai.Pei,
+			},
+			inTsumohai: hai.Manzu1,
+			outTypes:   []board.ActionType{board.Kan, board.Normal, board.Normal},
+			outCommand: []string{"kan 0", "m1", "p1"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			th := tehai.New()
+			assert.NoError(t, th.Adds(c.inHais))
+			p := player.New(utils.NewUUID(), kawa.New(), th, naki.New())
+			p.SetKaze(hai.Ton, hai.Nan)
+			assert.NoError(t, p.SetYama(&yama.YamaMock{HaiMock: c.inTsumohai}))
+			assert.NoError(t, p.Tsumo())
+
+			actions, err := turnActions(p)
+			assert.NoError(t, err)
+			for i := range c.outTypes {
+				assert.Equal(t, c.outTypes[i], actions[i].Type)
+				assert.Equal(t, c.outCommand[i], actions[i].Command)
+			}
+		})
+	}
+}
+
+func TestProcess(t *testing.T) {
+	cases := []struct {
+		name     string
+		inArgs   []string
+		inBudget time.Duration
+		outIndex int
+		outError error
+	}{
+		{
+			name:     "success",
+			inArgs:   []string{"-c", `while read l; do echo '{"index": 1}'; done`},
+			outIndex: 1,
+		},
+		{
+			name:     "failure: exited",
+			inArgs:   []string{"-c", "exit 0"},
+			outError: AgentProcessExitedErr,
+		},
+		{
+			name:     "failure: timeout",
+			inArgs:   []string{"-c", "cat > /dev/null"},
+			inBudget: 100 * time.Millisecond,
+			outError: AgentProcessTimeoutErr,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			a, err := NewProcess("sh", c.inArgs...)
+			assert.NoError(t, err)
+			idx, err := a.Choose(&State{Budget: c.inBudget}, []*Action{})
+			if c.outError == AgentProcessExitedErr && err != AgentProcessExitedErr {
+				// the write can fail first when the process is already gone
+				assert.Error(t, err)
+			} else {
+				assert.Equal(t, c.outError, err)
+			}
+			assert.Equal(t, c.outIndex, idx)
+		})
+	}
+}
+
+func TestProcessLateAnswer(t *testing.T) {
+	// the first answer comes after the thinking time, the next request gets its own answer
+	a, err := NewProcess("sh", "-c", `read l; sleep 0.3; echo '{"index": 1}'; read l; echo '{"index": 2}'; cat > /dev/null`)
+	assert.NoError(t, err)
+	_, err = a.Choose(&State{Budget: 100 * time.Millisecond}, []*Action{})
+	assert.Equal(t, AgentProcessTimeoutErr, err)
+	idx, err := a.Choose(&State{}, []*Action{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, idx)
+	assert.NoError(t, a.(io.Closer).Close())
+}
+
+func TestProcessClose(t *testing.T) {
+	ProcessCloseWait = 100 * time.Millisecond
+	cases := []struct {
+		name     string
+		inArgs   []string
+		outError error
+	}{
+		{
+			name:   "success: exits on the EOF",
+			inArgs: []string{"-c", "cat > /dev/null"},
+		},
+		{
+			name:     "failure: never exits",
+			inArgs:   []string{"-c", "trap '' TERM; while :; do sleep 0.05; done"},
+			outError: AgentProcessKilledErr,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			a, err := NewProcess("sh", c.inArgs...)
+			assert.NoError(t, err)
+			closed := make(chan error, 1)
+			go func() { closed <- a.(io.Closer).Close() }()
+			select {
+			case err := <-closed:
+				assert.Equal(t, c.outError, err)
+			case <-time.After(5 * time.Second):
+				t.Fatal("the close does not return")
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package agent
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// Request is the line written to the process, one json per line.
+type Request struct {
+	State   *State    `json:"state"`
+	Actions []*Action `json:"actions"`
+}
+
+// Response is the line the process answers.
+type Response struct {
+	Index int `json:"index"`
+}
+
+var (
+	// the wait for the exit of the process after its stdin is closed, the process is killed after it
+	ProcessCloseWait = 3 * time.Second
+)
+
+// processImpl is the agent running outside, it speaks json over the stdin and stdout.
+type processImpl struct {
+	sync.Mutex
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	// the lines answered by the process, closed at its exit
+	lines chan []byte
+	// the answers of the requests timed out, skipped when they come late
+	late int
+}
+
+func NewProcess(name string, args ...string) (Agent, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	pr := &processImpl{cmd: cmd, stdin: stdin, lines: make(chan []byte, 1)}
+	go pr.read(scanner)
+	return pr, nil
+}
+
+// read passes the lines of the process to Choose, the read itself has no deadline.
+func (pr *processImpl) read(scanner *bufio.Scanner) {
+	for scanner.Scan() {
+		pr.lines <- append([]byte{}, scanner.Bytes()...)
+	}
+	if err := scanner.Err(); err != nil {
+		log.Println(err)
+	}
+	close(pr.lines)
+}
+
+// Choose waits for the answer for the thinking time of the state,
+// the board plays the tsumogiri or the pass when it fails.
+func (pr *processImpl) Choose(s *State, actions []*Action) (int, error) {
+	pr.Lock()
+	defer pr.Unlock()
+
+	bytes, err := json.Marshal(&Request{State: s, Actions: actions})
+	if err != nil {
+		return 0, err
+	}
+	if _, err := pr.stdin.Write(append(bytes, '\n')); err != nil {
+		return 0, err
+	}
+	var timeout <-chan time.Time
+	if s.Budget > 0 {
+		timer := time.NewTimer(s.Budget)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	for {
+		select {
+		case line, ok := <-pr.lines:
+			if !ok {
+				return 0, AgentProcessExitedErr
+			}
+			if pr.late > 0 {
+				pr.late--
+				continue
+			}
+			res := &Response{}
+			if err := json.Unmarshal(line, res); err != nil {
+				return 0, err
+			}
+			return res.Index, nil
+		case <-timeout:
+			pr.late++
+			return 0, AgentProcessTimeoutErr
+		}
+	}
+}
+
+// Close closes the stdin of the process and waits for the exit, the process ignoring the EOF is killed.
+func (pr *processImpl) Close() error {
+	pr.Lock()
+	defer pr.Unlock()
+	// the late answers nobody waits for
+	go func() {
+		for range pr.lines {
+		}
+	}()
+	if err := pr.stdin.Close(); err != nil {
+		return err
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- pr.cmd.Wait() }()
+	timer := time.NewTimer(ProcessCloseWait)
+	defer timer.Stop()
+	select {
+	case err := <-exited:
+		return err
+	case <-timer.C:
+	}
+	if err := pr.cmd.Process.Kill(); err != nil {
+		return err
+	}
+	<-exited
+	return AgentProcessKilledErr
+}
@@ -2,13 +2,25 @@ package board
 
 import (
 	"mahjong/model/hai"
+	"mahjong/model/kawa"
+	"mahjong/model/match"
+	"mahjong/model/naki"
+	"mahjong/model/paifu"
 	"mahjong/model/player"
+	"mahjong/model/score"
+	"mahjong/model/tehai"
 	"mahjong/model/yama"
+	"math/rand"
 	"sync"
+	"time"
 )
 
 var (
 	MaxNumberOfUsers = 4
+	// the total point the noten players pay to the tenpai players at ryuukyoku
+	NotenBappu = 3000
+	// how long the seat of a disconnected player is kept, it is auto-played meanwhile
+	GracePeriod = 60 * time.Second
 )
 
 type ActionType string
@@ -20,8 +32,13 @@ var (
 	Chii   ActionType = "chii"
 	Pon    ActionType = "pon"
 	Kan    ActionType = "kan"
+	Kakan  ActionType = "kakan"
 	Ron    ActionType = "ron"
 	Cancel ActionType = "no"
+	// ready for the next hand
+	Ready ActionType = "next"
+
+	actionPriority = map[ActionType]int{Ron: 3, Pon: 2, Kan: 2, Chii: 1}
 )
 
 type Board interface {
@@ -29,40 +46,80 @@ type Board interface {
 	Players() []*boardPlayer
 	ActionPlayers() []*boardActionPlayer
 	MaxNumberOfUser() int
+	Yama() yama.Yama
+	Match() match.Match
 	Winner() player.Player
-
-	// setter
-	SetWinner(player.Player) error
+	Result() *Result
+	// the seed of the seats and the walls, the same seed deals the same game
+	Seed() int64
 
 	// game
 	JoinPlayer(player.Player) (chan Board, error)
 	LeavePlayer(player.Player) error
+	Disconnect(player.Player) error
+	Reconnect(player.Player) (chan Board, error)
 	Broadcast()
+	// the player is ready for the next hand
+	Ready(player.Player) error
+
+	// kaze
+	Bakaze() *hai.Hai
+	Jikaze(player.Player) (*hai.Hai, error)
 
 	// turn
 	CurrentTurn() int
 	NextTurn() int
 	MyTurn(player.Player) (int, error)
-	TurnEnd() error
+	// View runs f with the board locked, the getters in f read the board at one moment.
+	// f must not call the methods taking the lock
+	View(f func() error) error
 
 	// last hai
 	LastKawa() (*hai.Hai, error)
 
+	// the actions on the turn of the player, checked and played with the board locked
+	Discard(player.Player, *hai.Hai) error
+	DeclareRiichi(player.Player, *hai.Hai) error
+	TsumoAgari(player.Player) error
+	AnKan(player.Player, [4]*hai.Hai) error
+	Kakan(player.Player, *hai.Hai) error
+	// the ron on the discard or the kakan of the other
+	RonAgari(player.Player) error
+	// the chii, the pon or the kan of the pair at the index for the last discard
+	Call(player.Player, ActionType, int) error
+
 	// actions
 	MyAction(p player.Player) ([]ActionType, error)
+	WaitingPlayers() []player.Player
+	ThinkingTime(player.Player) (time.Duration, time.Duration, error)
 	CancelAction(c player.Player) error
-	TakeAction(player.Player, func(*hai.Hai) error) error
+	TakeAction(player.Player, ActionType, func(*hai.Hai) error) error
 }
 
-func New(maxNOU int, y yama.Yama) Board {
+// New makes the board, the seats and the walls made by newYama are shuffled from the seed.
+// Every event of the match is recorded to pf, nothing is recorded when it is nil.
+func New(maxNOU int, m match.Match, seed int64, newYama func(*rand.Rand) yama.Yama, pf paifu.Paifu) Board {
+	r := rand.New(rand.NewSource(seed))
 	return &boardImpl{
 		players:         []*boardPlayer{},
 		actionPlayers:   []*boardActionPlayer{},
-		yama:            y,
+		readyPlayers:    []player.Player{},
+		seed:            seed,
+		rand:            r,
+		yama:            newYama(r),
+		newYama:         newYama,
+		paifu:           pf,
+		match:           m,
 		turnIndex:       0,
 		maxNumberOfUser: maxNOU,
 		isPlaying:       true,
+		oyaIndex:        0,
+		baseTime:        m.Rule().BaseTime,
+		reserveTime:     m.Rule().ReserveTime,
+		timers:          map[player.Player]*boardTimer{},
+		chankanHai:      nil,
 		winner:          nil,
+		result:          nil,
 	}
 }
 
@@ -70,25 +127,76 @@ type boardImpl struct {
 	sync.Mutex
 	players         []*boardPlayer
 	actionPlayers   []*boardActionPlayer
+	readyPlayers    []player.Player
+	seed            int64
+	rand            *rand.Rand
 	yama            yama.Yama
+	newYama         func(*rand.Rand) yama.Yama
+	match           match.Match
 	turnIndex       int
 	maxNumberOfUser int
 	isPlaying       bool
+	oyaIndex        int
+	// thinking time, no time limit when baseTime is 0
+	baseTime    time.Duration
+	reserveTime time.Duration
+	timers      map[player.Player]*boardTimer
+	// the hai added by kakan, it can be robbed by chankan until everyone passes
+	chankanHai *hai.Hai
 
 	// win
 	winner player.Player
+	result *Result
+
+	// record, the hai drawn by the current turn tells the tsumogiri,
+	// the riichi is accepted with the dahai and doraCount is the dora indicators already recorded
+	paifu        paifu.Paifu
+	drawn        *hai.Hai
+	riichiPlayer player.Player
+	doraCount    int
+}
+
+// Result is the outcome of the game, Points and Tenpais are by turn index.
+type Result struct {
+	Winner player.Player
+	Loser  player.Player
+	Score  *score.Score
+	// the agari hai and the tehai of the winner without it
+	AgariHai    *hai.Hai
+	Tehai       []*hai.Hai
+	Points      []int
+	IsRyuukyoku bool
+	Tenpais     []bool
+	// the oya keeps the seat
+	IsRenchan bool
 }
 
 type boardPlayer struct {
 	channel chan Board
+	// the reserve thinking time left
+	reserve time.Duration
+	// the seat is auto-played until the player reconnects or the grace period ends
+	isDisconnected bool
+	grace          *time.Timer
 	player.Player
 }
 
 type boardActionPlayer struct {
-	actions []ActionType
+	actions  []ActionType
+	decision ActionType
+	action   func(*hai.Hai) error
 	player.Player
 }
 
+func (ap *boardActionPlayer) hasAction(action ActionType) bool {
+	for _, a := range ap.actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
 func (b *boardImpl) Players() []*boardPlayer {
 	return b.players
 }
@@ -101,16 +209,24 @@ func (b *boardImpl) MaxNumberOfUser() int {
 	return b.maxNumberOfUser
 }
 
+func (b *boardImpl) Yama() yama.Yama {
+	return b.yama
+}
+
+func (b *boardImpl) Match() match.Match {
+	return b.match
+}
+
 func (b *boardImpl) Winner() player.Player {
 	return b.winner
 }
 
-func (t *boardImpl) SetWinner(p player.Player) error {
-	if p == nil {
-		return BoardPlayerNilError
-	}
-	t.winner = p
-	return nil
+func (b *boardImpl) Result() *Result {
+	return b.result
+}
+
+func (b *boardImpl) Seed() int64 {
+	return b.seed
 }
 
 func (t *boardImpl) JoinPlayer(c player.Player) (chan Board, error) {
@@ -123,10 +239,15 @@ func (t *boardImpl) JoinPlayer(c player.Player) (chan Board, error) {
 	if err := c.SetYama(t.yama); err != nil {
 		return nil, err
 	}
+	// start the match with the point of the rule
+	c.AddPoint(t.match.Rule().StartPoint - c.Point())
 	channel := make(chan Board, t.maxNumberOfUser*3)
-	t.players = append(t.players, &boardPlayer{Player: c, channel: channel})
+	t.players = append(t.players, &boardPlayer{Player: c, channel: channel, reserve: t.reserveTime})
 
 	if len(t.players) >= t.maxNumberOfUser {
+		// decide the seats
+		t.rand.Shuffle(len(t.players), func(i, j int) { t.players[i], t.players[j] = t.players[j], t.players[i] })
+		t.recordStartGame()
 		t.gameStart()
 		go t.Broadcast()
 	}
@@ -137,24 +258,115 @@ func (t *boardImpl) JoinPlayer(c player.Player) (chan Board, error) {
 func (t *boardImpl) LeavePlayer(c player.Player) error {
 	t.Lock()
 	defer t.Unlock()
+	return t.leavePlayer(c)
+}
+
+func (t *boardImpl) leavePlayer(c player.Player) error {
 	// terminate the game
 	if t.isPlaying {
+		if len(t.players) == t.maxNumberOfUser && !t.match.IsEnd() {
+			// the match is over on the way
+			t.record(paifu.EndGameEvent(t.scores()))
+		}
 		t.isPlaying = false
+		t.setTimers()
 		for _, tu := range t.players {
+			if tu.grace != nil {
+				tu.grace.Stop()
+			}
+			if t.match.IsEnd() && !tu.isDisconnected {
+				// the broadcast of the end can come after the close, the others read the end before it
+				select {
+				case tu.channel <- t:
+				default:
+				}
+			}
 			close(tu.channel)
 		}
-		t.players = []*boardPlayer{}
+		if !t.match.IsEnd() {
+			t.players = []*boardPlayer{}
+		}
+		// the others still show the result of the match after the end
 	}
 	return nil
 }
 
 func (t *boardImpl) Broadcast() {
+	t.Lock()
+	defer t.Unlock()
+	if !t.isPlaying {
+		// the channels are closed
+		return
+	}
+	t.setTimers()
 	for _, tu := range t.players {
-		tu.channel <- t
+		if tu.isDisconnected {
+			// redrawn on reconnect
+			continue
+		}
+		select {
+		case tu.channel <- t:
+		default:
+			// the channel is full of the same board, the player reads the latest state anyway
+		}
 	}
 }
 
+func (t *boardImpl) Ready(p player.Player) error {
+	t.Lock()
+	defer t.Unlock()
+	return t.ready(p)
+}
+
+func (t *boardImpl) ready(p player.Player) error {
+	if t.result == nil || t.match.IsEnd() {
+		return BoardNotReadyErr
+	}
+	if _, err := t.MyTurn(p); err != nil {
+		return err
+	}
+	for _, rp := range t.readyPlayers {
+		if rp == p {
+			return nil
+		}
+	}
+	t.readyPlayers = append(t.readyPlayers, p)
+
+	if len(t.readyPlayers) == len(t.players) {
+		if err := t.nextHand(); err != nil {
+			return err
+		}
+		go t.Broadcast()
+	}
+	return nil
+}
+
+// nextHand deals the next hand with fresh instances, the players and the points are kept.
+func (t *boardImpl) nextHand() error {
+	t.yama = t.newYama(t.rand)
+	for _, tp := range t.players {
+		tp.Reset(kawa.New(), tehai.New(), naki.New(), t.yama)
+	}
+	t.actionPlayers = []*boardActionPlayer{}
+	t.readyPlayers = []player.Player{}
+	t.chankanHai = nil
+	t.winner = nil
+	t.result = nil
+	t.riichiPlayer = nil
+	return t.gameStart()
+}
+
 func (t *boardImpl) gameStart() error {
+	t.oyaIndex = t.match.OyaIndex()
+	t.turnIndex = t.oyaIndex
+	for _, tp := range t.players {
+		jikaze, err := t.Jikaze(tp.Player)
+		if err != nil {
+			return err
+		}
+		tp.SetKaze(t.Bakaze(), jikaze)
+	}
+
 	// tehai assign
 	for _, tc := range t.players {
 		if err := tc.Haipai(); err != nil {
@@ -162,8 +374,28 @@ func (t *boardImpl) gameStart() error {
 		}
 	}
 
+	t.recordStartKyoku()
+
 	// tsumo
-	return t.players[t.CurrentTurn()].Tsumo()
+	tp := t.players[t.CurrentTurn()]
+	if err := tp.Tsumo(); err != nil {
+		return err
+	}
+	t.recordTsumo(tp.Player)
+	return nil
+}
+
+func (t *boardImpl) Bakaze() *hai.Hai {
+	return t.match.Bakaze()
+}
+
+// Jikaze is decided by the seat from the oya, the oya is always ton.
+func (t *boardImpl) Jikaze(p player.Player) (*hai.Hai, error) {
+	idx, err := t.MyTurn(p)
+	if err != nil {
+		return nil, err
+	}
+	return match.Bakazes[(idx-t.oyaIndex+len(t.players))%len(t.players)], nil
 }
 
 func (t *boardImpl) CurrentTurn() int {
@@ -183,19 +415,177 @@ func (t *boardImpl) NextTurn() int {
 	return (t.turnIndex + 1) % t.maxNumberOfUser
 }
 
-func (t *boardImpl) TurnEnd() error {
+func (t *boardImpl) View(f func() error) error {
+	t.Lock()
+	defer t.Unlock()
+	return f()
+}
+
+// checkTurn is nil when the player has the turn, not waiting for the calls of the others.
+func (t *boardImpl) checkTurn(p player.Player) error {
+	idx, err := t.MyTurn(p)
+	if err != nil {
+		return err
+	}
+	if t.result != nil || idx != t.turnIndex || len(t.actionPlayers) != 0 || t.chankanHai != nil {
+		return BoardNotYourTurnErr
+	}
+	return nil
+}
+
+// Discard plays the dahai of the player and ends the turn.
+func (t *boardImpl) Discard(p player.Player, h *hai.Hai) error {
 	t.Lock()
 	defer t.Unlock()
+	if err := t.checkTurn(p); err != nil {
+		return err
+	}
+	if err := p.Dahai(h); err != nil {
+		return err
+	}
+	t.answered(p)
+	return t.turnEnd()
+}
+
+// DeclareRiichi discards the hai with the riichi declaration.
+func (t *boardImpl) DeclareRiichi(p player.Player, h *hai.Hai) error {
+	t.Lock()
+	defer t.Unlock()
+	if err := t.checkTurn(p); err != nil {
+		return err
+	}
+	ok, err := p.CanRiichi()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return BoardActionInvalidErr
+	}
+	if err := p.Riichi(h); err != nil {
+		return err
+	}
+	if err := t.riichi(p); err != nil {
+		return err
+	}
+	t.answered(p)
+	return t.turnEnd()
+}
+
+// TsumoAgari wins the hand by the tsumohai.
+func (t *boardImpl) TsumoAgari(p player.Player) error {
+	t.Lock()
+	defer t.Unlock()
+	if err := t.checkTurn(p); err != nil {
+		return err
+	}
+	ok, err := p.CanTsumoAgari()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return BoardActionInvalidErr
+	}
+	if err := t.agari(p, p.Tsumohai(), true); err != nil {
+		return err
+	}
+	t.answered(p)
+	go t.Broadcast()
+	return nil
+}
+
+// RonAgari decides the ron, the agari is played once everyone decided.
+func (t *boardImpl) RonAgari(p player.Player) error {
+	t.Lock()
+	defer t.Unlock()
+	if t.undecided(p) == nil {
+		return BoardActionAlreadyTokenErr
+	}
+	return t.takeAction(p, Ron, func(inHai *hai.Hai) error {
+		if err := t.agari(p, inHai, false); err != nil {
+			return err
+		}
+		return p.Tehai().Add(inHai)
+	})
+}
+
+// Call decides the call of the pair at idx, the pairs are made from the last discard with the board locked.
+// the call is played once everyone decided.
+func (t *boardImpl) Call(p player.Player, actionType ActionType, idx int) error {
+	t.Lock()
+	defer t.Unlock()
+	ap := t.undecided(p)
+	if ap == nil {
+		return BoardActionAlreadyTokenErr
+	}
+	if !ap.hasAction(actionType) {
+		return BoardActionInvalidErr
+	}
+	inHai, err := t.LastKawa()
+	if err != nil {
+		return err
+	}
+	var action func(*hai.Hai) error
+	switch actionType {
+	case Chii:
+		pairs, err := p.Tehai().ChiiPairs(inHai)
+		if err != nil {
+			return err
+		}
+		if idx < 0 || idx >= len(pairs) {
+			return BoardIndexOutOfRangeErr
+		}
+		pair := pairs[idx]
+		action = func(inHai *hai.Hai) error {
+			return p.Chii(inHai, pair)
+		}
+	case Pon:
+		pairs, err := p.Tehai().PonPairs(inHai)
+		if err != nil {
+			return err
+		}
+		if idx < 0 || idx >= len(pairs) {
+			return BoardIndexOutOfRangeErr
+		}
+		pair := pairs[idx]
+		action = func(inHai *hai.Hai) error {
+			return p.Pon(inHai, pair)
+		}
+	case Kan:
+		pairs, err := p.Tehai().MinKanPairs(inHai)
+		if err != nil {
+			return err
+		}
+		if idx < 0 || idx >= len(pairs) {
+			return BoardIndexOutOfRangeErr
+		}
+		pair := pairs[idx]
+		action = func(inHai *hai.Hai) error {
+			if err := p.MinKan(inHai, pair); err != nil {
+				return err
+			}
+			return p.Rinshan()
+		}
+	default:
+		return BoardActionInvalidErr
+	}
+	return t.takeAction(p, actionType, action)
+}
+
+func (t *boardImpl) turnEnd() error {
+	t.recordDahai()
 	err := t.setActionPlayer()
 	if err != nil {
 		return err
 	}
 
 	if len(t.actionPlayers) == 0 {
+		if err := t.acceptRiichi(); err != nil {
+			return err
+		}
 		if err := t.turnchange(t.NextTurn()); err != nil {
 			return err
 		}
-		if err := t.players[t.CurrentTurn()].Tsumo(); err != nil {
+		if err := t.tsumo(); err != nil {
 			return err
 		}
 	}
@@ -203,6 +593,69 @@ func (t *boardImpl) TurnEnd() error {
 	return nil
 }
 
+// tsumo draws for the current turn, the game ends in ryuukyoku when the yama runs out.
+func (t *boardImpl) tsumo() error {
+	tp := t.players[t.CurrentTurn()]
+	err := tp.Tsumo()
+	if err == yama.YamaNoMoreHaiErr {
+		return t.ryuukyoku()
+	}
+	if err != nil {
+		return err
+	}
+	t.recordTsumo(tp.Player)
+	return nil
+}
+
+func (t *boardImpl) ryuukyoku() error {
+	tenpais := make([]bool, len(t.players))
+	cntTenpai := 0
+	for i, tp := range t.players {
+		ok, err := tp.IsTenpai()
+		if err != nil {
+			return err
+		}
+		tenpais[i] = ok
+		if ok {
+			cntTenpai++
+		}
+	}
+
+	points := make([]int, len(t.players))
+	if cntTenpai != 0 && cntTenpai != len(t.players) {
+		for i := range t.players {
+			if tenpais[i] {
+				points[i] += NotenBappu / cntTenpai
+			} else {
+				points[i] -= NotenBappu / (len(t.players) - cntTenpai)
+			}
+		}
+	}
+
+	for i, tp := range t.players {
+		tp.AddPoint(points[i])
+	}
+	t.actionPlayers = []*boardActionPlayer{}
+	t.result = &Result{
+		Points:      points,
+		IsRyuukyoku: true,
+		Tenpais:     tenpais,
+		IsRenchan:   tenpais[t.oyaIndex],
+	}
+	if t.paifu != nil {
+		tehais := [][]*hai.Hai{}
+		for i, tp := range t.players {
+			if !tenpais[i] {
+				tehais = append(tehais, []*hai.Hai{})
+				continue
+			}
+			tehais = append(tehais, tp.Tehai().Hais())
+		}
+		t.record(paifu.RyukyokuEvent(tehais, tenpais, points, t.scores()))
+	}
+	return t.handEnd()
+}
+
 func (t *boardImpl) turnchange(idx int) error {
 	if idx < 0 || idx >= len(t.players) {
 		return BoardIndexOutOfRangeErr
@@ -229,15 +682,18 @@ func (t *boardImpl) setActionPlayer() error {
 			action ActionType
 		}
 		args := []Arg{}
-		if i == t.NextTurn() {
-			ok, err := tc.CanChii(inHai)
-			args = append(args, Arg{ok, err, Chii})
-		}
-		ok, err := tc.CanPon(inHai)
-		args = append(args, Arg{ok, err, Pon})
-		ok, err = tc.CanMinKan(inHai)
-		args = append(args, Arg{ok, err, Kan})
-		ok, err = tc.CanRon(inHai)
+		// the last discard of the hand is only for the ron, no draw after the call
+		if !t.yama.IsEmpty() {
+			if i == t.NextTurn() {
+				ok, err := tc.CanChii(inHai)
+				args = append(args, Arg{ok, err, Chii})
+			}
+			ok, err := tc.CanPon(inHai)
+			args = append(args, Arg{ok, err, Pon})
+			ok, err = tc.CanMinKan(inHai)
+			args = append(args, Arg{ok, err, Kan})
+		}
+		ok, err := tc.CanRon(inHai)
 		args = append(args, Arg{ok, err, Ron})
 
 		actions := []ActionType{}
@@ -263,79 +719,187 @@ func (t *boardImpl) setActionPlayer() error {
 }
 
 func (t *boardImpl) LastKawa() (*hai.Hai, error) {
+	if t.chankanHai != nil {
+		return t.chankanHai, nil
+	}
 	return t.players[t.CurrentTurn()].Kawa().Last()
 }
 
 func (t *boardImpl) MyAction(p player.Player) ([]ActionType, error) {
 	for _, ap := range t.actionPlayers {
-		if ap.Player == p {
+		if ap.Player == p && ap.decision == "" {
 			return ap.actions, nil
 		}
 	}
 	return []ActionType{}, nil
 }
 
+// WaitingPlayers returns the players who have not decided their action yet.
+func (t *boardImpl) WaitingPlayers() []player.Player {
+	players := []player.Player{}
+	for _, ap := range t.actionPlayers {
+		if ap.decision == "" {
+			players = append(players, ap.Player)
+		}
+	}
+	return players
+}
+
 func (t *boardImpl) CancelAction(p player.Player) error {
 	t.Lock()
 	defer t.Unlock()
+	return t.cancelAction(p)
+}
+
+func (t *boardImpl) cancelAction(p player.Player) error {
 	if len(t.actionPlayers) == 0 {
 		return nil
 	}
 
 	found := false
 	for i, tc := range t.actionPlayers {
-		if tc.Player == p {
+		if tc.Player == p && tc.decision == "" {
 			found = true
+			if tc.hasAction(Ron) {
+				tc.SkipRon()
+			}
 			t.actionPlayers = append(t.actionPlayers[:i], t.actionPlayers[i+1:]...)
+			break
 		}
 	}
 	if !found {
 		return BoardPlayerNotFoundErr
 	}
+	return t.resolveAction()
+}
 
-	if len(t.actionPlayers) == 0 {
-		if err := t.turnchange(t.NextTurn()); err != nil {
-			return err
-		}
-		if err := t.players[t.CurrentTurn()].Tsumo(); err != nil {
-			return err
+// TakeAction keeps the decision of the player, the action is applied once everyone decided.
+func (t *boardImpl) TakeAction(c player.Player, actionType ActionType, action func(*hai.Hai) error) error {
+	t.Lock()
+	defer t.Unlock()
+	return t.takeAction(c, actionType, action)
+}
+
+// undecided is the action player of p not decided yet, nil when the calls of p are closed.
+func (t *boardImpl) undecided(p player.Player) *boardActionPlayer {
+	for _, ap := range t.actionPlayers {
+		if ap.Player == p && ap.decision == "" {
+			return ap
 		}
-		go t.Broadcast()
 	}
 	return nil
 }
 
-func (t *boardImpl) TakeAction(c player.Player, action func(*hai.Hai) error) error {
-	t.Lock()
-	defer t.Unlock()
+func (t *boardImpl) takeAction(c player.Player, actionType ActionType, action func(*hai.Hai) error) error {
 	if len(t.actionPlayers) == 0 {
 		return BoardActionAlreadyTokenErr
 	}
 
 	found := false
 	for _, tc := range t.actionPlayers {
-		if tc.Player == c {
+		if tc.Player == c && tc.decision == "" {
+			if !tc.hasAction(actionType) {
+				return BoardActionInvalidErr
+			}
 			found = true
+			tc.decision = actionType
+			tc.action = action
 		}
 	}
 	if !found {
 		return BoardPlayerNotFoundErr
 	}
+	return t.resolveAction()
+}
 
-	h, err := t.players[t.CurrentTurn()].Kawa().Last()
+// resolveAction applies the action of the highest priority when every action player decided.
+// ron ties are broken by the seat order from the current turn.
+func (t *boardImpl) resolveAction() error {
+	if len(t.actionPlayers) == 0 {
+		if t.chankanHai != nil {
+			// nobody robbed the kan
+			t.chankanHai = nil
+			t.interrupt()
+			tp := t.players[t.CurrentTurn()]
+			if err := tp.Rinshan(); err != nil {
+				return err
+			}
+			t.recordTsumo(tp.Player)
+			go t.Broadcast()
+			return nil
+		}
+		if err := t.acceptRiichi(); err != nil {
+			return err
+		}
+		if err := t.turnchange(t.NextTurn()); err != nil {
+			return err
+		}
+		if err := t.tsumo(); err != nil {
+			return err
+		}
+		go t.Broadcast()
+		return nil
+	}
+
+	var winner *boardActionPlayer
+	winnerDistance := 0
+	for _, ap := range t.actionPlayers {
+		if ap.decision == "" {
+			// waiting for the others
+			go t.Broadcast()
+			return nil
+		}
+		idx, err := t.MyTurn(ap.Player)
+		if err != nil {
+			return err
+		}
+		distance := (idx - t.CurrentTurn() + len(t.players)) % len(t.players)
+		if winner == nil ||
+			actionPriority[ap.decision] > actionPriority[winner.decision] ||
+			(actionPriority[ap.decision] == actionPriority[winner.decision] && distance < winnerDistance) {
+			winner = ap
+			winnerDistance = distance
+		}
+	}
+
+	h, err := t.LastKawa()
 	if err != nil {
 		return err
 	}
-	if err := action(h); err != nil {
+	if winner.decision == Ron {
+		// the stick stays with the player dealt in on the riichi dahai
+		t.riichiPlayer = nil
+	} else if err := t.acceptRiichi(); err != nil {
 		return err
 	}
-	_, err = t.players[t.CurrentTurn()].Kawa().RemoveLast()
-	if err != nil {
+	if err := winner.action(h); err != nil {
 		return err
 	}
+	switch winner.decision {
+	case Chii, Pon:
+		t.interrupt()
+		t.recordCall(winner, t.CurrentTurn(), h)
+	case Kan:
+		t.interrupt()
+		t.recordCall(winner, t.CurrentTurn(), h)
+		t.recordTsumo(winner.Player)
+	}
+	if t.chankanHai == nil {
+		_, err = t.players[t.CurrentTurn()].Kawa().RemoveLast()
+		if err != nil {
+			return err
+		}
+	}
+	t.chankanHai = nil
+	// the others passed their ron
+	for _, tc := range t.actionPlayers {
+		if tc != winner && tc.hasAction(Ron) && tc.decision != Ron {
+			tc.SkipRon()
+		}
+	}
 	t.actionPlayers = []*boardActionPlayer{}
 
-	turnIdx, err := t.MyTurn(c)
+	turnIdx, err := t.MyTurn(winner.Player)
 	if err != nil {
 		return err
 	}
@@ -345,3 +909,214 @@ func (t *boardImpl) TakeAction(c player.Player, action func(*hai.Hai) error) err
 	go t.Broadcast()
 	return nil
 }
+
+func (t *boardImpl) agari(p player.Player, inHai *hai.Hai, isTsumo bool) error {
+	if p == nil {
+		return BoardPlayerNilError
+	}
+	winnerIdx, err := t.MyTurn(p)
+	if err != nil {
+		return err
+	}
+	// the ron on the kakan is decided before the chankan window is closed
+	var s *score.Score
+	if t.chankanHai != nil {
+		s, err = p.ChankanScore(inHai)
+	} else {
+		s, err = p.Score(inHai, isTsumo)
+	}
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		return BoardNoYakuErr
+	}
+
+	points := make([]int, len(t.players))
+	isOya := winnerIdx == t.oyaIndex
+	honba := t.match.Honba() * match.HonbaPoint
+	var loser player.Player
+	// the target of the paifu and the value of the hand without sticks
+	target, horaPoints := winnerIdx, 0
+	if isTsumo {
+		oya, ko := score.TsumoPoint(s.Base, isOya)
+		for i := range t.players {
+			if i == winnerIdx {
+				continue
+			}
+			point := ko
+			if i == t.oyaIndex {
+				point = oya
+			}
+			horaPoints += point
+			point += honba / (len(t.players) - 1)
+			points[i] -= point
+			points[winnerIdx] += point
+		}
+	} else {
+		loserIdx := t.CurrentTurn()
+		loser = t.players[loserIdx].Player
+		target = loserIdx
+		horaPoints = score.RonPoint(s.Base, isOya)
+		point := horaPoints + honba
+		points[loserIdx] -= point
+		points[winnerIdx] += point
+	}
+	// the winner takes the riichi sticks on the table
+	points[winnerIdx] += t.match.Kyoutaku() * match.KyoutakuPoint
+
+	for i, tp := range t.players {
+		tp.AddPoint(points[i])
+	}
+	t.winner = p
+	t.result = &Result{
+		Winner:    p,
+		Loser:     loser,
+		Score:     s,
+		AgariHai:  inHai,
+		Tehai:     append([]*hai.Hai{}, p.Tehai().Hais()...),
+		Points:    points,
+		IsRenchan: isOya,
+	}
+	if t.paifu != nil {
+		uraDora := []*hai.Hai{}
+		if p.IsRiichi() {
+			uraDora = t.yama.UraDora()
+		}
+		t.record(paifu.HoraEvent(winnerIdx, target, inHai, uraDora, p.Tehai().Hais(), s, horaPoints, points, t.scores()))
+	}
+	return t.handEnd()
+}
+
+func (t *boardImpl) handEnd() error {
+	points := []int{}
+	for _, tp := range t.players {
+		points = append(points, tp.Point())
+	}
+	if err := t.match.Next(t.result.IsRenchan, t.result.IsRyuukyoku, points); err != nil {
+		return err
+	}
+	if t.match.IsEnd() {
+		// the top takes the riichi sticks left on the table, the tie goes to the earlier seat from the first oya
+		top := t.players[0]
+		for _, tp := range t.players[1:] {
+			if tp.Point() > top.Point() {
+				top = tp
+			}
+		}
+		top.AddPoint(t.match.TakeKyoutaku() * match.KyoutakuPoint)
+	}
+	t.recordEnd()
+	return nil
+}
+
+// interrupt breaks the first go-around and the ippatsu of everyone by the call or the kan.
+func (t *boardImpl) interrupt() {
+	for _, tp := range t.players {
+		tp.Interrupt()
+	}
+}
+
+// riichi declares the riichi with the next dahai, the stick is put when the dahai passes.
+func (t *boardImpl) riichi(p player.Player) error {
+	idx, err := t.MyTurn(p)
+	if err != nil {
+		return err
+	}
+	t.record(paifu.ReachEvent(idx))
+	t.riichiPlayer = p
+	return nil
+}
+
+// acceptRiichi puts the riichi stick when the riichi dahai passed without a ron.
+func (t *boardImpl) acceptRiichi() error {
+	p := t.riichiPlayer
+	if p == nil {
+		return nil
+	}
+	t.riichiPlayer = nil
+	idx, err := t.MyTurn(p)
+	if err != nil {
+		return err
+	}
+	p.AddPoint(-match.KyoutakuPoint)
+	t.match.Riichi()
+	deltas := make([]int, len(t.players))
+	deltas[idx] = -match.KyoutakuPoint
+	t.record(paifu.ReachAcceptedEvent(idx, deltas, t.scores()))
+	return nil
+}
+
+// Kakan opens the chankan window for the others who can ron the added hai.
+// the kan completes with the rinshan draw when nobody can rob it.
+func (t *boardImpl) Kakan(p player.Player, inHai *hai.Hai) error {
+	t.Lock()
+	defer t.Unlock()
+	if err := t.checkTurn(p); err != nil {
+		return err
+	}
+	idx, err := t.MyTurn(p)
+	if err != nil {
+		return err
+	}
+	if err := p.Kakan(inHai); err != nil {
+		return err
+	}
+	t.answered(p)
+	t.drawn = nil
+	t.record(paifu.KakanEvent(idx, inHai, []*hai.Hai{inHai, inHai, inHai}))
+
+	actionPlayers := []*boardActionPlayer{}
+	for i, tc := range t.players {
+		if i == idx {
+			continue
+		}
+		ok, err := tc.CanChankan(inHai)
+		if err != nil {
+			return err
+		}
+		if ok {
+			actionPlayers = append(actionPlayers, &boardActionPlayer{Player: tc.Player, actions: []ActionType{Ron}})
+		}
+	}
+	t.actionPlayers = actionPlayers
+
+	if len(t.actionPlayers) == 0 {
+		t.interrupt()
+		if err := p.Rinshan(); err != nil {
+			return err
+		}
+		t.recordTsumo(p)
+	} else {
+		// the ippatsu of the ron robbing the kan is kept
+		t.chankanHai = inHai
+	}
+	go t.Broadcast()
+	return nil
+}
+
+// AnKan declares the concealed kan of the hais and draws the rinshan hai.
+func (t *boardImpl) AnKan(p player.Player, hais [4]*hai.Hai) error {
+	t.Lock()
+	defer t.Unlock()
+	if err := t.checkTurn(p); err != nil {
+		return err
+	}
+	idx, err := t.MyTurn(p)
+	if err != nil {
+		return err
+	}
+	if err := p.AnKan(hais); err != nil {
+		return err
+	}
+	t.answered(p)
+	t.drawn = nil
+	t.record(paifu.AnkanEvent(idx, hais[:]))
+	t.interrupt()
+	if err := p.Rinshan(); err != nil {
+		return err
+	}
+	t.recordTsumo(p)
+	go t.Broadcast()
+	return nil
+}
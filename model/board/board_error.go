@@ -8,4 +8,10 @@ var (
 	BoardIndexOutOfRangeErr    = errors.New("the index is out of range")
 	BoardPlayerNotFoundErr     = errors.New("the player not found in the board")
 	BoardActionAlreadyTokenErr = errors.New("requresed action is timeover")
+	BoardNoYakuErr             = errors.New("the agari has no yaku")
+	BoardNotReadyErr           = errors.New("the hand is not over yet")
+	BoardActionInvalidErr      = errors.New("the action is not offered to the player")
+	BoardNotPlayingErr         = errors.New("the game is already over")
+	BoardNotYourTurnErr        = errors.New("it is not the turn of the player")
+	BoardPlayerConnectedErr    = errors.New("the seat is played by the other connection")
 )
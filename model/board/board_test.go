@@ -4,7 +4,14 @@ import (
 	"errors"
 	"mahjong/model/hai"
 	"mahjong/model/kawa"
+	"mahjong/model/match"
+	"mahjong/model/naki"
 	"mahjong/model/player"
+	"mahjong/model/score"
+	"mahjong/model/tehai"
+	"mahjong/model/yama"
+	"mahjong/utils"
+	"math/rand"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -30,7 +37,7 @@ func TestJoinPlayer(t *testing.T) {
 			outError:               nil,
 		},
 		{
-			beforePlayers:          []*boardPlayer{{Player: &player.PlayerMock{}, channel: make(chan Board)}, {Player: &player.PlayerMock{}, channel: make(chan Board)}},
+			beforePlayers:          []*boardPlayer{{Player: &player.PlayerMock{}, channel: make(chan Board, 1)}, {Player: &player.PlayerMock{}, channel: make(chan Board, 1)}},
 			beforeMaxNumberOfUsers: 3,
 			beforeIsPlaying:        true,
 			inPlayer:               &player.PlayerMock{},
@@ -49,6 +56,8 @@ func TestJoinPlayer(t *testing.T) {
 
 	for _, c := range cases {
 		tk := &boardImpl{
+			rand:            rand.New(rand.NewSource(0)),
+			match:           &match.MatchMock{RuleMock: match.Tonpuusen},
 			players:         c.beforePlayers,
 			isPlaying:       c.beforeIsPlaying,
 			maxNumberOfUser: c.beforeMaxNumberOfUsers,
@@ -72,6 +81,32 @@ func TestJoinPlayer(t *testing.T) {
 	}
 }
 
+func TestSeed(t *testing.T) {
+	// the order of joining by the order of the seats
+	seats := func(seed int64) []int {
+		b := New(MaxNumberOfUsers, match.New(match.Tonpuusen, MaxNumberOfUsers), seed, yama.NewWithRand, nil)
+		players := []player.Player{}
+		for i := 0; i < MaxNumberOfUsers; i++ {
+			p := player.New(utils.NewUUID(), kawa.New(), tehai.New(), naki.New())
+			_, err := b.JoinPlayer(p)
+			assert.NoError(t, err)
+			players = append(players, p)
+		}
+		assert.Equal(t, seed, b.Seed())
+		out := []int{}
+		for _, tp := range b.Players() {
+			for i, p := range players {
+				if tp.Player == p {
+					out = append(out, i)
+				}
+			}
+		}
+		return out
+	}
+	assert.Equal(t, seats(1), seats(1))
+	assert.NotEqual(t, seats(1), seats(2))
+}
+
 func TestLeavePlayer(t *testing.T) {
 
 	testPlayer := &player.PlayerMock{}
@@ -79,9 +114,11 @@ func TestLeavePlayer(t *testing.T) {
 		beforePlayers          []*boardPlayer
 		beforeMaxNumberOfUsers int
 		beforeIsPlaying        bool
+		beforeIsEnd            bool
 		inPlayer               player.Player
 		afterPlayersLen        int
 		afterIsPlaying         bool
+		afterReadsEnd          bool
 		outError               error
 	}{
 		{
@@ -118,10 +155,23 @@ func TestLeavePlayer(t *testing.T) {
 			inPlayer:               testPlayer,
 			outError:               BoardPlayerNotFoundErr,
 		},
+		{
+			beforePlayers:          []*boardPlayer{{Player: testPlayer, channel: make(chan Board, 1)}, {Player: &player.PlayerMock{}, channel: make(chan Board, 1)}},
+			beforeMaxNumberOfUsers: 2,
+			beforeIsPlaying:        true,
+			beforeIsEnd:            true,
+			inPlayer:               testPlayer,
+			afterPlayersLen:        2,
+			afterIsPlaying:         false,
+			afterReadsEnd:          true,
+			outError:               nil,
+		},
 	}
 
 	for _, c := range cases {
 		tk := &boardImpl{
+			rand:            rand.New(rand.NewSource(0)),
+			match:           &match.MatchMock{RuleMock: match.Tonpuusen, BoolMock: c.beforeIsEnd},
 			players:         c.beforePlayers,
 			isPlaying:       c.beforeIsPlaying,
 			maxNumberOfUser: c.beforeMaxNumberOfUsers,
@@ -134,6 +184,10 @@ func TestLeavePlayer(t *testing.T) {
 
 		if c.beforeIsPlaying != c.afterIsPlaying {
 			for _, Player := range tk.players {
+				if c.afterReadsEnd {
+					// the end of the match is read before the close
+					assert.Equal(t, tk, <-Player.channel)
+				}
 				if Board := <-Player.channel; Board != nil {
 					t.Fatal()
 				}
@@ -197,6 +251,7 @@ func TestTurnEnd(t *testing.T) {
 		name              string
 		beforePlayers     []*boardPlayer
 		beforeTurnIndex   int
+		beforeYamaEmpty   bool
 		afterActionPlayer []*boardActionPlayer
 		outError          error
 	}{
@@ -212,6 +267,13 @@ func TestTurnEnd(t *testing.T) {
 			beforeTurnIndex:   0,
 			afterActionPlayer: []*boardActionPlayer{{Player: testPlayer3, actions: []ActionType{Chii, Pon, Kan, Ron}}},
 		},
+		{
+			name:              "success: only ron on the last discard",
+			beforePlayers:     []*boardPlayer{{Player: testPlayer1}, {Player: testPlayer3}},
+			beforeTurnIndex:   0,
+			beforeYamaEmpty:   true,
+			afterActionPlayer: []*boardActionPlayer{{Player: testPlayer3, actions: []ActionType{Ron}}},
+		},
 	}
 
 	for _, c := range cases {
@@ -220,8 +282,9 @@ func TestTurnEnd(t *testing.T) {
 				players:         c.beforePlayers,
 				turnIndex:       c.beforeTurnIndex,
 				maxNumberOfUser: MaxNumberOfUsers,
+				yama:            &yama.YamaMock{BoolMock: c.beforeYamaEmpty},
 			}
-			err := b.TurnEnd()
+			err := b.turnEnd()
 			if err != nil {
 				assert.Equal(t, c.outError, err)
 				return
@@ -231,6 +294,71 @@ func TestTurnEnd(t *testing.T) {
 	}
 }
 
+func TestDiscard(t *testing.T) {
+	testPlayer1 := &player.PlayerMock{KawaMock: &kawa.KawaMock{HaiMock: hai.Haku}}
+	testPlayer2 := &player.PlayerMock{BoolMock: false}
+	cases := []struct {
+		name               string
+		beforeTurnIndex    int
+		beforeActionPlayer []*boardActionPlayer
+		beforeResult       *Result
+		inPlayer           player.Player
+		afterTurnIndex     int
+		outError           error
+	}{
+		{
+			name:           "success",
+			inPlayer:       testPlayer1,
+			afterTurnIndex: 1,
+		},
+		{
+			name:            "failure: not the turn",
+			beforeTurnIndex: 1,
+			inPlayer:        testPlayer1,
+			outError:        BoardNotYourTurnErr,
+		},
+		{
+			name:               "failure: waiting for the calls",
+			beforeActionPlayer: []*boardActionPlayer{{Player: testPlayer2, actions: []ActionType{Pon}}},
+			inPlayer:           testPlayer1,
+			outError:           BoardNotYourTurnErr,
+		},
+		{
+			name:         "failure: hand over",
+			beforeResult: &Result{},
+			inPlayer:     testPlayer1,
+			outError:     BoardNotYourTurnErr,
+		},
+		{
+			name:     "failure: not on the board",
+			inPlayer: &player.PlayerMock{},
+			outError: BoardPlayerNotFoundErr,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			b := boardImpl{
+				players:         []*boardPlayer{{Player: testPlayer1}, {Player: testPlayer2}},
+				turnIndex:       c.beforeTurnIndex,
+				actionPlayers:   c.beforeActionPlayer,
+				result:          c.beforeResult,
+				maxNumberOfUser: 2,
+				timers:          map[player.Player]*boardTimer{},
+				yama:            &yama.YamaMock{},
+			}
+			err := b.Discard(c.inPlayer, hai.Haku)
+			if err != nil {
+				assert.Equal(t, c.outError, err)
+				return
+			}
+			assert.Equal(t, c.afterTurnIndex, b.turnIndex)
+			// the same discard again is not the turn any more
+			assert.Equal(t, BoardNotYourTurnErr, b.Discard(c.inPlayer, hai.Haku))
+		})
+	}
+}
+
 func TestLastkawa(t *testing.T) {
 	testPlayer1 := &player.PlayerMock{KawaMock: &kawa.KawaMock{HaiMock: hai.Haku}}
 	testPlayer2 := &player.PlayerMock{KawaMock: &kawa.KawaMock{ErrorMock: errors.New("")}}
@@ -312,46 +440,579 @@ func TestCancelAction(t *testing.T) {
 }
 
 func TestTakeAction(t *testing.T) {
-	testPlayer1 := &player.PlayerMock{KawaMock: &kawa.KawaMock{HaiMock: hai.Haku}}
+	executed := ""
+	record := func(name string) func(*hai.Hai) error {
+		return func(_ *hai.Hai) error {
+			executed = name
+			return nil
+		}
+	}
+	testPlayer0 := &player.PlayerMock{KawaMock: &kawa.KawaMock{HaiMock: hai.Haku}}
+	testPlayer1 := &player.PlayerMock{}
+	testPlayer2 := &player.PlayerMock{}
+	testPlayer3 := &player.PlayerMock{}
+	players := func() []*boardPlayer {
+		return []*boardPlayer{
+			{Player: testPlayer0, channel: make(chan Board, 1)}, {Player: testPlayer1, channel: make(chan Board, 1)},
+			{Player: testPlayer2, channel: make(chan Board, 1)}, {Player: testPlayer3, channel: make(chan Board, 1)},
+		}
+	}
 	cases := []struct {
 		name                string
 		beforeActionPlayers []*boardActionPlayer
-		beforePlayers       []*boardPlayer
 		inPlayer            player.Player
-		inFunc              func(*hai.Hai) error
+		inActionType        ActionType
 		outError            error
-		afterActionPlayer   []*boardActionPlayer
+		afterActionPlayers  int
+		afterTurnIndex      int
+		afterExecuted       string
 	}{
 		{
 			name:                "success",
-			beforeActionPlayers: []*boardActionPlayer{{Player: testPlayer1}},
-			beforePlayers:       []*boardPlayer{{Player: testPlayer1}},
-			inPlayer:            testPlayer1,
-			inFunc:              func(_ *hai.Hai) error { return nil },
-
-			afterActionPlayer: []*boardActionPlayer{},
+			beforeActionPlayers: []*boardActionPlayer{{Player: testPlayer2, actions: []ActionType{Pon}}},
+			inPlayer:            testPlayer2,
+			inActionType:        Pon,
+			afterActionPlayers:  0,
+			afterTurnIndex:      2,
+			afterExecuted:       "in",
+		},
+		{
+			name: "success: waiting for the others",
+			beforeActionPlayers: []*boardActionPlayer{
+				{Player: testPlayer1, actions: []ActionType{Chii}}, {Player: testPlayer2, actions: []ActionType{Pon}},
+			},
+			inPlayer:           testPlayer1,
+			inActionType:       Chii,
+			afterActionPlayers: 2,
+			afterTurnIndex:     0,
+			afterExecuted:      "",
+		},
+		{
+			name: "success: pon over chii",
+			beforeActionPlayers: []*boardActionPlayer{
+				{Player: testPlayer1, actions: []ActionType{Chii}, decision: Chii, action: record("chii")},
+				{Player: testPlayer2, actions: []ActionType{Pon}},
+			},
+			inPlayer:           testPlayer2,
+			inActionType:       Pon,
+			afterActionPlayers: 0,
+			afterTurnIndex:     2,
+			afterExecuted:      "in",
+		},
+		{
+			name: "success: ron over pon",
+			beforeActionPlayers: []*boardActionPlayer{
+				{Player: testPlayer1, actions: []ActionType{Pon}, decision: Pon, action: record("pon")},
+				{Player: testPlayer2, actions: []ActionType{Ron}},
+			},
+			inPlayer:           testPlayer2,
+			inActionType:       Ron,
+			afterActionPlayers: 0,
+			afterTurnIndex:     2,
+			afterExecuted:      "in",
+		},
+		{
+			name: "success: ron by seat order",
+			beforeActionPlayers: []*boardActionPlayer{
+				{Player: testPlayer2, actions: []ActionType{Ron}, decision: Ron, action: record("ron")},
+				{Player: testPlayer3, actions: []ActionType{Ron}},
+			},
+			inPlayer:           testPlayer3,
+			inActionType:       Ron,
+			afterActionPlayers: 0,
+			afterTurnIndex:     2,
+			afterExecuted:      "ron",
+		},
+		{
+			name:                "failure: not offered",
+			beforeActionPlayers: []*boardActionPlayer{{Player: testPlayer2, actions: []ActionType{Pon}}},
+			inPlayer:            testPlayer2,
+			inActionType:        Ron,
+			outError:            BoardActionInvalidErr,
 		},
 		{
 			name:                "failure",
 			beforeActionPlayers: []*boardActionPlayer{},
-			beforePlayers:       []*boardPlayer{},
-			inFunc:              func(_ *hai.Hai) error { return nil },
 			outError:            BoardActionAlreadyTokenErr,
 		},
 	}
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
+			executed = ""
 			Board := boardImpl{
 				actionPlayers: c.beforeActionPlayers,
-				players:       c.beforePlayers,
+				players:       players(),
 			}
-			err := Board.TakeAction(c.inPlayer, c.inFunc)
+			err := Board.TakeAction(c.inPlayer, c.inActionType, record("in"))
 			if err != nil {
 				assert.Equal(t, c.outError, err)
 				return
 			}
-			assert.Equal(t, c.afterActionPlayer, Board.actionPlayers)
+			assert.Len(t, Board.actionPlayers, c.afterActionPlayers)
+			assert.Equal(t, c.afterTurnIndex, Board.CurrentTurn())
+			assert.Equal(t, c.afterExecuted, executed)
+		})
+	}
+}
+
+func TestCall(t *testing.T) {
+	testPlayer0 := &player.PlayerMock{KawaMock: &kawa.KawaMock{HaiMock: hai.Haku}}
+	testPlayer1 := &player.PlayerMock{}
+	testPlayer3 := &player.PlayerMock{}
+	cases := []struct {
+		name                string
+		beforeActionPlayers func(player.Player) []*boardActionPlayer
+		inActionType        ActionType
+		inIndex             int
+		outError            error
+		afterTurnIndex      int
+	}{
+		{
+			name: "success",
+			beforeActionPlayers: func(p player.Player) []*boardActionPlayer {
+				return []*boardActionPlayer{{Player: p, actions: []ActionType{Pon}}}
+			},
+			inActionType:   Pon,
+			inIndex:        0,
+			afterTurnIndex: 2,
+		},
+		{
+			name: "failure: the index out of the pairs",
+			beforeActionPlayers: func(p player.Player) []*boardActionPlayer {
+				return []*boardActionPlayer{{Player: p, actions: []ActionType{Pon}}}
+			},
+			inActionType: Pon,
+			inIndex:      1,
+			outError:     BoardIndexOutOfRangeErr,
+		},
+		{
+			name: "failure: not offered",
+			beforeActionPlayers: func(p player.Player) []*boardActionPlayer {
+				return []*boardActionPlayer{{Player: p, actions: []ActionType{Ron}}}
+			},
+			inActionType: Pon,
+			outError:     BoardActionInvalidErr,
+		},
+		{
+			name: "failure: already decided",
+			beforeActionPlayers: func(p player.Player) []*boardActionPlayer {
+				return []*boardActionPlayer{
+					{Player: p, actions: []ActionType{Pon}, decision: Pon},
+					{Player: testPlayer3, actions: []ActionType{Ron}},
+				}
+			},
+			inActionType: Pon,
+			outError:     BoardActionAlreadyTokenErr,
+		},
+		{
+			name: "failure: the window closed",
+			beforeActionPlayers: func(p player.Player) []*boardActionPlayer {
+				return []*boardActionPlayer{}
+			},
+			inActionType: Pon,
+			outError:     BoardActionAlreadyTokenErr,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			th := tehai.New()
+			assert.NoError(t, th.Adds([]*hai.Hai{hai.Haku, hai.Haku, hai.Manzu1}))
+			testPlayer2 := &player.PlayerMock{TehaiMock: th}
+			Board := boardImpl{
+				actionPlayers: c.beforeActionPlayers(testPlayer2),
+				players: []*boardPlayer{
+					{Player: testPlayer0, channel: make(chan Board, 1)}, {Player: testPlayer1, channel: make(chan Board, 1)},
+					{Player: testPlayer2, channel: make(chan Board, 1)}, {Player: testPlayer3, channel: make(chan Board, 1)},
+				},
+			}
+			err := Board.Call(testPlayer2, c.inActionType, c.inIndex)
+			assert.Equal(t, c.outError, err)
+			if err != nil {
+				return
+			}
+			assert.Len(t, Board.actionPlayers, 0)
+			assert.Equal(t, c.afterTurnIndex, Board.CurrentTurn())
+		})
+	}
+}
+
+func TestRiichiStick(t *testing.T) {
+	cases := []struct {
+		name        string
+		inAction    ActionType
+		afterPoints []int
+	}{
+		{
+			name:        "success: passed",
+			inAction:    Cancel,
+			afterPoints: []int{24000, 25000},
+		},
+		{
+			name:        "success: pon",
+			inAction:    Pon,
+			afterPoints: []int{24000, 25000},
+		},
+		{
+			name:        "success: ron",
+			inAction:    Ron,
+			afterPoints: []int{25000, 25000},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			riichiPlayer := &player.PlayerMock{IntMock: 25000, KawaMock: &kawa.KawaMock{HaiMock: hai.Haku}}
+			caller := &player.PlayerMock{IntMock: 25000}
+			b := boardImpl{
+				players: []*boardPlayer{
+					{Player: riichiPlayer, channel: make(chan Board, 1)}, {Player: caller, channel: make(chan Board, 1)},
+				},
+				actionPlayers:   []*boardActionPlayer{{Player: caller, actions: []ActionType{Pon, Ron}}},
+				match:           &match.MatchMock{},
+				maxNumberOfUser: 2,
+				timers:          map[player.Player]*boardTimer{},
+			}
+			assert.NoError(t, b.riichi(riichiPlayer))
+			// the stick is put after the calls on the riichi dahai
+			assert.Equal(t, 25000, riichiPlayer.Point())
+			if c.inAction == Cancel {
+				assert.NoError(t, b.CancelAction(caller))
+			} else {
+				assert.NoError(t, b.TakeAction(caller, c.inAction, func(*hai.Hai) error { return nil }))
+			}
+			assert.Equal(t, c.afterPoints, []int{riichiPlayer.Point(), caller.Point()})
+			assert.Nil(t, b.riichiPlayer)
+		})
+	}
+}
+
+func TestAgari(t *testing.T) {
+	s := &score.Score{Base: 2000}
+	cases := []struct {
+		name            string
+		beforePlayers   []*boardPlayer
+		beforeTurnIndex int
+		inIndex         int
+		inIsTsumo       bool
+		afterPoints     []int
+		outError        error
+	}{
+		{
+			name: "success: ko tsumo",
+			beforePlayers: []*boardPlayer{
+				{Player: &player.PlayerMock{}}, {Player: &player.PlayerMock{ScoreMock: s, TehaiMock: &tehai.TehaiMock{}}},
+				{Player: &player.PlayerMock{}}, {Player: &player.PlayerMock{}},
+			},
+			beforeTurnIndex: 1,
+			inIndex:         1,
+			inIsTsumo:       true,
+			afterPoints:     []int{-4000, 8000, -2000, -2000},
+		},
+		{
+			name: "success: oya ron",
+			beforePlayers: []*boardPlayer{
+				{Player: &player.PlayerMock{ScoreMock: s, TehaiMock: &tehai.TehaiMock{}}}, {Player: &player.PlayerMock{}},
+				{Player: &player.PlayerMock{}}, {Player: &player.PlayerMock{}},
+			},
+			beforeTurnIndex: 2,
+			inIndex:         0,
+			inIsTsumo:       false,
+			afterPoints:     []int{12000, 0, -12000, 0},
+		},
+		{
+			name: "failure: no yaku",
+			beforePlayers: []*boardPlayer{
+				{Player: &player.PlayerMock{}}, {Player: &player.PlayerMock{}},
+			},
+			inIndex:  0,
+			outError: BoardNoYakuErr,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			b := boardImpl{
+				match:     &match.MatchMock{},
+				players:   c.beforePlayers,
+				turnIndex: c.beforeTurnIndex,
+			}
+			err := b.agari(c.beforePlayers[c.inIndex].Player, hai.Haku, c.inIsTsumo)
+			if err != nil {
+				assert.Equal(t, c.outError, err)
+				return
+			}
+			points := []int{}
+			for _, p := range b.players {
+				points = append(points, p.Point())
+			}
+			assert.Equal(t, c.afterPoints, points)
+			assert.Equal(t, c.afterPoints, b.Result().Points)
+			assert.Equal(t, c.beforePlayers[c.inIndex].Player, b.Winner())
+			assert.Equal(t, hai.Haku, b.Result().AgariHai)
+		})
+	}
+}
+
+func TestKakan(t *testing.T) {
+	testPlayer1 := &player.PlayerMock{}
+	testPlayer2 := &player.PlayerMock{BoolMock: true}
+	cases := []struct {
+		name              string
+		beforePlayers     []*boardPlayer
+		inPlayer          player.Player
+		afterActionPlayer []*boardActionPlayer
+		afterChankanHai   *hai.Hai
+		outError          error
+	}{
+		{
+			name: "success: rinshan",
+			beforePlayers: []*boardPlayer{
+				{Player: testPlayer1, channel: make(chan Board, 1)}, {Player: &player.PlayerMock{}, channel: make(chan Board, 1)},
+			},
+			inPlayer:          testPlayer1,
+			afterActionPlayer: []*boardActionPlayer{},
+			afterChankanHai:   nil,
+		},
+		{
+			name: "success: chankan",
+			beforePlayers: []*boardPlayer{
+				{Player: testPlayer1, channel: make(chan Board, 1)}, {Player: testPlayer2, channel: make(chan Board, 1)},
+			},
+			inPlayer:          testPlayer1,
+			afterActionPlayer: []*boardActionPlayer{{Player: testPlayer2, actions: []ActionType{Ron}}},
+			afterChankanHai:   hai.Haku,
+		},
+		{
+			name:          "failure",
+			beforePlayers: []*boardPlayer{},
+			inPlayer:      testPlayer1,
+			outError:      BoardPlayerNotFoundErr,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			b := boardImpl{players: c.beforePlayers}
+			err := b.Kakan(c.inPlayer, hai.Haku)
+			if err != nil {
+				assert.Equal(t, c.outError, err)
+				return
+			}
+			assert.Equal(t, c.afterActionPlayer, b.actionPlayers)
+			assert.Equal(t, c.afterChankanHai, b.chankanHai)
+		})
+	}
+}
+
+func TestRyuukyoku(t *testing.T) {
+	cases := []struct {
+		name           string
+		beforePlayers  []*boardPlayer
+		beforeOya      int
+		beforeIsEnd    bool
+		beforeKyoutaku int
+		afterPoints    []int
+		afterRenchan   bool
+	}{
+		{
+			name: "success: 1 tenpai",
+			beforePlayers: []*boardPlayer{
+				{Player: &player.PlayerMock{}}, {Player: &player.PlayerMock{BoolMock: true}},
+				{Player: &player.PlayerMock{}}, {Player: &player.PlayerMock{}},
+			},
+			afterPoints:  []int{-1000, 3000, -1000, -1000},
+			afterRenchan: false,
+		},
+		{
+			name: "success: 2 tenpai",
+			beforePlayers: []*boardPlayer{
+				{Player: &player.PlayerMock{BoolMock: true}}, {Player: &player.PlayerMock{}},
+				{Player: &player.PlayerMock{BoolMock: true}}, {Player: &player.PlayerMock{}},
+			},
+			afterPoints:  []int{1500, -1500, 1500, -1500},
+			afterRenchan: true,
+		},
+		{
+			name: "success: all noten",
+			beforePlayers: []*boardPlayer{
+				{Player: &player.PlayerMock{}}, {Player: &player.PlayerMock{}},
+				{Player: &player.PlayerMock{}}, {Player: &player.PlayerMock{}},
+			},
+			afterPoints:  []int{0, 0, 0, 0},
+			afterRenchan: false,
+		},
+		{
+			name: "success: the top takes the sticks at the match end",
+			beforePlayers: []*boardPlayer{
+				{Player: &player.PlayerMock{}}, {Player: &player.PlayerMock{BoolMock: true}},
+				{Player: &player.PlayerMock{}}, {Player: &player.PlayerMock{}},
+			},
+			beforeIsEnd:    true,
+			beforeKyoutaku: 2,
+			afterPoints:    []int{-1000, 5000, -1000, -1000},
+			afterRenchan:   false,
+		},
+		{
+			name: "success: the earlier seat takes the sticks of the tie",
+			beforePlayers: []*boardPlayer{
+				{Player: &player.PlayerMock{}}, {Player: &player.PlayerMock{}},
+				{Player: &player.PlayerMock{}}, {Player: &player.PlayerMock{}},
+			},
+			beforeIsEnd:    true,
+			beforeKyoutaku: 1,
+			afterPoints:    []int{1000, 0, 0, 0},
+			afterRenchan:   false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			m := &match.MatchMock{BoolMock: c.beforeIsEnd, IntMock: c.beforeKyoutaku}
+			b := boardImpl{match: m, players: c.beforePlayers, oyaIndex: c.beforeOya}
+			assert.NoError(t, b.ryuukyoku())
+			points := []int{}
+			for _, p := range b.players {
+				points = append(points, p.Point())
+			}
+			assert.Equal(t, c.afterPoints, points)
+			assert.True(t, b.Result().IsRyuukyoku)
+			assert.Equal(t, c.afterRenchan, b.Result().IsRenchan)
+			assert.Nil(t, b.Winner())
+			assert.Equal(t, 0, m.Kyoutaku())
+		})
+	}
+}
+
+func TestAgariWithSticks(t *testing.T) {
+	s := &score.Score{Base: 2000}
+	// 1 honba and 1 riichi stick
+	m := &match.MatchMock{IntMock: 1}
+	players := []*boardPlayer{
+		{Player: &player.PlayerMock{}}, {Player: &player.PlayerMock{ScoreMock: s, TehaiMock: &tehai.TehaiMock{}}},
+		{Player: &player.PlayerMock{}}, {Player: &player.PlayerMock{}},
+	}
+	b := boardImpl{match: m, players: players, turnIndex: 1, oyaIndex: 1}
+	assert.NoError(t, b.agari(players[1].Player, hai.Haku, true))
+	assert.Equal(t, []int{-4100, 13300, -4100, -4100}, b.Result().Points)
+	assert.True(t, b.Result().IsRenchan)
+}
+
+func TestReady(t *testing.T) {
+	testPlayer1 := &player.PlayerMock{}
+	testPlayer2 := &player.PlayerMock{}
+	cases := []struct {
+		name              string
+		beforeResult      *Result
+		beforeReady       []player.Player
+		beforeMatch       match.Match
+		inPlayer          player.Player
+		afterReadyPlayers []player.Player
+		afterResult       *Result
+		outError          error
+	}{
+		{
+			name:              "success: waiting for the others",
+			beforeResult:      &Result{},
+			beforeReady:       []player.Player{},
+			beforeMatch:       &match.MatchMock{},
+			inPlayer:          testPlayer1,
+			afterReadyPlayers: []player.Player{testPlayer1},
+			afterResult:       &Result{},
+		},
+		{
+			name:              "success: next hand",
+			beforeResult:      &Result{},
+			beforeReady:       []player.Player{testPlayer2},
+			beforeMatch:       &match.MatchMock{},
+			inPlayer:          testPlayer1,
+			afterReadyPlayers: []player.Player{},
+			afterResult:       nil,
+		},
+		{
+			name:         "failure: playing",
+			beforeResult: nil,
+			beforeMatch:  &match.MatchMock{},
+			inPlayer:     testPlayer1,
+			outError:     BoardNotReadyErr,
+		},
+		{
+			name:         "failure: match end",
+			beforeResult: &Result{},
+			beforeMatch:  &match.MatchMock{BoolMock: true},
+			inPlayer:     testPlayer1,
+			outError:     BoardNotReadyErr,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			b := boardImpl{
+				players: []*boardPlayer{
+					{Player: testPlayer1, channel: make(chan Board, 1)},
+					{Player: testPlayer2, channel: make(chan Board, 1)},
+				},
+				readyPlayers: c.beforeReady,
+				result:       c.beforeResult,
+				match:        c.beforeMatch,
+				newYama:      func(_ *rand.Rand) yama.Yama { return &yama.YamaMock{} },
+				rand:         rand.New(rand.NewSource(0)),
+			}
+			err := b.Ready(c.inPlayer)
+			if err != nil {
+				assert.Equal(t, c.outError, err)
+				return
+			}
+			assert.Equal(t, c.afterReadyPlayers, b.readyPlayers)
+			assert.Equal(t, c.afterResult, b.result)
+		})
+	}
+}
+
+func TestJikaze(t *testing.T) {
+	testPlayer1 := &player.PlayerMock{}
+	testPlayer2 := &player.PlayerMock{}
+	cases := []struct {
+		name      string
+		beforeOya int
+		inPlayer  player.Player
+		outJikaze *hai.Hai
+		outError  error
+	}{
+		{
+			name:      "success: oya",
+			beforeOya: 1,
+			inPlayer:  testPlayer2,
+			outJikaze: hai.Ton,
+		},
+		{
+			name:      "success: pei",
+			beforeOya: 1,
+			inPlayer:  testPlayer1,
+			outJikaze: hai.Pei,
+		},
+		{
+			name:     "failure",
+			inPlayer: &player.PlayerMock{},
+			outError: BoardPlayerNotFoundErr,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			b := boardImpl{
+				players: []*boardPlayer{
+					{Player: testPlayer1}, {Player: testPlayer2}, {Player: &player.PlayerMock{}}, {Player: &player.PlayerMock{}},
+				},
+				oyaIndex: c.beforeOya,
+			}
+			jikaze, err := b.Jikaze(c.inPlayer)
+			if err != nil {
+				assert.Equal(t, c.outError, err)
+				return
+			}
+			assert.Equal(t, c.outJikaze, jikaze)
 		})
 	}
 }
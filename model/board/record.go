@@ -0,0 +1,119 @@
+package board
+
+import (
+	"log"
+	"mahjong/model/hai"
+	"mahjong/model/paifu"
+	"mahjong/model/player"
+)
+
+// record keeps the event in the paifu, the game goes on even if the record fails.
+func (t *boardImpl) record(e *paifu.Event) {
+	if t.paifu == nil {
+		return
+	}
+	if err := t.paifu.Record(e); err != nil {
+		log.Println(err)
+	}
+}
+
+func (t *boardImpl) scores() []int {
+	scores := []int{}
+	for _, tp := range t.players {
+		scores = append(scores, tp.Point())
+	}
+	return scores
+}
+
+func (t *boardImpl) recordStartGame() {
+	if t.paifu == nil {
+		return
+	}
+	names := []string{}
+	for _, tp := range t.players {
+		names = append(names, tp.ID().String())
+	}
+	t.record(paifu.StartGameEvent(names, t.match.Rule().Name, t.seed))
+}
+
+func (t *boardImpl) recordStartKyoku() {
+	if t.paifu == nil {
+		return
+	}
+	tehais := [][]*hai.Hai{}
+	for _, tp := range t.players {
+		tehais = append(tehais, tp.Tehai().Hais())
+	}
+	doras := t.yama.OmoteDora()
+	t.doraCount = len(doras)
+	t.record(paifu.StartKyokuEvent(
+		t.Bakaze(), t.match.Kyoku(), t.match.Honba(), t.match.Kyoutaku(), t.oyaIndex, doras[0], tehais, t.scores(),
+	))
+}
+
+// recordTsumo records the draw of the player, after the dora revealed by the kan before the rinshan.
+func (t *boardImpl) recordTsumo(p player.Player) {
+	t.drawn = p.Tsumohai()
+	if t.paifu == nil {
+		return
+	}
+	doras := t.yama.OmoteDora()
+	for ; t.doraCount < len(doras); t.doraCount++ {
+		t.record(paifu.DoraEvent(doras[t.doraCount]))
+	}
+	idx, err := t.MyTurn(p)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	t.record(paifu.TsumoEvent(idx, p.Tsumohai()))
+}
+
+// recordDahai records the last discard of the current turn.
+func (t *boardImpl) recordDahai() {
+	tp := t.players[t.CurrentTurn()]
+	drawn := t.drawn
+	t.drawn = nil
+	if t.paifu == nil {
+		return
+	}
+	h, err := tp.Kawa().Last()
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	t.record(paifu.DahaiEvent(t.CurrentTurn(), h, h == drawn))
+}
+
+// recordCall records the chii, pon or kan of the winner on the discard of target.
+func (t *boardImpl) recordCall(ap *boardActionPlayer, target int, h *hai.Hai) {
+	t.drawn = nil
+	if t.paifu == nil {
+		return
+	}
+	idx, err := t.MyTurn(ap.Player)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	n := ap.Naki()
+	switch ap.decision {
+	case Chii:
+		melds := n.Chiis()
+		t.record(paifu.CallEvent(paifu.Chi, idx, target, h, melds[len(melds)-1][1:]))
+	case Pon:
+		melds := n.Pons()
+		t.record(paifu.CallEvent(paifu.Pon, idx, target, h, melds[len(melds)-1][1:]))
+	case Kan:
+		melds := n.MinKans()
+		t.record(paifu.CallEvent(paifu.Daiminkan, idx, target, h, melds[len(melds)-1][1:]))
+	}
+}
+
+// recordEnd records the end of the hand, and of the match if it is over.
+func (t *boardImpl) recordEnd() {
+	t.record(paifu.EndKyokuEvent())
+	if t.match.IsEnd() {
+		t.record(paifu.EndGameEvent(t.scores()))
+	}
+}
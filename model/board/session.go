@@ -0,0 +1,71 @@
+package board
+
+import (
+	"log"
+	"mahjong/model/player"
+	"time"
+)
+
+// Disconnect keeps the seat of the player for the grace period and auto-plays it meanwhile.
+func (t *boardImpl) Disconnect(p player.Player) error {
+	t.Lock()
+	defer t.Unlock()
+	if !t.isPlaying {
+		return nil
+	}
+	tp := t.boardPlayer(p)
+	if tp == nil {
+		return BoardPlayerNotFoundErr
+	}
+	if tp.isDisconnected {
+		return nil
+	}
+	tp.isDisconnected = true
+	tp.grace = time.AfterFunc(GracePeriod, func() { t.expire(tp) })
+
+	// the running timer is replaced by the auto-play one
+	if bt, ok := t.timers[p]; ok {
+		bt.timer.Stop()
+		delete(t.timers, p)
+	}
+	t.setTimers()
+	return nil
+}
+
+// Reconnect re-attaches the disconnected player to the seat and returns the channel of the seat.
+func (t *boardImpl) Reconnect(p player.Player) (chan Board, error) {
+	t.Lock()
+	defer t.Unlock()
+	if !t.isPlaying {
+		return nil, BoardNotPlayingErr
+	}
+	tp := t.boardPlayer(p)
+	if tp == nil {
+		return nil, BoardPlayerNotFoundErr
+	}
+	if !tp.isDisconnected {
+		// the seat is played by the other connection
+		return nil, BoardPlayerConnectedErr
+	}
+	tp.isDisconnected = false
+	tp.grace.Stop()
+	t.setTimers()
+	// redraw the table, the channel full has the latest board already
+	select {
+	case tp.channel <- t:
+	default:
+	}
+	return tp.channel, nil
+}
+
+// expire terminates the game when the player has not come back in the grace period.
+func (t *boardImpl) expire(tp *boardPlayer) {
+	t.Lock()
+	defer t.Unlock()
+	if !tp.isDisconnected {
+		return
+	}
+	if err := t.leavePlayer(tp.Player); err != nil {
+		log.Println(err)
+	}
+}
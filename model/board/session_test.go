@@ -0,0 +1,149 @@
+package board
+
+import (
+	"mahjong/model/hai"
+	"mahjong/model/kawa"
+	"mahjong/model/match"
+	"mahjong/model/player"
+	"mahjong/model/yama"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisconnect(t *testing.T) {
+	testPlayer1 := &player.PlayerMock{HaiMock: hai.Haku, KawaMock: &kawa.KawaMock{HaiMock: hai.Haku}}
+	testPlayer2 := &player.PlayerMock{KawaMock: &kawa.KawaMock{HaiMock: hai.Haku}}
+	cases := []struct {
+		name            string
+		beforeIsPlaying bool
+		inPlayer        player.Player
+		afterTurnIndex  int
+		outError        error
+	}{
+		{
+			// the seat is auto-played without the time limit
+			name:            "success: tsumogiri",
+			beforeIsPlaying: true,
+			inPlayer:        testPlayer1,
+			afterTurnIndex:  1,
+		},
+		{
+			name:            "success: already over",
+			beforeIsPlaying: false,
+			inPlayer:        testPlayer1,
+			afterTurnIndex:  0,
+		},
+		{
+			name:            "failure: not found",
+			beforeIsPlaying: true,
+			inPlayer:        &player.PlayerMock{},
+			afterTurnIndex:  0,
+			outError:        BoardPlayerNotFoundErr,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			b := &boardImpl{
+				players: []*boardPlayer{
+					{Player: testPlayer1, channel: make(chan Board, 10)},
+					{Player: testPlayer2, channel: make(chan Board, 10)},
+				},
+				actionPlayers:   []*boardActionPlayer{},
+				match:           &match.MatchMock{},
+				maxNumberOfUser: 2,
+				isPlaying:       c.beforeIsPlaying,
+				timers:          map[player.Player]*boardTimer{},
+				yama:            &yama.YamaMock{},
+			}
+			err := b.Disconnect(c.inPlayer)
+			assert.Equal(t, c.outError, err)
+			time.Sleep(50 * time.Millisecond)
+
+			b.Lock()
+			defer b.Unlock()
+			assert.Equal(t, c.afterTurnIndex, b.CurrentTurn())
+			for _, tp := range b.players {
+				if tp.grace != nil {
+					tp.grace.Stop()
+				}
+			}
+		})
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	testPlayer := &player.PlayerMock{}
+	cases := []struct {
+		name                 string
+		beforeIsPlaying      bool
+		beforeIsDisconnected bool
+		inPlayer             player.Player
+		outError             error
+	}{
+		{
+			name:                 "success",
+			beforeIsPlaying:      true,
+			beforeIsDisconnected: true,
+			inPlayer:             testPlayer,
+		},
+		{
+			name:                 "failure: still connected",
+			beforeIsPlaying:      true,
+			beforeIsDisconnected: false,
+			inPlayer:             testPlayer,
+			outError:             BoardPlayerConnectedErr,
+		},
+		{
+			name:            "failure: already over",
+			beforeIsPlaying: false,
+			inPlayer:        testPlayer,
+			outError:        BoardNotPlayingErr,
+		},
+		{
+			name:            "failure: not found",
+			beforeIsPlaying: true,
+			inPlayer:        &player.PlayerMock{},
+			outError:        BoardPlayerNotFoundErr,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tp := &boardPlayer{Player: testPlayer, channel: make(chan Board, 10), isDisconnected: c.beforeIsDisconnected, grace: time.NewTimer(time.Hour)}
+			b := &boardImpl{
+				players:         []*boardPlayer{tp},
+				match:           &match.MatchMock{},
+				maxNumberOfUser: 2,
+				isPlaying:       c.beforeIsPlaying,
+				timers:          map[player.Player]*boardTimer{},
+			}
+			channel, err := b.Reconnect(c.inPlayer)
+			if err != nil {
+				assert.Equal(t, c.outError, err)
+				return
+			}
+			assert.Equal(t, tp.channel, channel)
+			assert.False(t, tp.isDisconnected)
+			// the table is redrawn
+			assert.Equal(t, b, <-channel)
+		})
+	}
+}
+
+func TestExpire(t *testing.T) {
+	testPlayer := &player.PlayerMock{}
+	tp := &boardPlayer{Player: testPlayer, channel: make(chan Board, 10), isDisconnected: true}
+	b := &boardImpl{
+		players:   []*boardPlayer{tp},
+		match:     &match.MatchMock{},
+		isPlaying: true,
+		timers:    map[player.Player]*boardTimer{},
+	}
+	b.expire(tp)
+	assert.False(t, b.isPlaying)
+	_, ok := <-tp.channel
+	assert.False(t, ok)
+}
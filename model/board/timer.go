@@ -0,0 +1,177 @@
+package board
+
+import (
+	"log"
+	"mahjong/model/player"
+	"time"
+)
+
+type timerKind string
+
+var (
+	turnTimer  timerKind = "turn"
+	callTimer  timerKind = "call"
+	readyTimer timerKind = "ready"
+)
+
+type boardTimer struct {
+	kind  timerKind
+	since time.Time
+	timer *time.Timer
+}
+
+// waitingKinds returns the players the board waits for and what they are asked.
+func (t *boardImpl) waitingKinds() map[player.Player]timerKind {
+	kinds := map[player.Player]timerKind{}
+	switch {
+	case !t.isPlaying || len(t.players) < t.maxNumberOfUser:
+	case t.result != nil:
+		if t.match.IsEnd() {
+			break
+		}
+		for _, tp := range t.players {
+			kinds[tp.Player] = readyTimer
+		}
+		for _, rp := range t.readyPlayers {
+			delete(kinds, rp)
+		}
+	case len(t.actionPlayers) != 0:
+		for _, ap := range t.actionPlayers {
+			if ap.decision == "" {
+				kinds[ap.Player] = callTimer
+			}
+		}
+	default:
+		kinds[t.players[t.CurrentTurn()].Player] = turnTimer
+	}
+	return kinds
+}
+
+// setTimers starts the timers of the players newly waited for,
+// and stops the ones of the players who answered with charging the reserve.
+func (t *boardImpl) setTimers() {
+	kinds := t.waitingKinds()
+	now := time.Now()
+	for p, bt := range t.timers {
+		if kind, ok := kinds[p]; ok && kind == bt.kind {
+			continue
+		}
+		t.answered(p)
+	}
+	for p, kind := range kinds {
+		if _, ok := t.timers[p]; ok {
+			continue
+		}
+		tp := t.boardPlayer(p)
+		if tp == nil {
+			continue
+		}
+		limit := t.baseTime + tp.reserve
+		if tp.isDisconnected {
+			// auto-play at once
+			limit = 0
+		} else if t.baseTime == 0 {
+			// no time limit
+			continue
+		}
+		bt := &boardTimer{kind: kind, since: now}
+		bt.timer = time.AfterFunc(limit, func() { t.timeout(tp, bt) })
+		t.timers[p] = bt
+	}
+}
+
+// answered stops the timer of the player answering in time, the reserve is charged for the time used.
+// the timer firing meanwhile finds it gone and does nothing.
+func (t *boardImpl) answered(p player.Player) {
+	bt, ok := t.timers[p]
+	if !ok {
+		return
+	}
+	bt.timer.Stop()
+	delete(t.timers, p)
+	if tp := t.boardPlayer(p); tp != nil && !tp.isDisconnected {
+		tp.reserve -= reserveUsed(time.Since(bt.since), t.baseTime, tp.reserve)
+	}
+}
+
+func reserveUsed(elapsed time.Duration, base time.Duration, reserve time.Duration) time.Duration {
+	if elapsed <= base {
+		return 0
+	}
+	if elapsed-base > reserve {
+		return reserve
+	}
+	return elapsed - base
+}
+
+func (t *boardImpl) boardPlayer(p player.Player) *boardPlayer {
+	for _, tp := range t.players {
+		if tp.Player == p {
+			return tp
+		}
+	}
+	return nil
+}
+
+// timeout plays the automatic action, tsumogiri on the turn and pass on the call.
+func (t *boardImpl) timeout(tp *boardPlayer, bt *boardTimer) {
+	t.Lock()
+	defer t.Unlock()
+	if t.timers[tp.Player] != bt {
+		// already answered
+		return
+	}
+	delete(t.timers, tp.Player)
+	if !tp.isDisconnected {
+		tp.reserve = 0
+	}
+
+	var err error
+	switch bt.kind {
+	case turnTimer:
+		err = t.tsumogiri(tp.Player)
+	case callTimer:
+		err = t.cancelAction(tp.Player)
+	case readyTimer:
+		err = t.ready(tp.Player)
+	}
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+func (t *boardImpl) tsumogiri(p player.Player) error {
+	outHai := p.Tsumohai()
+	if outHai == nil {
+		// after chii or pon
+		hais := p.Tehai().Hais()
+		if len(hais) == 0 {
+			return BoardPlayerNotFoundErr
+		}
+		outHai = hais[len(hais)-1]
+	}
+	if err := p.Dahai(outHai); err != nil {
+		return err
+	}
+	return t.turnEnd()
+}
+
+// ThinkingTime returns the base time and the reserve time left for the player.
+func (t *boardImpl) ThinkingTime(p player.Player) (time.Duration, time.Duration, error) {
+	t.Lock()
+	defer t.Unlock()
+	tp := t.boardPlayer(p)
+	if tp == nil {
+		return 0, 0, BoardPlayerNotFoundErr
+	}
+	bt, ok := t.timers[p]
+	if !ok {
+		return t.baseTime, tp.reserve, nil
+	}
+	elapsed := time.Since(bt.since)
+	base := t.baseTime - elapsed
+	if base < 0 {
+		base = 0
+	}
+	return base, tp.reserve - reserveUsed(elapsed, t.baseTime, tp.reserve), nil
+}
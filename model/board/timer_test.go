@@ -0,0 +1,76 @@
+package board
+
+import (
+	"mahjong/model/hai"
+	"mahjong/model/kawa"
+	"mahjong/model/match"
+	"mahjong/model/player"
+	"mahjong/model/yama"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeout(t *testing.T) {
+	testPlayer1 := &player.PlayerMock{HaiMock: hai.Haku, KawaMock: &kawa.KawaMock{HaiMock: hai.Haku}}
+	testPlayer2 := &player.PlayerMock{KawaMock: &kawa.KawaMock{HaiMock: hai.Haku}}
+	cases := []struct {
+		name                string
+		beforeTurnIndex     int
+		beforeActionPlayers []*boardActionPlayer
+		afterTurnIndex      int
+	}{
+		{
+			name:                "success: tsumogiri",
+			beforeTurnIndex:     0,
+			beforeActionPlayers: []*boardActionPlayer{},
+			afterTurnIndex:      1,
+		},
+		{
+			// pass the pon, then tsumogiri the drawn hai
+			name:                "success: pass",
+			beforeTurnIndex:     1,
+			beforeActionPlayers: []*boardActionPlayer{{Player: testPlayer1, actions: []ActionType{Pon}}},
+			afterTurnIndex:      1,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			b := &boardImpl{
+				players: []*boardPlayer{
+					{Player: testPlayer1, channel: make(chan Board, 10), reserve: time.Millisecond},
+					{Player: testPlayer2, channel: make(chan Board, 10), reserve: time.Hour},
+				},
+				turnIndex:       c.beforeTurnIndex,
+				actionPlayers:   c.beforeActionPlayers,
+				match:           &match.MatchMock{},
+				maxNumberOfUser: 2,
+				isPlaying:       true,
+				baseTime:        time.Millisecond,
+				timers:          map[player.Player]*boardTimer{},
+				yama:            &yama.YamaMock{},
+			}
+			b.Broadcast()
+			time.Sleep(50 * time.Millisecond)
+
+			b.Lock()
+			defer b.Unlock()
+			defer b.timers[testPlayer2].timer.Stop()
+			assert.Equal(t, c.afterTurnIndex, b.CurrentTurn())
+			assert.Len(t, b.actionPlayers, 0)
+			for _, tp := range b.players {
+				if tp.Player == testPlayer1 {
+					assert.Equal(t, time.Duration(0), tp.reserve)
+				}
+			}
+		})
+	}
+}
+
+func TestReserveUsed(t *testing.T) {
+	assert.Equal(t, time.Duration(0), reserveUsed(3*time.Second, 5*time.Second, 10*time.Second))
+	assert.Equal(t, 2*time.Second, reserveUsed(7*time.Second, 5*time.Second, 10*time.Second))
+	assert.Equal(t, 10*time.Second, reserveUsed(20*time.Second, 5*time.Second, 10*time.Second))
+}
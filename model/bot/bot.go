@@ -0,0 +1,146 @@
+package bot
+
+import (
+	"mahjong/model/agent"
+	"mahjong/model/board"
+	"mahjong/model/hai"
+	"mahjong/model/hai/attribute"
+	"mahjong/model/tehai"
+)
+
+// botImpl is the baseline agent, it discards to minimise the shanten and always takes the agari.
+type botImpl struct{}
+
+func New() agent.Agent {
+	return &botImpl{}
+}
+
+func (bt *botImpl) Choose(s *agent.State, actions []*agent.Action) (int, error) {
+	if len(actions) == 0 {
+		return 0, agent.AgentNoActionErr
+	}
+	for i, a := range actions {
+		switch a.Type {
+		case board.Ron, board.Tsumo, board.Ready:
+			return i, nil
+		}
+	}
+
+	discards := map[*hai.Hai]int{}
+	riichis := map[*hai.Hai]int{}
+	for i, a := range actions {
+		switch a.Type {
+		case board.Normal, board.Riichi:
+			h, err := hai.AtoHai(a.Hais[0])
+			if err != nil {
+				return 0, err
+			}
+			if a.Type == board.Riichi {
+				riichis[h] = i
+			} else {
+				discards[h] = i
+			}
+		}
+	}
+	if len(discards) == 0 {
+		// calls are not worth it for the baseline, let it go
+		for i, a := range actions {
+			if a.Type == board.Cancel {
+				return i, nil
+			}
+		}
+		return 0, nil
+	}
+	if len(discards) == 1 {
+		for _, i := range discards {
+			return i, nil
+		}
+	}
+
+	hais := []*hai.Hai{}
+	for _, name := range append(append([]string{}, s.Tehai...), s.Tsumohai) {
+		if name == "" {
+			continue
+		}
+		h, err := hai.AtoHai(name)
+		if err != nil {
+			return 0, err
+		}
+		hais = append(hais, h)
+	}
+	outHai, err := Dahai(hais)
+	if err != nil {
+		return 0, err
+	}
+	if i, ok := riichis[outHai]; ok {
+		return i, nil
+	}
+	if i, ok := discards[outHai]; ok {
+		return i, nil
+	}
+	return 0, agent.AgentInvalidChoiceErr
+}
+
+// Dahai chooses the hai leaving the lowest shanten, and the most kinds of hais to improve it on a tie.
+// The jihai goes first if they are still tied.
+func Dahai(hais []*hai.Hai) (*hai.Hai, error) {
+	if len(hais) == 0 {
+		return nil, BotNoHaiErr
+	}
+
+	var outHai *hai.Hai
+	minShanten, maxUkeire := 0, 0
+	seen := map[*hai.Hai]bool{}
+	for i, h := range hais {
+		if seen[h] {
+			continue
+		}
+		seen[h] = true
+		rest := append([]*hai.Hai{}, hais[:i]...)
+		rest = append(rest, hais[i+1:]...)
+
+		s, err := shanten(rest)
+		if err != nil {
+			return nil, err
+		}
+		if outHai != nil && s > minShanten {
+			continue
+		}
+		u, err := ukeire(rest, s)
+		if err != nil {
+			return nil, err
+		}
+		if outHai == nil || s < minShanten || u > maxUkeire {
+			outHai, minShanten, maxUkeire = h, s, u
+			continue
+		}
+		// jihai can not make a shuntsu, let it go first
+		if u == maxUkeire && h.HasAttribute(&attribute.Jihai) && !outHai.HasAttribute(&attribute.Jihai) {
+			outHai, minShanten, maxUkeire = h, s, u
+		}
+	}
+	return outHai, nil
+}
+
+func shanten(hais []*hai.Hai) (int, error) {
+	t := tehai.New()
+	if err := t.Adds(hais); err != nil {
+		return 0, err
+	}
+	return t.Shanten()
+}
+
+// ukeire counts the kinds of hais lowering the shanten.
+func ukeire(hais []*hai.Hai, s int) (int, error) {
+	cnt := 0
+	for _, h := range hai.All {
+		next, err := shanten(append(append([]*hai.Hai{}, hais...), h))
+		if err != nil {
+			return 0, err
+		}
+		if next < s {
+			cnt++
+		}
+	}
+	return cnt, nil
+}
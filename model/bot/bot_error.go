@@ -0,0 +1,7 @@
+package bot
+
+import "errors"
+
+var (
+	BotNoHaiErr = errors.New("the player has no hai to discard")
+)
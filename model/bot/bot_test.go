@@ -0,0 +1,110 @@
+package bot
+
+import (
+	"mahjong/model/agent"
+	"mahjong/model/board"
+	"mahjong/model/hai"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDahai(t *testing.T) {
+	cases := []struct {
+		name   string
+		inHais []*hai.Hai
+		outHai *hai.Hai
+		outErr error
+	}{
+		{
+			name: "success: isolated jihai",
+			inHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu2, hai.Manzu3, hai.Pinzu4, hai.Pinzu5, hai.Pinzu6,
+				hai.Souzu7, hai.Souzu8, hai.Manzu5, hai.Manzu6, hai.Chun, hai.Chun,
+				hai.Pinzu9, hai.Ton,
+			},
+			outHai: hai.Ton,
+		},
+		{
+			name: "success: keep tenpai",
+			inHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu2, hai.Manzu3, hai.Pinzu4, hai.Pinzu5, hai.Pinzu6,
+				hai.Souzu7, hai.Souzu8, hai.Souzu9, hai.Manzu5, hai.Manzu6, hai.Chun,
+				hai.Chun, hai.Pinzu1,
+			},
+			outHai: hai.Pinzu1,
+		},
+		{
+			name:   "failure: no hai",
+			inHais: []*hai.Hai{},
+			outErr: BotNoHaiErr,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			h, err := Dahai(c.inHais)
+			assert.Equal(t, c.outErr, err)
+			assert.Equal(t, c.outHai, h)
+		})
+	}
+}
+
+func TestChoose(t *testing.T) {
+	cases := []struct {
+		name      string
+		inState   *agent.State
+		inActions []*agent.Action
+		outIndex  int
+		outErr    error
+	}{
+		{
+			name:    "success: ron",
+			inState: &agent.State{},
+			inActions: []*agent.Action{
+				{Type: board.Pon, Hais: []string{"m1", "m1"}, Command: "pon 0"},
+				{Type: board.Ron, Hais: []string{"m1"}, Command: "ron"},
+				{Type: board.Cancel, Hais: []string{}, Command: "no"},
+			},
+			outIndex: 1,
+		},
+		{
+			name:    "success: skip the call",
+			inState: &agent.State{},
+			inActions: []*agent.Action{
+				{Type: board.Pon, Hais: []string{"m1", "m1"}, Command: "pon 0"},
+				{Type: board.Cancel, Hais: []string{}, Command: "no"},
+			},
+			outIndex: 1,
+		},
+		{
+			name: "success: riichi with the isolated jihai",
+			inState: &agent.State{
+				Tehai: []string{
+					"m2", "m3", "m4", "p2", "p3", "p4", "s2", "s3", "s4", "s6", "s7", "p8", "p8",
+				},
+				Tsumohai: "東",
+			},
+			inActions: []*agent.Action{
+				{Type: board.Riichi, Hais: []string{"東"}, Command: "riichi 0"},
+				{Type: board.Normal, Hais: []string{"m2"}, Command: "m2"},
+				{Type: board.Normal, Hais: []string{"東"}, Command: "東"},
+			},
+			outIndex: 0,
+		},
+		{
+			name:      "failure: no action",
+			inState:   &agent.State{},
+			inActions: []*agent.Action{},
+			outErr:    agent.AgentNoActionErr,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			idx, err := New().Choose(c.inState, c.inActions)
+			assert.Equal(t, c.outErr, err)
+			assert.Equal(t, c.outIndex, idx)
+		})
+	}
+}
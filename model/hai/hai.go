@@ -202,6 +202,21 @@ func HaitoSuits(h *Hai) ([]*Hai, error) {
 	return []*Hai{}, HaiInvalidArgumentErr
 }
 
+// Dora returns the hai indicated by the dora indicator.
+func Dora(indicator *Hai) (*Hai, error) {
+	if indicator == nil {
+		return nil, HaiInvalidArgumentErr
+	}
+	for _, hais := range [][]*Hai{Manzu, Pinzu, Souzu, KazeHai, YakuHai} {
+		for i, h := range hais {
+			if h == indicator {
+				return hais[(i+1)%len(hais)], nil
+			}
+		}
+	}
+	return nil, HaiInvalidArgumentErr
+}
+
 func (h *Hai) Name() string {
 	return h.name
 }
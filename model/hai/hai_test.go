@@ -143,3 +143,49 @@ func TestHaitoSuits(t *testing.T) {
 		})
 	}
 }
+
+func TestDora(t *testing.T) {
+	cases := []struct {
+		name     string
+		inHai    *Hai
+		outHai   *Hai
+		outError error
+	}{
+		{
+			name:   "success: suhai",
+			inHai:  Pinzu3,
+			outHai: Pinzu4,
+		},
+		{
+			name:   "success: nine",
+			inHai:  Souzu9,
+			outHai: Souzu1,
+		},
+		{
+			name:   "success: kaze",
+			inHai:  Pei,
+			outHai: Ton,
+		},
+		{
+			name:   "success: sangen",
+			inHai:  Chun,
+			outHai: Haku,
+		},
+		{
+			name:     "failure: nil",
+			inHai:    nil,
+			outError: HaiInvalidArgumentErr,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			h, err := Dora(c.inHai)
+			if err != nil {
+				assert.Equal(t, c.outError, err)
+				return
+			}
+			assert.Equal(t, c.outHai, h)
+		})
+	}
+}
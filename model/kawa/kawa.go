@@ -5,12 +5,15 @@ import "mahjong/model/hai"
 type Kawa interface {
 	Add(inHai *hai.Hai) error
 	Hais() []*hai.Hai
+	Sutehais() []*hai.Hai
 	Last() (*hai.Hai, error)
 	RemoveLast() (*hai.Hai, error)
 }
 
 type kawaImpl struct {
 	hais []*hai.Hai
+	// every discarded hai including the ones called away by others
+	sutehais []*hai.Hai
 }
 
 func New() Kawa {
@@ -21,8 +24,13 @@ func (h *kawaImpl) Hais() []*hai.Hai {
 	return h.hais
 }
 
+func (h *kawaImpl) Sutehais() []*hai.Hai {
+	return h.sutehais
+}
+
 func (h *kawaImpl) Add(inHai *hai.Hai) error {
 	h.hais = append(h.hais, inHai)
+	h.sutehais = append(h.sutehais, inHai)
 	return nil
 }
 
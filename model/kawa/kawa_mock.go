@@ -16,6 +16,10 @@ func (h *KawaMock) Hais() []*hai.Hai {
 	return h.HaisMock
 }
 
+func (h *KawaMock) Sutehais() []*hai.Hai {
+	return h.HaisMock
+}
+
 func (h *KawaMock) Add(inHai *hai.Hai) error {
 	h.HaiMock = inHai
 	return h.ErrorMock
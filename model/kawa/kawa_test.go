@@ -32,6 +32,7 @@ func TestAdd(t *testing.T) {
 		}
 
 		assert.Equal(t, c.afterHais, h.hais)
+		assert.Equal(t, c.afterHais, h.sutehais)
 	}
 }
 
@@ -66,17 +67,19 @@ func TestLast(t *testing.T) {
 
 func TestRemoveLast(t *testing.T) {
 	cases := []struct {
-		name       string
-		beforeHais []*hai.Hai
-		outHai     *hai.Hai
-		outError   error
-		afterHais  []*hai.Hai
+		name          string
+		beforeHais    []*hai.Hai
+		outHai        *hai.Hai
+		outError      error
+		afterHais     []*hai.Hai
+		afterSutehais []*hai.Hai
 	}{
 		{
-			name:       "success",
-			beforeHais: []*hai.Hai{hai.Haku},
-			outHai:     hai.Haku,
-			afterHais:  []*hai.Hai{},
+			name:          "success",
+			beforeHais:    []*hai.Hai{hai.Haku},
+			outHai:        hai.Haku,
+			afterHais:     []*hai.Hai{},
+			afterSutehais: []*hai.Hai{hai.Haku},
 		},
 		{
 			name:       "failure",
@@ -87,7 +90,7 @@ func TestRemoveLast(t *testing.T) {
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			Kawa := kawaImpl{hais: c.beforeHais}
+			Kawa := kawaImpl{hais: c.beforeHais, sutehais: c.beforeHais}
 			hai, err := Kawa.RemoveLast()
 			if err != nil {
 				assert.Equal(t, c.outError, err)
@@ -95,6 +98,7 @@ func TestRemoveLast(t *testing.T) {
 			}
 			assert.Equal(t, c.outHai, hai)
 			assert.Equal(t, c.afterHais, Kawa.hais)
+			assert.Equal(t, c.afterSutehais, Kawa.Sutehais())
 
 		})
 
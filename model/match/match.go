@@ -0,0 +1,171 @@
+package match
+
+import (
+	"mahjong/model/hai"
+	"time"
+)
+
+type Rule struct {
+	Name string
+	// the number of bakaze to play, 1 for tonpuusen and 2 for hanchan
+	Rounds     int
+	StartPoint int
+	// the top has to reach the point to finish the match at all last
+	ReturnPoint int
+	// thinking time of every decision and the reserve used up through the match
+	BaseTime    time.Duration
+	ReserveTime time.Duration
+}
+
+var (
+	Tonpuusen = &Rule{Name: "tonpuusen", Rounds: 1, StartPoint: 25000, ReturnPoint: 30000, BaseTime: 10 * time.Second, ReserveTime: 30 * time.Second}
+	Hanchan   = &Rule{Name: "hanchan", Rounds: 2, StartPoint: 25000, ReturnPoint: 30000, BaseTime: 10 * time.Second, ReserveTime: 60 * time.Second}
+	Rules     = []*Rule{Tonpuusen, Hanchan}
+
+	Bakazes = []*hai.Hai{hai.Ton, hai.Nan, hai.Sha, hai.Pei}
+	// the point of a riichi stick and of a honba stick paid by all the losers
+	KyoutakuPoint = 1000
+	HonbaPoint    = 300
+)
+
+func AtoRule(s string) (*Rule, error) {
+	for _, r := range Rules {
+		if r.Name == s {
+			return r, nil
+		}
+	}
+	return nil, MatchInvalidArgumentErr
+}
+
+type Match interface {
+	// getter
+	Rule() *Rule
+	Bakaze() *hai.Hai
+	Kyoku() int
+	Honba() int
+	Kyoutaku() int
+	OyaIndex() int
+	IsEnd() bool
+
+	// a riichi stick is put on the table
+	Riichi()
+	// go to the next hand by the result of the hand, points are the ones after the hand by turn index
+	Next(isRenchan bool, isRyuukyoku bool, points []int) error
+	// the riichi sticks are taken off the table, for the top at the end of the match
+	TakeKyoutaku() int
+}
+
+type matchImpl struct {
+	rule            *Rule
+	maxNumberOfUser int
+	bakazeIndex     int
+	kyoku           int
+	honba           int
+	kyoutaku        int
+	oyaIndex        int
+	isEnd           bool
+}
+
+func New(rule *Rule, maxNOU int) Match {
+	return &matchImpl{
+		rule:            rule,
+		maxNumberOfUser: maxNOU,
+		bakazeIndex:     0,
+		kyoku:           1,
+		honba:           0,
+		kyoutaku:        0,
+		oyaIndex:        0,
+		isEnd:           false,
+	}
+}
+
+func (m *matchImpl) Rule() *Rule {
+	return m.rule
+}
+
+func (m *matchImpl) Bakaze() *hai.Hai {
+	return Bakazes[m.bakazeIndex%len(Bakazes)]
+}
+
+func (m *matchImpl) Kyoku() int {
+	return m.kyoku
+}
+
+func (m *matchImpl) Honba() int {
+	return m.honba
+}
+
+func (m *matchImpl) Kyoutaku() int {
+	return m.kyoutaku
+}
+
+func (m *matchImpl) OyaIndex() int {
+	return m.oyaIndex
+}
+
+func (m *matchImpl) IsEnd() bool {
+	return m.isEnd
+}
+
+func (m *matchImpl) Riichi() {
+	m.kyoutaku++
+}
+
+func (m *matchImpl) TakeKyoutaku() int {
+	kyoutaku := m.kyoutaku
+	m.kyoutaku = 0
+	return kyoutaku
+}
+
+func (m *matchImpl) Next(isRenchan bool, isRyuukyoku bool, points []int) error {
+	if m.isEnd {
+		return MatchAlreadyEndErr
+	}
+	if len(points) != m.maxNumberOfUser {
+		return MatchInvalidArgumentErr
+	}
+
+	isAllLast := m.bakazeIndex >= m.rule.Rounds-1 && m.kyoku == m.maxNumberOfUser
+	oyaIndex := m.oyaIndex
+	if isRenchan || isRyuukyoku {
+		m.honba++
+	} else {
+		m.honba = 0
+	}
+	if !isRyuukyoku {
+		// the winner took the riichi sticks
+		m.kyoutaku = 0
+	}
+	if !isRenchan {
+		m.oyaIndex = (m.oyaIndex + 1) % m.maxNumberOfUser
+		m.kyoku++
+		if m.kyoku > m.maxNumberOfUser {
+			m.kyoku = 1
+			m.bakazeIndex++
+		}
+	}
+
+	top := points[0]
+	for _, p := range points {
+		if p < 0 {
+			// busted
+			m.isEnd = true
+			return nil
+		}
+		if p > top {
+			top = p
+		}
+	}
+
+	switch {
+	case isAllLast && isRenchan && points[oyaIndex] == top && top >= m.rule.ReturnPoint:
+		// the oya finishes the match as the top
+		m.isEnd = true
+	case m.bakazeIndex >= m.rule.Rounds && top >= m.rule.ReturnPoint:
+		m.isEnd = true
+	case m.bakazeIndex > m.rule.Rounds || m.bakazeIndex >= len(Bakazes):
+		// the extra bakaze is over
+		m.isEnd = true
+	}
+	return nil
+}
@@ -0,0 +1,8 @@
+package match
+
+import "errors"
+
+var (
+	MatchInvalidArgumentErr = errors.New("invalid argument")
+	MatchAlreadyEndErr      = errors.New("the match already ended")
+)
@@ -0,0 +1,55 @@
+package match
+
+import "mahjong/model/hai"
+
+var _ Match = &MatchMock{}
+
+type MatchMock struct {
+	ErrorMock error
+	BoolMock  bool
+	IntMock   int
+	HaiMock   *hai.Hai
+	RuleMock  *Rule
+}
+
+func (m *MatchMock) Rule() *Rule {
+	return m.RuleMock
+}
+
+func (m *MatchMock) Bakaze() *hai.Hai {
+	return m.HaiMock
+}
+
+func (m *MatchMock) Kyoku() int {
+	return m.IntMock
+}
+
+func (m *MatchMock) Honba() int {
+	return m.IntMock
+}
+
+func (m *MatchMock) Kyoutaku() int {
+	return m.IntMock
+}
+
+func (m *MatchMock) OyaIndex() int {
+	return m.IntMock
+}
+
+func (m *MatchMock) IsEnd() bool {
+	return m.BoolMock
+}
+
+func (m *MatchMock) Riichi() {
+	m.IntMock++
+}
+
+func (m *MatchMock) TakeKyoutaku() int {
+	kyoutaku := m.IntMock
+	m.IntMock = 0
+	return kyoutaku
+}
+
+func (m *MatchMock) Next(_ bool, _ bool, _ []int) error {
+	return m.ErrorMock
+}
@@ -0,0 +1,179 @@
+package match
+
+import (
+	"mahjong/model/hai"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNext(t *testing.T) {
+	cases := []struct {
+		name              string
+		beforeRule        *Rule
+		beforeBakazeIndex int
+		beforeKyoku       int
+		beforeHonba       int
+		beforeKyoutaku    int
+		beforeOyaIndex    int
+		inIsRenchan       bool
+		inIsRyuukyoku     bool
+		inPoints          []int
+		afterBakaze       *hai.Hai
+		afterKyoku        int
+		afterHonba        int
+		afterKyoutaku     int
+		afterOyaIndex     int
+		afterIsEnd        bool
+		outError          error
+	}{
+		{
+			name:           "success: ko agari",
+			beforeRule:     Hanchan,
+			beforeKyoku:    1,
+			beforeHonba:    2,
+			beforeKyoutaku: 1,
+			inPoints:       []int{25000, 25000, 25000, 25000},
+			afterBakaze:    hai.Ton,
+			afterKyoku:     2,
+			afterHonba:     0,
+			afterKyoutaku:  0,
+			afterOyaIndex:  1,
+		},
+		{
+			name:           "success: oya agari",
+			beforeRule:     Hanchan,
+			beforeKyoku:    1,
+			beforeKyoutaku: 1,
+			inIsRenchan:    true,
+			inPoints:       []int{25000, 25000, 25000, 25000},
+			afterBakaze:    hai.Ton,
+			afterKyoku:     1,
+			afterHonba:     1,
+			afterKyoutaku:  0,
+			afterOyaIndex:  0,
+		},
+		{
+			name:           "success: ryuukyoku oya noten",
+			beforeRule:     Hanchan,
+			beforeKyoku:    4,
+			beforeKyoutaku: 2,
+			beforeOyaIndex: 3,
+			inIsRyuukyoku:  true,
+			inPoints:       []int{25000, 25000, 25000, 25000},
+			afterBakaze:    hai.Nan,
+			afterKyoku:     1,
+			afterHonba:     1,
+			afterKyoutaku:  2,
+			afterOyaIndex:  0,
+		},
+		{
+			name:           "success: all last",
+			beforeRule:     Tonpuusen,
+			beforeKyoku:    4,
+			beforeOyaIndex: 3,
+			inPoints:       []int{35000, 20000, 25000, 20000},
+			afterBakaze:    hai.Nan,
+			afterKyoku:     1,
+			afterOyaIndex:  0,
+			afterIsEnd:     true,
+		},
+		{
+			name:           "success: all last without reaching the return point",
+			beforeRule:     Tonpuusen,
+			beforeKyoku:    4,
+			beforeOyaIndex: 3,
+			inPoints:       []int{28000, 24000, 25000, 23000},
+			afterBakaze:    hai.Nan,
+			afterKyoku:     1,
+			afterOyaIndex:  0,
+			afterIsEnd:     false,
+		},
+		{
+			name:           "success: all last oya top",
+			beforeRule:     Tonpuusen,
+			beforeKyoku:    4,
+			beforeOyaIndex: 3,
+			inIsRenchan:    true,
+			inPoints:       []int{20000, 20000, 25000, 35000},
+			afterBakaze:    hai.Ton,
+			afterKyoku:     4,
+			afterHonba:     1,
+			afterOyaIndex:  3,
+			afterIsEnd:     true,
+		},
+		{
+			name:              "success: end of the extra bakaze",
+			beforeRule:        Tonpuusen,
+			beforeBakazeIndex: 1,
+			beforeKyoku:       4,
+			beforeOyaIndex:    3,
+			inPoints:          []int{28000, 24000, 25000, 23000},
+			afterBakaze:       hai.Sha,
+			afterKyoku:        1,
+			afterOyaIndex:     0,
+			afterIsEnd:        true,
+		},
+		{
+			name:        "success: busted",
+			beforeRule:  Hanchan,
+			beforeKyoku: 1,
+			inPoints:    []int{51000, 25000, 25000, -1000},
+			afterBakaze: hai.Ton,
+			afterKyoku:  2,
+			afterIsEnd:  true,
+			afterHonba:  0,
+			// the oya rotates anyway
+			afterOyaIndex: 1,
+		},
+		{
+			name:        "failure",
+			beforeRule:  Hanchan,
+			beforeKyoku: 1,
+			inPoints:    []int{25000},
+			outError:    MatchInvalidArgumentErr,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			m := matchImpl{
+				rule:            c.beforeRule,
+				maxNumberOfUser: 4,
+				bakazeIndex:     c.beforeBakazeIndex,
+				kyoku:           c.beforeKyoku,
+				honba:           c.beforeHonba,
+				kyoutaku:        c.beforeKyoutaku,
+				oyaIndex:        c.beforeOyaIndex,
+			}
+			err := m.Next(c.inIsRenchan, c.inIsRyuukyoku, c.inPoints)
+			if err != nil {
+				assert.Equal(t, c.outError, err)
+				return
+			}
+			assert.Equal(t, c.afterBakaze, m.Bakaze())
+			assert.Equal(t, c.afterKyoku, m.Kyoku())
+			assert.Equal(t, c.afterHonba, m.Honba())
+			assert.Equal(t, c.afterKyoutaku, m.Kyoutaku())
+			assert.Equal(t, c.afterOyaIndex, m.OyaIndex())
+			assert.Equal(t, c.afterIsEnd, m.IsEnd())
+		})
+	}
+}
+
+func TestTakeKyoutaku(t *testing.T) {
+	m := New(Tonpuusen, 4)
+	m.Riichi()
+	m.Riichi()
+	assert.NoError(t, m.Next(false, true, []int{25000, 25000, 25000, 25000}))
+	assert.Equal(t, 2, m.TakeKyoutaku())
+	assert.Equal(t, 0, m.Kyoutaku())
+}
+
+func TestAtoRule(t *testing.T) {
+	r, err := AtoRule("hanchan")
+	assert.NoError(t, err)
+	assert.Equal(t, Hanchan, r)
+	_, err = AtoRule("")
+	assert.Equal(t, MatchInvalidArgumentErr, err)
+}
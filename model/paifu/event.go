@@ -0,0 +1,154 @@
+package paifu
+
+import (
+	"encoding/json"
+	"mahjong/model/hai"
+)
+
+// the types of the mjai events
+var (
+	StartGame     = "start_game"
+	StartKyoku    = "start_kyoku"
+	Tsumo         = "tsumo"
+	Dahai         = "dahai"
+	Chi           = "chi"
+	Pon           = "pon"
+	Daiminkan     = "daiminkan"
+	Ankan         = "ankan"
+	Kakan         = "kakan"
+	Reach         = "reach"
+	ReachAccepted = "reach_accepted"
+	Dora          = "dora"
+	Hora          = "hora"
+	Ryukyoku      = "ryukyoku"
+	EndKyoku      = "end_kyoku"
+	EndGame       = "end_game"
+
+	// the fields written for each type
+	fields = map[string][]string{
+		StartGame:     {"names", "rule", "seed"},
+		StartKyoku:    {"bakaze", "kyoku", "honba", "kyotaku", "oya", "dora_marker", "tehais", "scores"},
+		Tsumo:         {"actor", "pai"},
+		Dahai:         {"actor", "pai", "tsumogiri"},
+		Chi:           {"actor", "target", "pai", "consumed"},
+		Pon:           {"actor", "target", "pai", "consumed"},
+		Daiminkan:     {"actor", "target", "pai", "consumed"},
+		Ankan:         {"actor", "consumed"},
+		Kakan:         {"actor", "pai", "consumed"},
+		Reach:         {"actor"},
+		ReachAccepted: {"actor", "deltas", "scores"},
+		Dora:          {"dora_marker"},
+		Hora:          {"actor", "target", "pai", "uradora_markers", "hora_tehais", "yakus", "fu", "fan", "hora_points", "deltas", "scores"},
+		Ryukyoku:      {"reason", "tehais", "tenpais", "deltas", "scores"},
+		EndKyoku:      {},
+		EndGame:       {"scores"},
+	}
+)
+
+// Event is one line of the paifu, only the fields of the type are written.
+// Actors and targets are the seats from the first oya, the hais are in mjai names.
+type Event struct {
+	Type           string     `json:"type"`
+	Names          []string   `json:"names"`
+	Rule           string     `json:"rule"`
+	Seed           int64      `json:"seed"`
+	Bakaze         string     `json:"bakaze"`
+	Kyoku          int        `json:"kyoku"`
+	Honba          int        `json:"honba"`
+	Kyotaku        int        `json:"kyotaku"`
+	Oya            int        `json:"oya"`
+	DoraMarker     string     `json:"dora_marker"`
+	Tehais         [][]string `json:"tehais"`
+	Actor          int        `json:"actor"`
+	Target         int        `json:"target"`
+	Pai            string     `json:"pai"`
+	Tsumogiri      bool       `json:"tsumogiri"`
+	Consumed       []string   `json:"consumed"`
+	UradoraMarkers []string   `json:"uradora_markers"`
+	HoraTehais     []string   `json:"hora_tehais"`
+	Yakus          []Yaku     `json:"yakus"`
+	Fu             int        `json:"fu"`
+	Fan            int        `json:"fan"`
+	HoraPoints     int        `json:"hora_points"`
+	Reason         string     `json:"reason"`
+	Tenpais        []bool     `json:"tenpais"`
+	Deltas         []int      `json:"deltas"`
+	Scores         []int      `json:"scores"`
+}
+
+func (e Event) MarshalJSON() ([]byte, error) {
+	// the tags of the fields to pick them by the type
+	type event Event
+	bytes, err := json.Marshal(event(e))
+	if err != nil {
+		return nil, err
+	}
+	all := map[string]json.RawMessage{}
+	if err := json.Unmarshal(bytes, &all); err != nil {
+		return nil, err
+	}
+	out := map[string]json.RawMessage{"type": all["type"]}
+	for _, f := range fields[e.Type] {
+		out[f] = all[f]
+	}
+	return json.Marshal(out)
+}
+
+// Yaku is written as a pair of the name and the han.
+type Yaku struct {
+	Name string
+	Han  int
+}
+
+func (y Yaku) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{y.Name, y.Han})
+}
+
+func (y *Yaku) UnmarshalJSON(bytes []byte) error {
+	pair := []interface{}{&y.Name, &y.Han}
+	return json.Unmarshal(bytes, &pair)
+}
+
+var (
+	jihaiPais = map[*hai.Hai]string{
+		hai.Ton: "E", hai.Nan: "S", hai.Sha: "W", hai.Pei: "N",
+		hai.Haku: "P", hai.Hatsu: "F", hai.Chun: "C",
+	}
+)
+
+// Pai is the mjai name of the hai, 1m for m1 and E for ton.
+func Pai(h *hai.Hai) string {
+	if h == nil {
+		return ""
+	}
+	if name, ok := jihaiPais[h]; ok {
+		return name
+	}
+	name := h.Name()
+	return name[1:] + name[:1]
+}
+
+func AtoPai(s string) (*hai.Hai, error) {
+	for _, h := range hai.All {
+		if Pai(h) == s {
+			return h, nil
+		}
+	}
+	return nil, PaifuInvalidPaiErr
+}
+
+func pais(hais []*hai.Hai) []string {
+	names := []string{}
+	for _, h := range hais {
+		names = append(names, Pai(h))
+	}
+	return names
+}
+
+func paiss(haiss [][]*hai.Hai) [][]string {
+	names := [][]string{}
+	for _, hais := range haiss {
+		names = append(names, pais(hais))
+	}
+	return names
+}
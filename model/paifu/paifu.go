@@ -0,0 +1,191 @@
+package paifu
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"mahjong/model/hai"
+	"mahjong/model/score"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Paifu records the events of a match in the order the board processed them.
+type Paifu interface {
+	Record(*Event) error
+	Events() []*Event
+}
+
+type paifuImpl struct {
+	sync.Mutex
+	events []*Event
+	// every event is written as a json line, closed at the end of the match if it is a closer
+	w io.Writer
+}
+
+// New makes the paifu writing to w, only kept in memory when w is nil.
+func New(w io.Writer) Paifu {
+	return &paifuImpl{events: []*Event{}, w: w}
+}
+
+// NewFile makes the paifu writing to the file of the id in dir.
+func NewFile(dir string, id string) (Paifu, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.Create(Path(dir, id))
+	if err != nil {
+		return nil, err
+	}
+	return New(f), nil
+}
+
+// Path is the file of the paifu of the id, one json line for each event.
+func Path(dir string, id string) string {
+	return filepath.Join(dir, id+".jsonl")
+}
+
+// Read parses the json lines of the paifu, the blank lines are skipped.
+func Read(r io.Reader) ([]*Event, error) {
+	events := []*Event{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		e := &Event{}
+		if err := json.Unmarshal(line, e); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, scanner.Err()
+}
+
+// ReadFile reads the paifu file of the path.
+func ReadFile(path string) ([]*Event, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+func (p *paifuImpl) Record(e *Event) error {
+	p.Lock()
+	defer p.Unlock()
+	p.events = append(p.events, e)
+	if p.w == nil {
+		return nil
+	}
+	bytes, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if _, err := p.w.Write(append(bytes, '\n')); err != nil {
+		return err
+	}
+	if c, ok := p.w.(io.Closer); ok && e.Type == EndGame {
+		p.w = nil
+		return c.Close()
+	}
+	return nil
+}
+
+func (p *paifuImpl) Events() []*Event {
+	p.Lock()
+	defer p.Unlock()
+	return append([]*Event{}, p.events...)
+}
+
+// StartGameEvent has the rule of the match and the seed dealing the walls, to replay it as it was.
+func StartGameEvent(names []string, rule string, seed int64) *Event {
+	return &Event{Type: StartGame, Names: names, Rule: rule, Seed: seed}
+}
+
+func StartKyokuEvent(bakaze *hai.Hai, kyoku int, honba int, kyotaku int, oya int, doraMarker *hai.Hai, tehais [][]*hai.Hai, scores []int) *Event {
+	return &Event{
+		Type:       StartKyoku,
+		Bakaze:     Pai(bakaze),
+		Kyoku:      kyoku,
+		Honba:      honba,
+		Kyotaku:    kyotaku,
+		Oya:        oya,
+		DoraMarker: Pai(doraMarker),
+		Tehais:     paiss(tehais),
+		Scores:     scores,
+	}
+}
+
+func TsumoEvent(actor int, h *hai.Hai) *Event {
+	return &Event{Type: Tsumo, Actor: actor, Pai: Pai(h)}
+}
+
+func DahaiEvent(actor int, h *hai.Hai, tsumogiri bool) *Event {
+	return &Event{Type: Dahai, Actor: actor, Pai: Pai(h), Tsumogiri: tsumogiri}
+}
+
+// CallEvent is chi, pon or daiminkan of the hai discarded by target.
+func CallEvent(t string, actor int, target int, h *hai.Hai, consumed []*hai.Hai) *Event {
+	return &Event{Type: t, Actor: actor, Target: target, Pai: Pai(h), Consumed: pais(consumed)}
+}
+
+func AnkanEvent(actor int, consumed []*hai.Hai) *Event {
+	return &Event{Type: Ankan, Actor: actor, Consumed: pais(consumed)}
+}
+
+func KakanEvent(actor int, h *hai.Hai, consumed []*hai.Hai) *Event {
+	return &Event{Type: Kakan, Actor: actor, Pai: Pai(h), Consumed: pais(consumed)}
+}
+
+func ReachEvent(actor int) *Event {
+	return &Event{Type: Reach, Actor: actor}
+}
+
+func ReachAcceptedEvent(actor int, deltas []int, scores []int) *Event {
+	return &Event{Type: ReachAccepted, Actor: actor, Deltas: deltas, Scores: scores}
+}
+
+func DoraEvent(doraMarker *hai.Hai) *Event {
+	return &Event{Type: Dora, DoraMarker: Pai(doraMarker)}
+}
+
+// HoraEvent is the agari of actor, target is actor himself on tsumo.
+func HoraEvent(actor int, target int, h *hai.Hai, uradoraMarkers []*hai.Hai, tehai []*hai.Hai, s *score.Score, horaPoints int, deltas []int, scores []int) *Event {
+	yakus := []Yaku{}
+	for _, y := range s.Yakus {
+		yakus = append(yakus, Yaku{Name: y.Name, Han: y.Han})
+	}
+	return &Event{
+		Type:           Hora,
+		Actor:          actor,
+		Target:         target,
+		Pai:            Pai(h),
+		UradoraMarkers: pais(uradoraMarkers),
+		HoraTehais:     pais(tehai),
+		Yakus:          yakus,
+		Fu:             s.Fu,
+		Fan:            s.Han,
+		HoraPoints:     horaPoints,
+		Deltas:         deltas,
+		Scores:         scores,
+	}
+}
+
+// RyukyokuEvent is the exhaustive draw, the tehais of the noten players are not shown.
+func RyukyokuEvent(tehais [][]*hai.Hai, tenpais []bool, deltas []int, scores []int) *Event {
+	return &Event{Type: Ryukyoku, Reason: "fanpai", Tehais: paiss(tehais), Tenpais: tenpais, Deltas: deltas, Scores: scores}
+}
+
+func EndKyokuEvent() *Event {
+	return &Event{Type: EndKyoku}
+}
+
+func EndGameEvent(scores []int) *Event {
+	return &Event{Type: EndGame, Scores: scores}
+}
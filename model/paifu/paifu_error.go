@@ -0,0 +1,7 @@
+package paifu
+
+import "errors"
+
+var (
+	PaifuInvalidPaiErr = errors.New("invalid mjai pai name")
+)
@@ -0,0 +1,148 @@
+package paifu
+
+import (
+	"bytes"
+	"encoding/json"
+	"mahjong/model/hai"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPai(t *testing.T) {
+	cases := []struct {
+		name string
+		in   *hai.Hai
+		want string
+	}{
+		{name: "manzu", in: hai.Manzu1, want: "1m"},
+		{name: "pinzu", in: hai.Pinzu5, want: "5p"},
+		{name: "souzu", in: hai.Souzu9, want: "9s"},
+		{name: "ton", in: hai.Ton, want: "E"},
+		{name: "haku", in: hai.Haku, want: "P"},
+		{name: "chun", in: hai.Chun, want: "C"},
+		{name: "nil", in: nil, want: ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.want, Pai(c.in))
+		})
+	}
+}
+
+func TestAtoPai(t *testing.T) {
+	for _, h := range hai.All {
+		got, err := AtoPai(Pai(h))
+		assert.NoError(t, err)
+		assert.Equal(t, h, got)
+	}
+	_, err := AtoPai("m1")
+	assert.Equal(t, PaifuInvalidPaiErr, err)
+}
+
+func TestMarshalJSON(t *testing.T) {
+	cases := []struct {
+		name string
+		in   *Event
+		want string
+	}{
+		{
+			name: "tsumo",
+			in:   TsumoEvent(1, hai.Manzu1),
+			want: `{"actor":1,"pai":"1m","type":"tsumo"}`,
+		},
+		{
+			name: "dahai",
+			in:   DahaiEvent(0, hai.Ton, true),
+			want: `{"actor":0,"pai":"E","tsumogiri":true,"type":"dahai"}`,
+		},
+		{
+			name: "pon",
+			in:   CallEvent(Pon, 2, 0, hai.Chun, []*hai.Hai{hai.Chun, hai.Chun}),
+			want: `{"actor":2,"consumed":["C","C"],"pai":"C","target":0,"type":"pon"}`,
+		},
+		{
+			name: "end kyoku",
+			in:   EndKyokuEvent(),
+			want: `{"type":"end_kyoku"}`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := json.Marshal(c.in)
+			assert.NoError(t, err)
+			assert.Equal(t, c.want, string(got))
+		})
+	}
+}
+
+func TestYaku(t *testing.T) {
+	in := []Yaku{{Name: "riichi", Han: 1}, {Name: "pinfu", Han: 1}}
+	bytes, err := json.Marshal(in)
+	assert.NoError(t, err)
+	assert.Equal(t, `[["riichi",1],["pinfu",1]]`, string(bytes))
+	got := []Yaku{}
+	assert.NoError(t, json.Unmarshal(bytes, &got))
+	assert.Equal(t, in, got)
+}
+
+type closer struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (c *closer) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestRecord(t *testing.T) {
+	w := &closer{}
+	p := New(w)
+	events := []*Event{
+		StartGameEvent([]string{"a", "b", "c", "d"}, "hanchan", 1),
+		TsumoEvent(0, hai.Manzu1),
+		DahaiEvent(0, hai.Manzu1, true),
+	}
+	for _, e := range events {
+		assert.NoError(t, p.Record(e))
+	}
+	assert.Equal(t, events, p.Events())
+	assert.Len(t, strings.Split(strings.TrimSpace(w.String()), "\n"), 3)
+	assert.False(t, w.closed)
+
+	assert.NoError(t, p.Record(EndGameEvent([]int{25000, 25000, 25000, 25000})))
+	assert.True(t, w.closed)
+	assert.Len(t, strings.Split(strings.TrimSpace(w.String()), "\n"), 4)
+
+	// kept in memory without a writer
+	p = New(nil)
+	assert.NoError(t, p.Record(EndKyokuEvent()))
+	assert.Len(t, p.Events(), 1)
+}
+
+func TestRead(t *testing.T) {
+	w := &bytes.Buffer{}
+	p := New(w)
+	events := []*Event{
+		StartGameEvent([]string{"a", "b", "c", "d"}, "hanchan", 1),
+		CallEvent(Chi, 1, 0, hai.Manzu3, []*hai.Hai{hai.Manzu1, hai.Manzu2}),
+		EndGameEvent([]int{25000, 25000, 25000, 25000}),
+	}
+	for _, e := range events {
+		assert.NoError(t, p.Record(e))
+	}
+	got, err := Read(strings.NewReader(w.String() + "\n"))
+	assert.NoError(t, err)
+	assert.Len(t, got, 3)
+	assert.Equal(t, events[0].Names, got[0].Names)
+	assert.Equal(t, "hanchan", got[0].Rule)
+	assert.Equal(t, int64(1), got[0].Seed)
+	assert.Equal(t, Chi, got[1].Type)
+	assert.Equal(t, []string{"1m", "2m"}, got[1].Consumed)
+	assert.Equal(t, events[2].Scores, got[2].Scores)
+
+	_, err = Read(strings.NewReader("{"))
+	assert.Error(t, err)
+}
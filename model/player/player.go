@@ -2,10 +2,11 @@ package player
 
 import (
 	"mahjong/model/hai"
-	"mahjong/model/hai/attribute"
 	"mahjong/model/kawa"
 	"mahjong/model/naki"
+	"mahjong/model/score"
 	"mahjong/model/tehai"
+	"mahjong/model/yaku"
 	"mahjong/model/yama"
 
 	"github.com/google/uuid"
@@ -13,13 +14,25 @@ import (
 
 type Player interface {
 	// getter
+	ID() uuid.UUID
 	Tehai() tehai.Tehai
 	Kawa() kawa.Kawa
 	Tsumohai() *hai.Hai
 	Naki() naki.Naki
 	IsRiichi() bool
+	Bakaze() *hai.Hai
+	Jikaze() *hai.Hai
+	IsFuriten() (bool, error)
+	IsTenpai() (bool, error)
+	Point() int
+	// the nickname shown to the others, empty for no name
+	Name() string
 	// setter
 	SetYama(yama.Yama) error
+	SetName(string)
+	SetKaze(bakaze *hai.Hai, jikaze *hai.Hai)
+	AddPoint(int)
+	Reset(kawa.Kawa, tehai.Tehai, naki.Naki, yama.Yama)
 
 	// my turn
 	CanRiichi() (bool, error)
@@ -30,18 +43,25 @@ type Player interface {
 	CanPon(*hai.Hai) (bool, error)
 	CanMinKan(*hai.Hai) (bool, error)
 	CanRon(*hai.Hai) (bool, error)
+	// CanChankan tells the player can ron the hai added by the kakan of the other
+	CanChankan(*hai.Hai) (bool, error)
+	SkipRon()
+	// Interrupt is told the call of anyone, the first go-around and the ippatsu are broken
+	Interrupt()
 
-	CanTanyao(*hai.Hai) (bool, error)
-	CanPinfu() (bool, error)
+	Score(*hai.Hai, bool) (*score.Score, error)
+	ChankanScore(*hai.Hai) (*score.Score, error)
 
 	Tsumo() error
+	Rinshan() error
 	Dahai(*hai.Hai) error
 	Haipai() error
 	Chii(*hai.Hai, [2]*hai.Hai) error
 	Pon(*hai.Hai, [2]*hai.Hai) error
 	AnKan([4]*hai.Hai) error
 	MinKan(*hai.Hai, [3]*hai.Hai) error
-	Kakan() error
+	KakanHais() []*hai.Hai
+	Kakan(*hai.Hai) error
 	Riichi(*hai.Hai) error
 }
 
@@ -75,8 +95,31 @@ type playerImpl struct {
 	naki     naki.Naki
 	yama     yama.Yama
 	isRiichi bool
+	point    int
+	name     string
+	bakaze   *hai.Hai
+	jikaze   *hai.Hai
+
+	// furiten by passing a ron, until the next dahai
+	isFuriten bool
+	// furiten by passing a ron after riichi, until the end of the game
+	isRiichiFuriten bool
+	// the tsumohai is the rinshan hai of the kan
+	isRinshan bool
+	// the first go-around without any call, until the own first dahai
+	isFirstTurn bool
+	// the riichi declared on the first go-around
+	isDoubleRiichi bool
+	// from the riichi until the own next dahai without any call
+	isIppatsu bool
 }
 
+var (
+	DefaultPoint = 25000
+	// the riichi stick, the player short of it can not declare riichi
+	RiichiPoint = 1000
+)
+
 func New(id uuid.UUID, k kawa.Kawa, t tehai.Tehai, n naki.Naki) Player {
 	return &playerImpl{
 		id:       id,
@@ -85,9 +128,17 @@ func New(id uuid.UUID, k kawa.Kawa, t tehai.Tehai, n naki.Naki) Player {
 		naki:     n,
 		yama:     nil,
 		isRiichi: false,
+		point:    DefaultPoint,
+
+		isFuriten:       false,
+		isRiichiFuriten: false,
 	}
 }
 
+func (c *playerImpl) ID() uuid.UUID {
+	return c.id
+}
+
 func (c *playerImpl) Tehai() tehai.Tehai {
 	return c.tehai
 }
@@ -108,6 +159,88 @@ func (c *playerImpl) IsRiichi() bool {
 	return c.isRiichi
 }
 
+func (c *playerImpl) Bakaze() *hai.Hai {
+	return c.bakaze
+}
+
+func (c *playerImpl) Jikaze() *hai.Hai {
+	return c.jikaze
+}
+
+func (c *playerImpl) SetKaze(bakaze *hai.Hai, jikaze *hai.Hai) {
+	c.bakaze = bakaze
+	c.jikaze = jikaze
+}
+
+func (c *playerImpl) IsFuriten() (bool, error) {
+	if c.isFuriten || c.isRiichiFuriten {
+		return true, nil
+	}
+	// any machihai in the own kawa
+	for _, h := range c.kawa.Sutehais() {
+		ok, err := c.isMachihai(h)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+// IsTenpai reports whether the tehai waits for any hai regardless of yaku.
+func (c *playerImpl) IsTenpai() (bool, error) {
+	for _, h := range hai.All {
+		ok, err := c.isMachihai(h)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+// isMachihai reports whether the tehai waits for h, not when the player holds all the four of h.
+func (c *playerImpl) isMachihai(h *hai.Hai) (bool, error) {
+	hais := append([]*hai.Hai{}, c.tehai.Hais()...)
+	for _, m := range c.naki.Pons() {
+		hais = append(hais, m[:]...)
+	}
+	for _, m := range c.naki.MinKans() {
+		hais = append(hais, m[:]...)
+	}
+	for _, m := range c.naki.AnKans() {
+		hais = append(hais, m[:]...)
+	}
+	cnt := 0
+	for _, th := range hais {
+		if th == h {
+			cnt++
+		}
+	}
+	if cnt >= 4 {
+		return false, nil
+	}
+	agaris, err := c.tehai.Agaris(h)
+	if err != nil {
+		return false, err
+	}
+	return len(agaris) != 0, nil
+}
+
+func (c *playerImpl) Point() int {
+	return c.point
+}
+
+func (c *playerImpl) Name() string {
+	return c.name
+}
+
+func (c *playerImpl) SetName(name string) {
+	c.name = name
+}
+
+func (c *playerImpl) AddPoint(point int) {
+	c.point += point
+}
+
 func (c *playerImpl) Tsumo() error {
 	if c.tsumohai != nil {
 		return PlayerAlreadyHaveTsumohaiErr
@@ -119,6 +252,23 @@ func (c *playerImpl) Tsumo() error {
 	}
 
 	c.tsumohai = tsumohai
+	c.isRinshan = false
+	return nil
+}
+
+// Rinshan draws the replacement hai of the kan from the dead wall.
+func (c *playerImpl) Rinshan() error {
+	if c.tsumohai != nil {
+		return PlayerAlreadyHaveTsumohaiErr
+	}
+
+	rinshanhai, err := c.yama.Kan()
+	if err != nil {
+		return err
+	}
+
+	c.tsumohai = rinshanhai
+	c.isRinshan = true
 	return nil
 }
 
@@ -142,6 +292,9 @@ func (c *playerImpl) Dahai(outHai *hai.Hai) error {
 		}
 	}
 	c.tsumohai = nil
+	c.isFuriten = false
+	c.isFirstTurn = false
+	c.isIppatsu = false
 
 	return c.kawa.Add(outHai)
 }
@@ -154,6 +307,22 @@ func (c *playerImpl) SetYama(y yama.Yama) error {
 	return nil
 }
 
+// Reset prepares the player for the next hand, the point is kept.
+func (c *playerImpl) Reset(k kawa.Kawa, t tehai.Tehai, n naki.Naki, y yama.Yama) {
+	c.tsumohai = nil
+	c.kawa = k
+	c.tehai = t
+	c.naki = n
+	c.yama = y
+	c.isRiichi = false
+	c.isFuriten = false
+	c.isRiichiFuriten = false
+	c.isRinshan = false
+	c.isFirstTurn = false
+	c.isDoubleRiichi = false
+	c.isIppatsu = false
+}
+
 func (c *playerImpl) Haipai() error {
 	if len(c.tehai.Hais()) != 0 {
 		return PlayerAlreadyDidHaipaiErr
@@ -172,6 +341,7 @@ func (c *playerImpl) Haipai() error {
 	if err := c.tehai.Sort(); err != nil {
 		return err
 	}
+	c.isFirstTurn = true
 	return nil
 }
 
@@ -228,8 +398,44 @@ func (c *playerImpl) AnKan(hais [4]*hai.Hai) error {
 	return c.naki.SetAnKan([4]*hai.Hai{hais[0], hais[1], hais[2], hais[3]})
 }
 
-func (c *playerImpl) Kakan() error {
-	err := c.naki.Kakan(c.tsumohai)
+// KakanHais returns the hais in hand which can be added to the pon.
+func (c *playerImpl) KakanHais() []*hai.Hai {
+	hais := []*hai.Hai{}
+	if c.tsumohai == nil || !c.canKan() {
+		// only after the draw, not on the turn of the call
+		return hais
+	}
+outer:
+	for _, h := range append([]*hai.Hai{c.tsumohai}, c.tehai.Hais()...) {
+		if h == nil || !c.naki.CanKakan(h) {
+			continue
+		}
+		for _, x := range hais {
+			if x == h {
+				continue outer
+			}
+		}
+		hais = append(hais, h)
+	}
+	return hais
+}
+
+func (c *playerImpl) Kakan(inHai *hai.Hai) error {
+	if c.tsumohai == nil {
+		return PlayerActionInvalidErr
+	}
+	if inHai != c.tsumohai {
+		if !c.naki.CanKakan(inHai) {
+			return PlayerActionInvalidErr
+		}
+		if _, err := c.tehai.Replace(c.tsumohai, inHai); err != nil {
+			return err
+		}
+		if err := c.tehai.Sort(); err != nil {
+			return err
+		}
+	}
+	err := c.naki.Kakan(inHai)
 	if err != nil {
 		return err
 	}
@@ -241,23 +447,34 @@ func (c *playerImpl) Riichi(inHai *hai.Hai) error {
 	if c.isRiichi {
 		return PlayerAlreadyRiichiErr
 	}
+	isFirstTurn := c.isFirstTurn
 	err := c.Dahai(inHai)
 	if err != nil {
 		return err
 	}
 	c.isRiichi = true
+	c.isDoubleRiichi = isFirstTurn
+	c.isIppatsu = true
 	return nil
 }
 
 func (c *playerImpl) CanAnKan() (bool, error) {
+	if !c.canKan() {
+		return false, nil
+	}
 	return c.tehai.CanAnKan(c.tsumohai)
 }
 
+// canKan tells the yama has the rinshan hai for a kan.
+func (c *playerImpl) canKan() bool {
+	return c.yama != nil && c.yama.CanKan()
+}
+
 func (c *playerImpl) CanRiichi() (bool, error) {
 	cntChii := len(c.naki.Chiis())
 	cntPon := len(c.naki.Pons())
 	cntKan := len(c.naki.MinKans())
-	if cntChii == 0 && cntPon == 0 && cntKan == 0 && c.isRiichi == false {
+	if cntChii == 0 && cntPon == 0 && cntKan == 0 && c.isRiichi == false && c.point >= RiichiPoint {
 		return c.tehai.CanRiichi(c.tsumohai)
 	}
 
@@ -265,201 +482,92 @@ func (c *playerImpl) CanRiichi() (bool, error) {
 }
 
 func (c *playerImpl) CanTsumoAgari() (bool, error) {
-	if c.isRiichi {
-		return c.tehai.CanRon(c.tsumohai)
-	} else {
-		ron, err := c.tehai.CanRon(c.tsumohai)
-		if err != nil {
-			return false, err
-		}
-		tanyao, err := c.CanTanyao(c.tsumohai)
-		if err != nil {
-			return false, err
-		}
-		pinfu, err := c.CanPinfu()
-		if err != nil {
-			return false, err
-		}
-		return ron && (tanyao || pinfu), nil
-	}
+	score, err := c.Score(c.tsumohai, true)
+	return score != nil, err
 }
 
 func (c *playerImpl) CanRon(inHai *hai.Hai) (bool, error) {
-	if c.isRiichi {
-		return c.tehai.CanRon(inHai)
-	} else {
-		ron, err := c.tehai.CanRon(inHai)
-		if err != nil {
-			return false, err
-		}
-		tanyao, err := c.CanTanyao(inHai)
-		if err != nil {
-			return false, err
-		}
-		pinfu, err := c.CanPinfu()
-		if err != nil {
-			return false, err
-		}
-		return ron && (tanyao || pinfu), nil
-	}
-}
-
-func (c *playerImpl) CanChii(inHai *hai.Hai) (bool, error) {
-	if c.isRiichi {
-		return false, nil
+	isFuriten, err := c.IsFuriten()
+	if err != nil || isFuriten {
+		return false, err
 	}
-	return c.tehai.CanChii(inHai)
+	score, err := c.Score(inHai, false)
+	return score != nil, err
 }
 
-func (p *playerImpl) CanPon(inHai *hai.Hai) (bool, error) {
-	if p.isRiichi {
-		return false, nil
+func (c *playerImpl) CanChankan(inHai *hai.Hai) (bool, error) {
+	isFuriten, err := c.IsFuriten()
+	if err != nil || isFuriten {
+		return false, err
 	}
-	return p.tehai.CanPon(inHai)
+	score, err := c.ChankanScore(inHai)
+	return score != nil, err
 }
 
-func (c *playerImpl) CanMinKan(inHai *hai.Hai) (bool, error) {
+func (c *playerImpl) SkipRon() {
+	c.isFuriten = true
 	if c.isRiichi {
-		return false, nil
+		c.isRiichiFuriten = true
 	}
-	return c.tehai.CanMinKan(inHai)
 }
 
-func (p *playerImpl) CanTanyao(inHai *hai.Hai) (bool, error) {
-
-	hais := []*hai.Hai{}
-	hais = append(hais, p.tehai.Hais()...)
-	for _, set := range p.naki.Chiis() {
-		hais = append(hais, set[0], set[1], set[2])
-	}
-	for _, set := range p.naki.Pons() {
-		hais = append(hais, set[0], set[1], set[2])
-	}
-	for _, set := range p.naki.MinKans() {
-		hais = append(hais, set[0], set[1], set[2], set[3])
-	}
-	for _, set := range p.naki.AnKans() {
-		hais = append(hais, set[0], set[1], set[2], set[3])
-	}
-
-outer:
-	for _, h := range hais {
-		for _, num := range attribute.Numbers[1:8] {
-			if h.HasAttribute(num) {
-				continue outer
-			}
-		}
-		return false, nil
-
-	}
-	return true, nil
+func (c *playerImpl) Interrupt() {
+	c.isFirstTurn = false
+	c.isIppatsu = false
 }
 
-func (p *playerImpl) CanPinfu() (bool, error) {
-	cntChii := len(p.naki.Chiis())
-	cntPon := len(p.naki.Pons())
-	cntKan := len(p.naki.MinKans())
-	if cntChii != 0 || cntPon != 0 || cntKan != 0 {
-		return false, nil
-	}
-	tehaiObj := tehai.New()
-	tehaiObj.Adds(p.Tehai().Hais())
-
-	// count by hai
-	haisMap := map[*hai.Hai]int{}
-	for _, h := range tehaiObj.Hais() {
-		haisMap[h]++
-	}
-	for k, v := range haisMap {
-		if v < 2 || k.HasAttribute(&attribute.Sangen) {
-			continue
-		}
-		// deep copy
-		tehaiObj2 := tehai.New()
-		tehaiObj2.Adds(tehaiObj.Hais())
-
-		if _, err := tehaiObj2.Removes([]*hai.Hai{k, k}); err != nil {
-			return false, err
-		}
-
-		// shuntsu
-		pairs, err := tehai.Shuntsu(tehaiObj2.Hais())
-		if err != nil {
-			return false, err
-		}
-		// remove
-		for j := 0; j < len(pairs); j++ {
-			if !(tehaiObj2.HasHai(pairs[j][0]) && tehaiObj2.HasHai(pairs[j][1]) && tehaiObj2.HasHai(pairs[j][2])) {
-				continue
-			}
-			if _, err := tehaiObj2.Removes(pairs[j]); err != nil {
-				return false, err
-			}
-		}
-
-		// machi check
-		if len(tehaiObj2.Hais()) == 2 {
-			a := tehaiObj2.Hais()[0]
-			b := tehaiObj2.Hais()[1]
-			ok, err := isRyanmen(a, b)
-			if err != nil {
-				return false, err
-			}
-			if ok {
-				return true, nil
-			}
-		}
-	}
-
-	return false, nil
+func (c *playerImpl) Score(inHai *hai.Hai, isTsumo bool) (*score.Score, error) {
+	return c.score(inHai, isTsumo, false)
 }
 
-func isRyanmen(a *hai.Hai, b *hai.Hai) (bool, error) {
-	found := false
-	for _, x := range attribute.Suits {
-		if a.HasAttribute(x) && b.HasAttribute(x) {
-			found = true
-		}
-	}
-	if !found {
-		return false, nil
-	}
-
-	if !a.HasAttribute(&attribute.Suhai) || !b.HasAttribute(&attribute.Suhai) {
-		return false, nil
-	}
+// ChankanScore is the score of the ron robbing the kakan of inHai.
+func (c *playerImpl) ChankanScore(inHai *hai.Hai) (*score.Score, error) {
+	return c.score(inHai, false, true)
+}
 
-	ai, err := hai.HaitoI(a)
-	if err != nil {
-		return false, err
-	}
-	bi, err := hai.HaitoI(b)
+// score sets the situation from the draw of the tsumohai and the yama, the discard of the last hai is the houtei.
+func (c *playerImpl) score(inHai *hai.Hai, isTsumo bool, isChankan bool) (*score.Score, error) {
+	agaris, err := c.tehai.Agaris(inHai)
 	if err != nil {
-		return false, err
-	}
+		return nil, err
+	}
+	isEmpty := c.yama.IsEmpty()
+	situation := &yaku.Situation{
+		IsTsumo:        isTsumo,
+		IsRiichi:       c.isRiichi,
+		IsChankan:      isChankan,
+		IsRinshan:      isTsumo && c.isRinshan,
+		IsHaitei:       isTsumo && !c.isRinshan && isEmpty,
+		IsHoutei:       !isTsumo && !isChankan && isEmpty,
+		IsDoubleRiichi: c.isDoubleRiichi,
+		IsIppatsu:      c.isIppatsu,
+		IsTenhou:       isTsumo && c.isFirstTurn && c.jikaze == hai.Ton,
+		IsChiihou:      isTsumo && c.isFirstTurn && c.jikaze != hai.Ton,
+		Bakaze:         c.bakaze,
+		Jikaze:         c.jikaze,
+		OmoteDora:      c.yama.OmoteDora(),
+		UraDora:        c.yama.UraDora(),
+	}
+	return score.Calculate(agaris, c.naki, inHai, situation)
+}
 
-	if abs(ai, bi) != 1 || min(ai, bi) == 1 || max(ai, bi) == 9 {
+func (c *playerImpl) CanChii(inHai *hai.Hai) (bool, error) {
+	if c.isRiichi {
 		return false, nil
 	}
-	return true, nil
-}
-
-func abs(a int, b int) int {
-	return max(a, b) - min(a, b)
+	return c.tehai.CanChii(inHai)
 }
 
-func min(a int, b int) int {
-	if a <= b {
-		return a
-	} else {
-		return b
+func (p *playerImpl) CanPon(inHai *hai.Hai) (bool, error) {
+	if p.isRiichi {
+		return false, nil
 	}
+	return p.tehai.CanPon(inHai)
 }
 
-func max(a int, b int) int {
-	if a >= b {
-		return a
-	} else {
-		return b
+func (c *playerImpl) CanMinKan(inHai *hai.Hai) (bool, error) {
+	if c.isRiichi || !c.canKan() {
+		return false, nil
 	}
+	return c.tehai.CanMinKan(inHai)
 }
@@ -4,8 +4,11 @@ import (
 	"mahjong/model/hai"
 	"mahjong/model/kawa"
 	"mahjong/model/naki"
+	"mahjong/model/score"
 	"mahjong/model/tehai"
 	"mahjong/model/yama"
+
+	"github.com/google/uuid"
 )
 
 var _ Player = &PlayerMock{}
@@ -19,6 +22,14 @@ type PlayerMock struct {
 	ActionsMock []Action
 	KawaMock    kawa.Kawa
 	BoolMock    bool
+	IntMock     int
+	ScoreMock   *score.Score
+	IDMock      uuid.UUID
+	NameMock    string
+}
+
+func (c *PlayerMock) ID() uuid.UUID {
+	return c.IDMock
 }
 
 func (c *PlayerMock) Tehai() tehai.Tehai {
@@ -41,10 +52,49 @@ func (c *PlayerMock) IsRiichi() bool {
 	return c.BoolMock
 }
 
+func (c *PlayerMock) Bakaze() *hai.Hai {
+	return c.HaiMock
+}
+
+func (c *PlayerMock) Jikaze() *hai.Hai {
+	return c.HaiMock
+}
+
+func (c *PlayerMock) SetKaze(_ *hai.Hai, _ *hai.Hai) {
+}
+
+func (c *PlayerMock) IsFuriten() (bool, error) {
+	return c.BoolMock, c.ErrorMock
+}
+
+func (c *PlayerMock) IsTenpai() (bool, error) {
+	return c.BoolMock, c.ErrorMock
+}
+
+func (c *PlayerMock) Point() int {
+	return c.IntMock
+}
+
+func (c *PlayerMock) Name() string {
+	return c.NameMock
+}
+
+func (c *PlayerMock) SetName(name string) {
+	c.NameMock = name
+}
+
+func (c *PlayerMock) AddPoint(point int) {
+	c.IntMock += point
+}
+
 func (c *PlayerMock) Tsumo() error {
 	return c.ErrorMock
 }
 
+func (c *PlayerMock) Rinshan() error {
+	return c.ErrorMock
+}
+
 func (c *PlayerMock) Dahai(outHai *hai.Hai) error {
 	return c.ErrorMock
 }
@@ -57,6 +107,12 @@ func (c *PlayerMock) SetYama(_ yama.Yama) error {
 	return c.ErrorMock
 }
 
+func (c *PlayerMock) Reset(k kawa.Kawa, t tehai.Tehai, n naki.Naki, _ yama.Yama) {
+	c.KawaMock = k
+	c.TehaiMock = t
+	c.NakiMock = n
+}
+
 func (c *PlayerMock) Haipai() error {
 	return c.ErrorMock
 }
@@ -76,7 +132,11 @@ func (c *PlayerMock) MinKan(inHai *hai.Hai, outHais [3]*hai.Hai) error {
 func (c *PlayerMock) AnKan(_ [4]*hai.Hai) error {
 	return c.ErrorMock
 }
-func (c *PlayerMock) Kakan() error {
+func (c *PlayerMock) KakanHais() []*hai.Hai {
+	return c.HaisMock
+}
+
+func (c *PlayerMock) Kakan(_ *hai.Hai) error {
 	return c.ErrorMock
 }
 
@@ -112,10 +172,20 @@ func (c *PlayerMock) CanRon(_ *hai.Hai) (bool, error) {
 	return c.BoolMock, c.ErrorMock
 }
 
-func (c *PlayerMock) CanTanyao(_ *hai.Hai) (bool, error) {
+func (c *PlayerMock) CanChankan(_ *hai.Hai) (bool, error) {
 	return c.BoolMock, c.ErrorMock
 }
 
-func (c *PlayerMock) CanPinfu() (bool, error) {
-	return c.BoolMock, c.ErrorMock
+func (c *PlayerMock) SkipRon() {
+}
+
+func (c *PlayerMock) Interrupt() {
+}
+
+func (c *PlayerMock) Score(_ *hai.Hai, _ bool) (*score.Score, error) {
+	return c.ScoreMock, c.ErrorMock
+}
+
+func (c *PlayerMock) ChankanScore(_ *hai.Hai) (*score.Score, error) {
+	return c.ScoreMock, c.ErrorMock
 }
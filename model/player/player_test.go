@@ -12,6 +12,12 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+func newTehai(hais []*hai.Hai) tehai.Tehai {
+	t := tehai.New()
+	t.Adds(hais)
+	return t
+}
+
 func TestTsumo(t *testing.T) {
 	cases := []struct {
 		beforeTsumohai *hai.Hai
@@ -47,6 +53,49 @@ func TestTsumo(t *testing.T) {
 	}
 }
 
+func TestRinshan(t *testing.T) {
+	cases := []struct {
+		name           string
+		beforeTsumohai *hai.Hai
+		beforeYama     yama.Yama
+		afterTsumohai  *hai.Hai
+		outError       error
+	}{
+		{
+			name:          "success",
+			beforeYama:    &yama.YamaMock{HaiMock: hai.Haku},
+			afterTsumohai: hai.Haku,
+		},
+		{
+			name:       "failure: no rinshan hai",
+			beforeYama: &yama.YamaMock{ErrorMock: yama.YamaNoMoreHaiErr},
+			outError:   yama.YamaNoMoreHaiErr,
+		},
+		{
+			name:           "failure: already have tsumohai",
+			beforeTsumohai: hai.Haku,
+			outError:       PlayerAlreadyHaveTsumohaiErr,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			Player := playerImpl{
+				tsumohai: c.beforeTsumohai,
+				yama:     c.beforeYama,
+			}
+
+			err := Player.Rinshan()
+			if err != nil {
+				assert.Equal(t, c.outError, err)
+				return
+			}
+
+			assert.Equal(t, c.afterTsumohai, Player.tsumohai)
+		})
+	}
+}
+
 func TestDahai(t *testing.T) {
 	cases := []struct {
 		beforeTsumohai *hai.Hai
@@ -267,47 +316,80 @@ func TestKan(t *testing.T) {
 
 func TestKakan(t *testing.T) {
 	cases := []struct {
+		name           string
 		beforeNaki     naki.Naki
+		beforeTehai    tehai.Tehai
 		beforeTsumohai *hai.Hai
+		inHai          *hai.Hai
 		afterNaki      naki.Naki
+		afterTehai     tehai.Tehai
 		afterTomohai   *hai.Hai
 		outError       error
 	}{
 		{
+			name:           "success: tsumohai",
 			beforeNaki:     &naki.NakiMock{PonMock: [3]*hai.Hai{hai.Haku, hai.Haku, hai.Haku}},
+			beforeTehai:    &tehai.TehaiMock{},
 			beforeTsumohai: hai.Haku,
+			inHai:          hai.Haku,
 			afterNaki:      &naki.NakiMock{MinKanMock: [4]*hai.Hai{hai.Haku, hai.Haku, hai.Haku, hai.Haku}},
+			afterTehai:     &tehai.TehaiMock{},
 			afterTomohai:   nil,
-			outError:       nil,
 		},
 		{
-			beforeNaki:     &naki.NakiMock{PonMock: [3]*hai.Hai{hai.Haku, hai.Haku, hai.Haku}},
-			beforeTsumohai: hai.Haku,
-			afterNaki:      &naki.NakiMock{MinKanMock: [4]*hai.Hai{hai.Haku, hai.Haku, hai.Haku, hai.Haku}},
+			name:           "success: tehai",
+			beforeNaki:     &naki.NakiMock{BoolMock: true, PonMock: [3]*hai.Hai{hai.Haku, hai.Haku, hai.Haku}},
+			beforeTehai:    &tehai.TehaiMock{},
+			beforeTsumohai: hai.Manzu1,
+			inHai:          hai.Haku,
+			afterNaki:      &naki.NakiMock{BoolMock: true, MinKanMock: [4]*hai.Hai{hai.Haku, hai.Haku, hai.Haku, hai.Haku}},
+			afterTehai:     &tehai.TehaiMock{HaiMock: hai.Manzu1},
 			afterTomohai:   nil,
-			outError:       nil,
 		},
 		{
-			beforeNaki: &naki.NakiMock{ErrorMock: errors.New("")},
-			afterNaki:  nil,
-			outError:   errors.New(""),
+			name:           "failure: no pon",
+			beforeNaki:     &naki.NakiMock{},
+			beforeTehai:    &tehai.TehaiMock{},
+			beforeTsumohai: hai.Manzu1,
+			inHai:          hai.Haku,
+			outError:       PlayerActionInvalidErr,
+		},
+		{
+			name:           "failure: no tsumohai",
+			beforeNaki:     &naki.NakiMock{BoolMock: true, PonMock: [3]*hai.Hai{hai.Haku, hai.Haku, hai.Haku}},
+			beforeTehai:    &tehai.TehaiMock{},
+			beforeTsumohai: nil,
+			inHai:          hai.Haku,
+			outError:       PlayerActionInvalidErr,
+		},
+		{
+			name:           "failure",
+			beforeNaki:     &naki.NakiMock{ErrorMock: errors.New("")},
+			beforeTehai:    &tehai.TehaiMock{},
+			beforeTsumohai: hai.Haku,
+			inHai:          hai.Haku,
+			outError:       errors.New(""),
 		},
 	}
 
 	for _, c := range cases {
-		Player := playerImpl{
-			tsumohai: c.beforeTsumohai,
-			naki:     c.beforeNaki,
-		}
+		t.Run(c.name, func(t *testing.T) {
+			Player := playerImpl{
+				tsumohai: c.beforeTsumohai,
+				tehai:    c.beforeTehai,
+				naki:     c.beforeNaki,
+			}
 
-		err := Player.Kakan()
-		if err != nil {
-			assert.Equal(t, c.outError, err)
-			continue
-		}
+			err := Player.Kakan(c.inHai)
+			if err != nil {
+				assert.Equal(t, c.outError, err)
+				return
+			}
 
-		assert.Equal(t, c.afterTomohai, Player.tsumohai)
-		assert.Equal(t, c.afterNaki, Player.naki)
+			assert.Equal(t, c.afterTomohai, Player.tsumohai)
+			assert.Equal(t, c.afterTehai, Player.tehai)
+			assert.Equal(t, c.afterNaki, Player.naki)
+		})
 	}
 }
 
@@ -341,6 +423,30 @@ func TestSetYama(t *testing.T) {
 	}
 }
 
+func TestReset(t *testing.T) {
+	p := playerImpl{
+		tsumohai:        hai.Haku,
+		kawa:            &kawa.KawaMock{},
+		tehai:           &tehai.TehaiMock{},
+		naki:            &naki.NakiMock{},
+		isRiichi:        true,
+		isFuriten:       true,
+		isRiichiFuriten: true,
+		point:           12000,
+	}
+	k, th, n, y := kawa.New(), tehai.New(), naki.New(), &yama.YamaMock{}
+	p.Reset(k, th, n, y)
+	assert.Nil(t, p.tsumohai)
+	assert.Equal(t, k, p.kawa)
+	assert.Equal(t, th, p.tehai)
+	assert.Equal(t, n, p.naki)
+	assert.Equal(t, y, p.yama)
+	assert.False(t, p.isRiichi)
+	assert.False(t, p.isFuriten)
+	assert.False(t, p.isRiichiFuriten)
+	assert.Equal(t, 12000, p.point)
+}
+
 func TestHaihai(t *testing.T) {
 	cases := []struct {
 		beforeYama  yama.Yama
@@ -381,18 +487,50 @@ func TestCanTsumoAgari(t *testing.T) {
 		outError       error
 	}{
 		{
-			beforeTehai: &tehai.TehaiMock{
-				BoolMock: true,
-			},
+			name: "success: menzen tsumo",
+			beforeTehai: newTehai([]*hai.Hai{
+				hai.Manzu1, hai.Manzu2, hai.Manzu3, hai.Pinzu1, hai.Pinzu2, hai.Pinzu3,
+				hai.Souzu7, hai.Souzu8, hai.Souzu9, hai.Pei, hai.Pei, hai.Pei,
+				hai.Hatsu,
+			}),
 			beforeTsumohai: hai.Hatsu,
 			beforeNaki:     &naki.NakiMock{},
 			outBool:        true,
 		},
 		{
-			beforeTehai: &tehai.TehaiMock{
-				BoolMock: false,
+			name: "success: yakuhai",
+			beforeTehai: newTehai([]*hai.Hai{
+				hai.Manzu1, hai.Manzu2, hai.Manzu3, hai.Pinzu1, hai.Pinzu2, hai.Pinzu3,
+				hai.Hatsu,
+			}),
+			beforeTsumohai: hai.Hatsu,
+			beforeNaki: &naki.NakiMock{
+				ChiisMock: [][3]*hai.Hai{{hai.Souzu7, hai.Souzu8, hai.Souzu9}},
+				PonsMock:  [][3]*hai.Hai{{hai.Chun, hai.Chun, hai.Chun}},
 			},
+			outBool: true,
+		},
+		{
+			name: "failure: no yaku",
+			beforeTehai: newTehai([]*hai.Hai{
+				hai.Manzu2, hai.Manzu3, hai.Manzu4, hai.Pinzu1, hai.Pinzu2, hai.Pinzu3,
+				hai.Hatsu,
+			}),
 			beforeTsumohai: hai.Hatsu,
+			beforeNaki: &naki.NakiMock{
+				ChiisMock: [][3]*hai.Hai{{hai.Souzu7, hai.Souzu8, hai.Souzu9}},
+				PonsMock:  [][3]*hai.Hai{{hai.Pei, hai.Pei, hai.Pei}},
+			},
+			outBool: false,
+		},
+		{
+			name: "failure: not agari",
+			beforeTehai: newTehai([]*hai.Hai{
+				hai.Manzu1, hai.Manzu2, hai.Manzu3, hai.Pinzu1, hai.Pinzu2, hai.Pinzu3,
+				hai.Souzu7, hai.Souzu8, hai.Souzu9, hai.Pei, hai.Pei, hai.Pei,
+				hai.Hatsu,
+			}),
+			beforeTsumohai: hai.Chun,
 			beforeNaki:     &naki.NakiMock{},
 			outBool:        false,
 		},
@@ -400,7 +538,7 @@ func TestCanTsumoAgari(t *testing.T) {
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			p := playerImpl{tehai: c.beforeTehai, tsumohai: c.beforeTsumohai, naki: c.beforeNaki}
+			p := playerImpl{tehai: c.beforeTehai, tsumohai: c.beforeTsumohai, naki: c.beforeNaki, yama: &yama.YamaMock{}}
 			isTsumo, err := p.CanTsumoAgari()
 			if err != nil {
 				assert.Equal(t, c.outError, err)
@@ -413,202 +551,439 @@ func TestCanTsumoAgari(t *testing.T) {
 
 }
 
-func TestCanTanyao(t *testing.T) {
+func TestCanRiichi(t *testing.T) {
 	cases := []struct {
 		name        string
-		beforeTehai tehai.Tehai
+		beforePoint int
 		beforeNaki  naki.Naki
-		inHai       *hai.Hai
 		outBool     bool
-		outError    error
 	}{
 		{
-			name: "success",
-			beforeTehai: &tehai.TehaiMock{
-				HaisMock: []*hai.Hai{
-					hai.Manzu2, hai.Manzu3, hai.Manzu4, hai.Manzu2, hai.Manzu3, hai.Manzu4,
-					hai.Manzu2, hai.Manzu3, hai.Manzu4, hai.Pinzu2, hai.Pinzu2, hai.Pinzu2,
-					hai.Manzu5,
-				},
-			},
-			beforeNaki: &naki.NakiMock{},
-			inHai:      hai.Manzu5,
-			outBool:    true,
+			name:        "success",
+			beforePoint: 1000,
+			beforeNaki:  &naki.NakiMock{},
+			outBool:     true,
 		},
 		{
-			name: "failure",
-			beforeTehai: &tehai.TehaiMock{
-				HaisMock: []*hai.Hai{
-					hai.Manzu1, hai.Manzu3, hai.Manzu4, hai.Manzu2, hai.Manzu3, hai.Manzu4,
-					hai.Manzu2, hai.Manzu3, hai.Manzu4, hai.Pinzu2, hai.Pinzu2, hai.Pinzu2,
-					hai.Manzu5,
-				},
-			},
-			beforeNaki: &naki.NakiMock{},
-			inHai:      hai.Manzu5,
-			outBool:    false,
+			name:        "failure: no point for the stick",
+			beforePoint: 900,
+			beforeNaki:  &naki.NakiMock{},
+			outBool:     false,
+		},
+		{
+			name:        "failure: naki",
+			beforePoint: 25000,
+			beforeNaki:  &naki.NakiMock{PonsMock: [][3]*hai.Hai{{hai.Chun, hai.Chun, hai.Chun}}},
+			outBool:     false,
 		},
 	}
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			p := playerImpl{tehai: c.beforeTehai, naki: c.beforeNaki}
-			ok, err := p.CanTanyao(c.inHai)
-			if err != nil {
-				assert.Equal(t, c.outError, err)
-				return
-
-			}
+			p := playerImpl{tehai: &tehai.TehaiMock{BoolMock: true}, naki: c.beforeNaki, point: c.beforePoint}
+			ok, err := p.CanRiichi()
+			assert.NoError(t, err)
 			assert.Equal(t, c.outBool, ok)
 		})
 	}
 }
 
-func TestCanPinfu(t *testing.T) {
+func TestCanRon(t *testing.T) {
 	cases := []struct {
-		name        string
-		beforeTehai tehai.Tehai
-		beforeNaki  naki.Naki
-		outBool     bool
-		outError    error
+		name           string
+		beforeIsRiichi bool
+		beforeJikaze   *hai.Hai
+		beforeTehai    tehai.Tehai
+		beforeNaki     naki.Naki
+		beforeKawa     kawa.Kawa
+		inHai          *hai.Hai
+		outBool        bool
+		outError       error
 	}{
 		{
-			name: "success",
-			beforeTehai: &tehai.TehaiMock{
-				HaisMock: []*hai.Hai{
-					hai.Manzu2, hai.Manzu3, hai.Manzu4, hai.Manzu2, hai.Manzu3, hai.Manzu4,
-					hai.Manzu2, hai.Manzu3, hai.Manzu4, hai.Pinzu2, hai.Pinzu3, hai.Pinzu4,
-					hai.Manzu5,
-				},
-			},
+			name:           "successs: riichi",
+			beforeIsRiichi: true,
+			beforeTehai: newTehai([]*hai.Hai{
+				hai.Manzu1, hai.Manzu2, hai.Manzu3, hai.Pinzu1, hai.Pinzu2, hai.Pinzu3,
+				hai.Souzu7, hai.Souzu8, hai.Souzu9, hai.Pei, hai.Pei, hai.Pei,
+				hai.Hatsu,
+			}),
 			beforeNaki: &naki.NakiMock{},
+			beforeKawa: &kawa.KawaMock{},
+			inHai:      hai.Hatsu,
 			outBool:    true,
 		},
 		{
-			name: "failure: naki",
-			beforeTehai: &tehai.TehaiMock{
-				HaisMock: []*hai.Hai{
-					hai.Manzu2, hai.Manzu3, hai.Manzu4, hai.Manzu2, hai.Manzu3, hai.Manzu4,
-					hai.Manzu2, hai.Manzu3, hai.Manzu4,
-					hai.Manzu5,
-				},
-			},
+			name: "successs: tanyao",
+			beforeTehai: newTehai([]*hai.Hai{
+				hai.Manzu2, hai.Manzu3, hai.Manzu4, hai.Pinzu5, hai.Pinzu5, hai.Pinzu5,
+				hai.Souzu6, hai.Souzu7, hai.Souzu8, hai.Pinzu7,
+			}),
 			beforeNaki: &naki.NakiMock{
 				ChiisMock: [][3]*hai.Hai{{hai.Pinzu2, hai.Pinzu3, hai.Pinzu4}},
 			},
-			outBool: false,
+			beforeKawa: &kawa.KawaMock{},
+			inHai:      hai.Pinzu7,
+			outBool:    true,
 		},
 		{
-			name: "failure: head",
-			beforeTehai: &tehai.TehaiMock{
-				HaisMock: []*hai.Hai{
-					hai.Manzu2, hai.Manzu3, hai.Manzu4, hai.Manzu2, hai.Manzu3, hai.Manzu4,
-					hai.Manzu2, hai.Manzu3, hai.Manzu4, hai.Pinzu2, hai.Pinzu3, hai.Haku,
-					hai.Haku,
-				},
-			},
+			name:         "successs: jikaze",
+			beforeJikaze: hai.Nan,
+			beforeTehai: newTehai([]*hai.Hai{
+				hai.Manzu1, hai.Manzu2, hai.Manzu3, hai.Pinzu1, hai.Pinzu2, hai.Pinzu3,
+				hai.Souzu4, hai.Souzu5, hai.Souzu6, hai.Nan, hai.Nan, hai.Hatsu,
+				hai.Hatsu,
+			}),
 			beforeNaki: &naki.NakiMock{},
-			outBool:    false,
+			beforeKawa: &kawa.KawaMock{},
+			inHai:      hai.Nan,
+			outBool:    true,
 		},
 		{
-			name: "failure: kotsu",
-			beforeTehai: &tehai.TehaiMock{
-				HaisMock: []*hai.Hai{
-					hai.Manzu2, hai.Manzu3, hai.Manzu4, hai.Manzu2, hai.Manzu3, hai.Manzu4,
-					hai.Manzu2, hai.Manzu3, hai.Manzu4, hai.Pinzu2, hai.Pinzu2, hai.Pinzu2,
-					hai.Manzu5,
-				},
-			},
+			name:         "failure: otakaze",
+			beforeJikaze: hai.Sha,
+			beforeTehai: newTehai([]*hai.Hai{
+				hai.Manzu1, hai.Manzu2, hai.Manzu3, hai.Pinzu1, hai.Pinzu2, hai.Pinzu3,
+				hai.Souzu4, hai.Souzu5, hai.Souzu6, hai.Nan, hai.Nan, hai.Hatsu,
+				hai.Hatsu,
+			}),
 			beforeNaki: &naki.NakiMock{},
+			beforeKawa: &kawa.KawaMock{},
+			inHai:      hai.Nan,
 			outBool:    false,
 		},
 		{
-			name: "failure: machi1",
-			beforeTehai: &tehai.TehaiMock{
-				HaisMock: []*hai.Hai{
-					hai.Manzu2, hai.Manzu3, hai.Manzu4, hai.Manzu2, hai.Manzu3, hai.Manzu4,
-					hai.Manzu2, hai.Manzu3, hai.Manzu4, hai.Pinzu2, hai.Pinzu2, hai.Manzu7,
-					hai.Manzu5,
-				},
+			name: "successs: honitsu",
+			beforeTehai: newTehai([]*hai.Hai{
+				hai.Pinzu1, hai.Pinzu1, hai.Pinzu1, hai.Pinzu5, hai.Pinzu6, hai.Pinzu7,
+				hai.Nan, hai.Nan, hai.Nan, hai.Pinzu9,
+			}),
+			beforeNaki: &naki.NakiMock{
+				ChiisMock: [][3]*hai.Hai{{hai.Pinzu2, hai.Pinzu3, hai.Pinzu4}},
 			},
-			beforeNaki: &naki.NakiMock{},
+			beforeKawa: &kawa.KawaMock{},
+			inHai:      hai.Pinzu9,
+			outBool:    true,
+		},
+		{
+			name: "failure",
+			beforeTehai: newTehai([]*hai.Hai{
+				hai.Manzu2, hai.Manzu3, hai.Manzu4, hai.Pinzu5, hai.Pinzu5, hai.Pinzu5,
+				hai.Souzu6, hai.Souzu7, hai.Souzu8, hai.Pinzu7,
+			}),
+			beforeNaki: &naki.NakiMock{
+				ChiisMock: [][3]*hai.Hai{{hai.Pinzu2, hai.Pinzu3, hai.Pinzu1}},
+			},
+			beforeKawa: &kawa.KawaMock{},
+			inHai:      hai.Pinzu7,
 			outBool:    false,
 		},
 		{
-			name: "failure: machi2",
-			beforeTehai: &tehai.TehaiMock{
-				HaisMock: []*hai.Hai{
-					hai.Manzu2, hai.Manzu3, hai.Manzu4, hai.Manzu2, hai.Manzu3, hai.Manzu4,
-					hai.Manzu2, hai.Manzu3, hai.Manzu4, hai.Pinzu2, hai.Pinzu2, hai.Manzu8,
-					hai.Manzu9,
-				},
+			name: "failure: furiten",
+			beforeTehai: newTehai([]*hai.Hai{
+				hai.Manzu2, hai.Manzu3, hai.Manzu4, hai.Pinzu5, hai.Pinzu5, hai.Pinzu5,
+				hai.Souzu6, hai.Souzu7, hai.Souzu8, hai.Pinzu7,
+			}),
+			beforeNaki: &naki.NakiMock{
+				ChiisMock: [][3]*hai.Hai{{hai.Pinzu2, hai.Pinzu3, hai.Pinzu4}},
 			},
-			beforeNaki: &naki.NakiMock{},
+			beforeKawa: &kawa.KawaMock{HaisMock: []*hai.Hai{hai.Pinzu7}},
+			inHai:      hai.Pinzu7,
 			outBool:    false,
 		},
 	}
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			p := playerImpl{tehai: c.beforeTehai, naki: c.beforeNaki}
-			ok, err := p.CanPinfu()
+			p := playerImpl{tehai: c.beforeTehai, naki: c.beforeNaki, kawa: c.beforeKawa, yama: &yama.YamaMock{}, isRiichi: c.beforeIsRiichi, jikaze: c.beforeJikaze}
+			ok, err := p.CanRon(c.inHai)
 			if err != nil {
 				assert.Equal(t, c.outError, err)
 				return
+			}
+			assert.Equal(t, c.outBool, ok)
+		})
+	}
+}
+
+func TestCanChankan(t *testing.T) {
+	// no yaku but the chankan
+	th := newTehai([]*hai.Hai{
+		hai.Manzu2, hai.Manzu3, hai.Manzu4, hai.Pinzu5, hai.Pinzu5, hai.Pinzu5,
+		hai.Souzu6, hai.Souzu7, hai.Souzu8, hai.Pinzu7,
+	})
+	n := &naki.NakiMock{ChiisMock: [][3]*hai.Hai{{hai.Pinzu1, hai.Pinzu2, hai.Pinzu3}}}
+	p := playerImpl{tehai: th, naki: n, kawa: &kawa.KawaMock{}, yama: &yama.YamaMock{}}
+
+	ok, err := p.CanRon(hai.Pinzu7)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = p.CanChankan(hai.Pinzu7)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestScoreSituation(t *testing.T) {
+	cases := []struct {
+		name        string
+		beforeEmpty bool
+		inRinshan   bool
+		inTsumo     bool
+		outBool     bool
+	}{
+		{
+			name:      "success: rinshan kaihou",
+			inRinshan: true,
+			inTsumo:   true,
+			outBool:   true,
+		},
+		{
+			name:        "success: haitei raoyue",
+			beforeEmpty: true,
+			inTsumo:     true,
+			outBool:     true,
+		},
+		{
+			name:        "success: houtei raoyui",
+			beforeEmpty: true,
+			outBool:     true,
+		},
+		{
+			name:    "failure: tsumo",
+			inTsumo: true,
+			outBool: false,
+		},
+		{
+			name:    "failure: ron",
+			outBool: false,
+		},
+	}
 
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// no yaku but the situation
+			th := newTehai([]*hai.Hai{
+				hai.Manzu2, hai.Manzu3, hai.Manzu4, hai.Pinzu5, hai.Pinzu5, hai.Pinzu5,
+				hai.Souzu6, hai.Souzu7, hai.Souzu8, hai.Pinzu7,
+			})
+			n := &naki.NakiMock{ChiisMock: [][3]*hai.Hai{{hai.Pinzu1, hai.Pinzu2, hai.Pinzu3}}}
+			y := &yama.YamaMock{HaiMock: hai.Pinzu7, BoolMock: c.beforeEmpty}
+			p := playerImpl{tehai: th, naki: n, kawa: &kawa.KawaMock{}, yama: y}
+			var ok bool
+			var err error
+			switch {
+			case c.inRinshan:
+				assert.NoError(t, p.Rinshan())
+				ok, err = p.CanTsumoAgari()
+			case c.inTsumo:
+				assert.NoError(t, p.Tsumo())
+				ok, err = p.CanTsumoAgari()
+			default:
+				ok, err = p.CanRon(hai.Pinzu7)
 			}
+			assert.NoError(t, err)
 			assert.Equal(t, c.outBool, ok)
 		})
 	}
 }
 
-func TestCanRon(t *testing.T) {
+func TestIsFuriten(t *testing.T) {
 	cases := []struct {
-		name           string
-		beforeIsRiichi bool
-		beforeTehai    tehai.Tehai
-		beforeNaki     naki.Naki
-		inHai          *hai.Hai
-		outBool        bool
-		outError       error
+		name                  string
+		beforeKawa            kawa.Kawa
+		beforeIsFuriten       bool
+		beforeIsRiichiFuriten bool
+		outBool               bool
 	}{
 		{
-			name:           "successs: riichi",
-			beforeIsRiichi: true,
-			beforeTehai:    &tehai.TehaiMock{BoolMock: true},
-			beforeNaki:     &naki.NakiMock{},
-			inHai:          hai.Pinzu1,
-			outBool:        true,
+			name:       "success: no furiten",
+			beforeKawa: &kawa.KawaMock{HaisMock: []*hai.Hai{hai.Ton, hai.Manzu9}},
+			outBool:    false,
 		},
 		{
-			name:        "successs: tanyao",
-			beforeTehai: &tehai.TehaiMock{BoolMock: true},
-			beforeNaki: &naki.NakiMock{
-				ChiisMock: [][3]*hai.Hai{{hai.Pinzu2, hai.Pinzu3, hai.Pinzu4}},
-			},
-			inHai:   hai.Pinzu7,
+			name:       "success: machihai in kawa",
+			beforeKawa: &kawa.KawaMock{HaisMock: []*hai.Hai{hai.Ton, hai.Souzu2}},
+			outBool:    true,
+		},
+		{
+			name:            "success: skipped ron",
+			beforeKawa:      &kawa.KawaMock{},
+			beforeIsFuriten: true,
+			outBool:         true,
+		},
+		{
+			name:                  "success: skipped ron after riichi",
+			beforeKawa:            &kawa.KawaMock{},
+			beforeIsRiichiFuriten: true,
+			outBool:               true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p := playerImpl{
+				tehai: newTehai([]*hai.Hai{
+					hai.Manzu2, hai.Manzu3, hai.Manzu4, hai.Pinzu5, hai.Pinzu5, hai.Pinzu5,
+					hai.Souzu6, hai.Souzu7, hai.Souzu8, hai.Souzu3, hai.Souzu4, hai.Hatsu,
+					hai.Hatsu,
+				}),
+				naki:            &naki.NakiMock{},
+				kawa:            c.beforeKawa,
+				isFuriten:       c.beforeIsFuriten,
+				isRiichiFuriten: c.beforeIsRiichiFuriten,
+			}
+			ok, err := p.IsFuriten()
+			assert.NoError(t, err)
+			assert.Equal(t, c.outBool, ok)
+		})
+	}
+}
+
+func TestSkipRon(t *testing.T) {
+	p := playerImpl{tehai: &tehai.TehaiMock{}, kawa: &kawa.KawaMock{}, tsumohai: hai.Haku, isRiichi: true}
+	p.SkipRon()
+	assert.True(t, p.isFuriten)
+	assert.True(t, p.isRiichiFuriten)
+
+	// the temporary furiten lasts until the next dahai
+	assert.NoError(t, p.Dahai(hai.Haku))
+	assert.False(t, p.isFuriten)
+	assert.True(t, p.isRiichiFuriten)
+}
+
+func TestIsTenpai(t *testing.T) {
+	cases := []struct {
+		name        string
+		beforeTehai tehai.Tehai
+		beforeNaki  naki.Naki
+		outBool     bool
+	}{
+		{
+			name: "success: tenpai without yaku",
+			beforeTehai: newTehai([]*hai.Hai{
+				hai.Manzu1, hai.Manzu2, hai.Manzu3, hai.Souzu2, hai.Souzu3, hai.Souzu4,
+				hai.Pinzu7, hai.Pinzu7, hai.Pei, hai.Pei,
+			}),
 			outBool: true,
 		},
 		{
-			name:        "failure",
-			beforeTehai: &tehai.TehaiMock{BoolMock: true},
-			beforeNaki: &naki.NakiMock{
-				ChiisMock: [][3]*hai.Hai{{hai.Pinzu2, hai.Pinzu3, hai.Pinzu1}},
-			},
-			inHai:   hai.Pinzu7,
+			name: "success: noten",
+			beforeTehai: newTehai([]*hai.Hai{
+				hai.Manzu1, hai.Manzu2, hai.Manzu3, hai.Souzu2, hai.Souzu3, hai.Souzu4,
+				hai.Pinzu7, hai.Pinzu1, hai.Pei, hai.Nan,
+			}),
+			outBool: false,
+		},
+		{
+			name: "success: noten waiting for the hai of the four in the tehai",
+			beforeTehai: newTehai([]*hai.Hai{
+				hai.Manzu1, hai.Manzu2, hai.Manzu3, hai.Souzu2, hai.Souzu3, hai.Souzu4,
+				hai.Pinzu7, hai.Pinzu8, hai.Pinzu9, hai.Ton, hai.Ton, hai.Ton, hai.Ton,
+			}),
 			outBool: false,
 		},
+		{
+			name: "success: noten waiting for the hai of the four with the pon",
+			beforeTehai: newTehai([]*hai.Hai{
+				hai.Manzu1, hai.Manzu2, hai.Manzu3, hai.Souzu2, hai.Souzu3, hai.Souzu4,
+				hai.Pinzu7, hai.Pinzu8, hai.Pinzu9, hai.Ton,
+			}),
+			beforeNaki: &naki.NakiMock{PonsMock: [][3]*hai.Hai{{hai.Ton, hai.Ton, hai.Ton}}},
+			outBool:    false,
+		},
 	}
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			p := playerImpl{tehai: c.beforeTehai, naki: c.beforeNaki, isRiichi: c.beforeIsRiichi}
-			ok, err := p.CanRon(c.inHai)
-			if err != nil {
-				assert.Equal(t, c.outError, err)
-				return
+			if c.beforeNaki == nil {
+				c.beforeNaki = &naki.NakiMock{}
 			}
+			p := playerImpl{tehai: c.beforeTehai, naki: c.beforeNaki}
+			ok, err := p.IsTenpai()
+			assert.NoError(t, err)
 			assert.Equal(t, c.outBool, ok)
 		})
 	}
 }
+
+func TestFirstTurn(t *testing.T) {
+	riichi := func(p *playerImpl) error {
+		p.tsumohai = hai.Chun
+		return p.Riichi(hai.Chun)
+	}
+	cases := []struct {
+		name          string
+		beforeJikaze  *hai.Hai
+		beforeIsFirst bool
+		inPlay        []func(*playerImpl) error
+		outNames      []string
+	}{
+		{
+			name:          "success: tenhou",
+			beforeJikaze:  hai.Ton,
+			beforeIsFirst: true,
+			outNames:      []string{"tenhou"},
+		},
+		{
+			name:          "success: chiihou",
+			beforeJikaze:  hai.Nan,
+			beforeIsFirst: true,
+			outNames:      []string{"chiihou"},
+		},
+		{
+			name:          "success: the call breaks the first go-around",
+			beforeJikaze:  hai.Nan,
+			beforeIsFirst: true,
+			inPlay:        []func(*playerImpl) error{func(p *playerImpl) error { p.Interrupt(); return nil }},
+			outNames:      []string{"menzen tsumo"},
+		},
+		{
+			name:          "success: double riichi ippatsu",
+			beforeJikaze:  hai.Nan,
+			beforeIsFirst: true,
+			inPlay:        []func(*playerImpl) error{riichi},
+			outNames:      []string{"ippatsu", "menzen tsumo", "double riichi"},
+		},
+		{
+			name:         "success: riichi ippatsu",
+			beforeJikaze: hai.Nan,
+			inPlay:       []func(*playerImpl) error{riichi},
+			outNames:     []string{"riichi", "ippatsu", "menzen tsumo"},
+		},
+		{
+			name:         "success: the call breaks the ippatsu",
+			beforeJikaze: hai.Nan,
+			inPlay:       []func(*playerImpl) error{riichi, func(p *playerImpl) error { p.Interrupt(); return nil }},
+			outNames:     []string{"riichi", "menzen tsumo"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			th := newTehai([]*hai.Hai{
+				hai.Manzu1, hai.Manzu2, hai.Manzu3, hai.Souzu2, hai.Souzu3, hai.Souzu4,
+				hai.Pinzu4, hai.Pinzu5, hai.Pinzu6, hai.Pinzu7, hai.Pinzu7, hai.Pei, hai.Pei,
+			})
+			p := &playerImpl{
+				tehai:       th,
+				naki:        &naki.NakiMock{},
+				kawa:        &kawa.KawaMock{},
+				yama:        &yama.YamaMock{HaiMock: hai.Pei},
+				jikaze:      c.beforeJikaze,
+				point:       DefaultPoint,
+				isFirstTurn: c.beforeIsFirst,
+			}
+			for _, play := range c.inPlay {
+				assert.NoError(t, play(p))
+			}
+			assert.NoError(t, p.Tsumo())
+			s, err := p.Score(p.Tsumohai(), true)
+			assert.NoError(t, err)
+			names := []string{}
+			for _, y := range s.Yakus {
+				names = append(names, y.Name)
+			}
+			assert.Equal(t, c.outNames, names)
+		})
+	}
+}
@@ -0,0 +1,353 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"mahjong/model/agent"
+	"mahjong/model/board"
+	"mahjong/model/hai"
+	"mahjong/model/player"
+	"mahjong/model/yama"
+	"sort"
+)
+
+var (
+	// Version of the messages, raised on every change breaking the clients
+	Version = 1
+
+	// the types of the messages from the server
+	HelloType    = "hello"
+	NameType     = "name"
+	RoomsType    = "rooms"
+	PrivateType  = "private"
+	RoomType     = "room"
+	SessionType  = "session"
+	StateType    = "state"
+	ResultType   = "result"
+	MatchEndType = "match_end"
+	ErrorType    = "error"
+
+	// the types of the messages from the client
+	ActionType  = "action"
+	CommandType = "command"
+)
+
+// Hello is the first message of the json protocol.
+type Hello struct {
+	Type    string `json:"type"`
+	Version int    `json:"version"`
+}
+
+func NewHello() *Hello {
+	return &Hello{Type: HelloType, Version: Version}
+}
+
+// Name is the nickname of the user set in the lobby.
+type Name struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+func NewName(name string) *Name {
+	return &Name{Type: NameType, Name: name}
+}
+
+// LobbyRoom is an open room in the lobby, the code of a private room is not shown.
+type LobbyRoom struct {
+	Rule      string   `json:"rule"`
+	Current   int      `json:"current"`
+	Max       int      `json:"max"`
+	Names     []string `json:"names"`
+	IsPrivate bool     `json:"is_private"`
+}
+
+// Rooms is the list of the open rooms in the lobby.
+type Rooms struct {
+	Type  string       `json:"type"`
+	Rooms []*LobbyRoom `json:"rooms"`
+}
+
+func NewRooms(rooms []*LobbyRoom) *Rooms {
+	return &Rooms{Type: RoomsType, Rooms: rooms}
+}
+
+// Private is the private room made by the user, the others join it by the code.
+type Private struct {
+	Type string `json:"type"`
+	Code string `json:"code"`
+	Rule string `json:"rule"`
+}
+
+func NewPrivate(code string, rule string) *Private {
+	return &Private{Type: PrivateType, Code: code, Rule: rule}
+}
+
+// Room is the number of the users waiting in the room.
+type Room struct {
+	Type    string `json:"type"`
+	Current int    `json:"current"`
+	Max     int    `json:"max"`
+}
+
+func NewRoom(current int, max int) *Room {
+	return &Room{Type: RoomType, Current: current, Max: max}
+}
+
+// Session is the token to resume the seat from a new connection.
+type Session struct {
+	Type  string `json:"type"`
+	Token string `json:"token"`
+}
+
+func NewSession(token string) *Session {
+	return &Session{Type: SessionType, Token: token}
+}
+
+// Action is a legal action, the id is sent back to take it.
+type Action struct {
+	ID int `json:"id"`
+	*agent.Action
+}
+
+// State is the board seen from the player with the actions the player can take now.
+// the seats are counted from the player, 0 is the player, 1 shimocha, 2 toimen and 3 kamicha.
+type State struct {
+	Type string `json:"type"`
+	// the number of the state on the connection, the actions are taken with it
+	Seq     int          `json:"seq"`
+	State   *agent.State `json:"state"`
+	Actions []*Action    `json:"actions"`
+	// the seat having the turn
+	Turn int `json:"turn"`
+	// the seats deciding their calls
+	Waiting []int `json:"waiting"`
+	// the thinking time left in seconds, both 0 when there is no limit
+	BaseTime    int `json:"base_time"`
+	ReserveTime int `json:"reserve_time"`
+}
+
+// NewState reads the board with it locked.
+func NewState(b board.Board, p player.Player) (*State, error) {
+	base, reserve, err := b.ThinkingTime(p)
+	if err != nil {
+		return nil, err
+	}
+	m := &State{Type: StateType, Actions: []*Action{}, Waiting: []int{}}
+	m.BaseTime, m.ReserveTime = int(base.Seconds()), int(reserve.Seconds())
+	err = b.View(func() error {
+		s, err := agent.NewState(b, p)
+		if err != nil {
+			return err
+		}
+		m.State = s
+		actions, err := agent.Actions(b, p)
+		if err != nil {
+			return err
+		}
+		for i, a := range actions {
+			m.Actions = append(m.Actions, &Action{ID: i, Action: a})
+		}
+		if m.Turn, err = seatOf(b, p, b.Players()[b.CurrentTurn()].Player); err != nil {
+			return err
+		}
+		for _, wp := range b.WaitingPlayers() {
+			seat, err := seatOf(b, p, wp)
+			if err != nil {
+				return err
+			}
+			m.Waiting = append(m.Waiting, seat)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+type Yaku struct {
+	Name string `json:"name"`
+	Han  int    `json:"han"`
+}
+
+// Result is the end of the hand, the seats are counted from the player and -1 is nobody.
+type Result struct {
+	Type        string `json:"type"`
+	IsRyuukyoku bool   `json:"is_ryuukyoku"`
+	Winner      int    `json:"winner"`
+	// -1 on tsumo
+	Loser int `json:"loser"`
+	// the tehai of the winner without the agari hai
+	Tehai    []string `json:"tehai"`
+	AgariHai string   `json:"agari_hai"`
+	Yakus    []*Yaku  `json:"yakus"`
+	Fu       int      `json:"fu"`
+	Han      int      `json:"han"`
+	Limit    string   `json:"limit"`
+	UraDora  []string `json:"ura_dora"`
+	Tenpais  []bool   `json:"tenpais"`
+	// the points moved by the hand and the points after it
+	Points []int `json:"points"`
+	Scores []int `json:"scores"`
+	// the salt and the wall to check the commitment, empty when the wall has no commitment
+	Salt string `json:"salt"`
+	Wall string `json:"wall"`
+}
+
+func NewResult(b board.Board, p player.Player) (*Result, error) {
+	result := b.Result()
+	if result == nil {
+		return nil, ProtocolInvalidMessageErr
+	}
+	idx, err := b.MyTurn(p)
+	if err != nil {
+		return nil, err
+	}
+	players := b.Players()
+	m := &Result{
+		Type:        ResultType,
+		IsRyuukyoku: result.IsRyuukyoku,
+		Winner:      -1,
+		Loser:       -1,
+		Tehai:       []string{},
+		Yakus:       []*Yaku{},
+		UraDora:     []string{},
+		Tenpais:     []bool{},
+		Points:      []int{},
+		Scores:      []int{},
+	}
+	for i := range players {
+		turnIdx := (idx + i) % len(players)
+		m.Points = append(m.Points, result.Points[turnIdx])
+		m.Scores = append(m.Scores, players[turnIdx].Point())
+		if result.IsRyuukyoku {
+			m.Tenpais = append(m.Tenpais, result.Tenpais[turnIdx])
+		}
+	}
+	if result.Winner != nil {
+		if m.Winner, err = seatOf(b, p, result.Winner); err != nil {
+			return nil, err
+		}
+		if result.Loser != nil {
+			if m.Loser, err = seatOf(b, p, result.Loser); err != nil {
+				return nil, err
+			}
+		}
+		m.Tehai = names(result.Tehai)
+		if result.AgariHai != nil {
+			m.AgariHai = result.AgariHai.Name()
+		}
+		if result.Score != nil {
+			for _, y := range result.Score.Yakus {
+				m.Yakus = append(m.Yakus, &Yaku{Name: y.Name, Han: y.Han})
+			}
+			m.Fu, m.Han, m.Limit = result.Score.Fu, result.Score.Han, string(result.Score.Limit)
+		}
+		if result.Winner.IsRiichi() {
+			m.UraDora = names(b.Yama().UraDora())
+		}
+	}
+	if b.Yama().Commitment() != "" {
+		wall, salt := b.Yama().Reveal()
+		m.Salt, m.Wall = hex.EncodeToString(salt), yama.WallString(wall)
+	}
+	return m, nil
+}
+
+// MatchEnd is the end of the match, the ranks are counted from the player and the ties go to the earlier seat from the first oya.
+type MatchEnd struct {
+	Type   string `json:"type"`
+	Ranks  []int  `json:"ranks"`
+	Scores []int  `json:"scores"`
+	Seed   int64  `json:"seed"`
+	// the paifu is found by the board id
+	BoardID string `json:"board_id"`
+}
+
+func NewMatchEnd(b board.Board, p player.Player, id string) (*MatchEnd, error) {
+	idx, err := b.MyTurn(p)
+	if err != nil {
+		return nil, err
+	}
+	players := b.Players()
+	order := []int{}
+	for i := range players {
+		order = append(order, i)
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return players[order[i]].Point() > players[order[j]].Point()
+	})
+	ranks := make([]int, len(players))
+	for rank, turnIdx := range order {
+		ranks[turnIdx] = rank + 1
+	}
+	m := &MatchEnd{Type: MatchEndType, Ranks: []int{}, Scores: []int{}, Seed: b.Seed(), BoardID: id}
+	for i := range players {
+		turnIdx := (idx + i) % len(players)
+		m.Ranks = append(m.Ranks, ranks[turnIdx])
+		m.Scores = append(m.Scores, players[turnIdx].Point())
+	}
+	return m, nil
+}
+
+type Error struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+func NewError(err error) *Error {
+	return &Error{Type: ErrorType, Message: err.Error()}
+}
+
+// Command is the message from the client, the id of an action in the state of the seq or a command typed in the text mode.
+type Command struct {
+	Type    string `json:"type"`
+	Seq     int    `json:"seq"`
+	ID      int    `json:"id"`
+	Command string `json:"command"`
+}
+
+// ParseCommand returns the text command of the message, the action is taken from the last state sent to the client.
+func ParseCommand(raw []byte, last *State) (string, error) {
+	raw = bytes.TrimSpace(bytes.Trim(raw, "\x00"))
+	c := &Command{ID: -1}
+	if err := json.Unmarshal(raw, c); err != nil {
+		return "", ProtocolInvalidMessageErr
+	}
+	switch c.Type {
+	case ActionType:
+		if last == nil || c.Seq != last.Seq {
+			// the board has changed after the state the client saw
+			return "", ProtocolStaleActionErr
+		}
+		if c.ID < 0 || c.ID >= len(last.Actions) {
+			return "", ProtocolInvalidActionErr
+		}
+		return last.Actions[c.ID].Command, nil
+	case CommandType:
+		return c.Command, nil
+	}
+	return "", ProtocolInvalidMessageErr
+}
+
+func seatOf(b board.Board, p player.Player, other player.Player) (int, error) {
+	idx, err := b.MyTurn(p)
+	if err != nil {
+		return -1, err
+	}
+	otherIdx, err := b.MyTurn(other)
+	if err != nil {
+		return -1, err
+	}
+	n := len(b.Players())
+	return (otherIdx - idx + n) % n, nil
+}
+
+func names(hais []*hai.Hai) []string {
+	strs := []string{}
+	for _, h := range hais {
+		strs = append(strs, h.Name())
+	}
+	return strs
+}
@@ -0,0 +1,9 @@
+package protocol
+
+import "errors"
+
+var (
+	ProtocolInvalidMessageErr = errors.New("invalid message")
+	ProtocolInvalidActionErr  = errors.New("the action id is not in the actions of the state")
+	ProtocolStaleActionErr    = errors.New("the seq is not of the last state")
+)
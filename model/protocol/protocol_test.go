@@ -0,0 +1,215 @@
+package protocol
+
+import (
+	"encoding/json"
+	"mahjong/model/agent"
+	"mahjong/model/board"
+	"mahjong/model/kawa"
+	"mahjong/model/match"
+	"mahjong/model/naki"
+	"mahjong/model/player"
+	"mahjong/model/tehai"
+	"mahjong/model/yama"
+	"mahjong/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newBoard(t *testing.T) board.Board {
+	rule := *match.Tonpuusen
+	rule.BaseTime, rule.ReserveTime = 0, 0
+	b := board.New(board.MaxNumberOfUsers, match.New(&rule, board.MaxNumberOfUsers), 1, yama.NewWithRand, nil)
+	for i := 0; i < board.MaxNumberOfUsers; i++ {
+		_, err := b.JoinPlayer(player.New(utils.NewUUID(), kawa.New(), tehai.New(), naki.New()))
+		assert.NoError(t, err)
+	}
+	return b
+}
+
+func TestNewState(t *testing.T) {
+	b := newBoard(t)
+	b.Players()[1].SetName("alice")
+	for i, tp := range b.Players() {
+		s, err := NewState(b, tp.Player)
+		assert.NoError(t, err)
+		assert.Equal(t, StateType, s.Type)
+		actions, err := agent.Actions(b, tp.Player)
+		assert.NoError(t, err)
+		assert.Equal(t, len(actions), len(s.Actions))
+		for j, a := range s.Actions {
+			assert.Equal(t, j, a.ID)
+			assert.Equal(t, actions[j].Command, a.Command)
+		}
+		// the oya has the turn, seen from the seat counted by the player
+		assert.Equal(t, (b.CurrentTurn()-i+len(b.Players()))%len(b.Players()), s.Turn)
+		assert.Equal(t, []int{}, s.Waiting)
+		// the seat of the named player, the others have no name
+		n := len(b.Players())
+		for j, seat := range s.State.Seats {
+			name := ""
+			if j == (1-i+n)%n {
+				name = "alice"
+			}
+			assert.Equal(t, name, seat.Name)
+		}
+	}
+}
+
+func TestNewMatchEnd(t *testing.T) {
+	b := newBoard(t)
+	for i, point := range []int{-5000, 10000, 0, 0} {
+		b.Players()[i].AddPoint(point)
+	}
+	cases := []struct {
+		name      string
+		inIdx     int
+		outRanks  []int
+		outScores []int
+	}{
+		{
+			name:      "success: from the first seat",
+			inIdx:     0,
+			outRanks:  []int{4, 1, 2, 3},
+			outScores: []int{20000, 35000, 25000, 25000},
+		},
+		{
+			name:      "success: the ties by the seats from the first oya",
+			inIdx:     3,
+			outRanks:  []int{3, 4, 1, 2},
+			outScores: []int{25000, 20000, 35000, 25000},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			m, err := NewMatchEnd(b, b.Players()[c.inIdx].Player, "id")
+			assert.NoError(t, err)
+			assert.Equal(t, c.outRanks, m.Ranks)
+			assert.Equal(t, c.outScores, m.Scores)
+			assert.Equal(t, int64(1), m.Seed)
+			assert.Equal(t, "id", m.BoardID)
+		})
+	}
+}
+
+func TestParseCommand(t *testing.T) {
+	b := newBoard(t)
+	oya := b.Players()[b.CurrentTurn()].Player
+	last, err := NewState(b, oya)
+	assert.NoError(t, err)
+	last.Seq = 3
+
+	cases := []struct {
+		name       string
+		inRaw      string
+		inLast     *State
+		outCommand string
+		outError   error
+	}{
+		{
+			name:       "success: action",
+			inRaw:      `{"type": "action", "seq": 3, "id": 1}` + "\n\x00\x00",
+			inLast:     last,
+			outCommand: last.Actions[1].Command,
+		},
+		{
+			name:       "success: command",
+			inRaw:      `{"type": "command", "command": "pon 0"}`,
+			outCommand: "pon 0",
+		},
+		{
+			name:     "failure: old seq",
+			inRaw:    `{"type": "action", "seq": 2, "id": 1}`,
+			inLast:   last,
+			outError: ProtocolStaleActionErr,
+		},
+		{
+			name:     "failure: no state",
+			inRaw:    `{"type": "action", "seq": 0, "id": 1}`,
+			outError: ProtocolStaleActionErr,
+		},
+		{
+			name:     "failure: no id",
+			inRaw:    `{"type": "action", "seq": 3}`,
+			inLast:   last,
+			outError: ProtocolInvalidActionErr,
+		},
+		{
+			name:     "failure: out of the actions",
+			inRaw:    `{"type": "action", "seq": 3, "id": 100}`,
+			inLast:   last,
+			outError: ProtocolInvalidActionErr,
+		},
+		{
+			name:     "failure: unknown type",
+			inRaw:    `{"type": "ready"}`,
+			outError: ProtocolInvalidMessageErr,
+		},
+		{
+			name:     "failure: not json",
+			inRaw:    "m1",
+			outError: ProtocolInvalidMessageErr,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			command, err := ParseCommand([]byte(c.inRaw), c.inLast)
+			assert.Equal(t, c.outError, err)
+			assert.Equal(t, c.outCommand, command)
+		})
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	cases := []struct {
+		name string
+		in   interface{}
+		out  string
+	}{
+		{
+			name: "success: hello",
+			in:   NewHello(),
+			out:  `{"type":"hello","version":1}`,
+		},
+		{
+			name: "success: name",
+			in:   NewName("alice"),
+			out:  `{"type":"name","name":"alice"}`,
+		},
+		{
+			name: "success: rooms",
+			in:   NewRooms([]*LobbyRoom{{Rule: "hanchan", Current: 1, Max: 4, Names: []string{"alice"}, IsPrivate: true}}),
+			out:  `{"type":"rooms","rooms":[{"rule":"hanchan","current":1,"max":4,"names":["alice"],"is_private":true}]}`,
+		},
+		{
+			name: "success: no rooms",
+			in:   NewRooms([]*LobbyRoom{}),
+			out:  `{"type":"rooms","rooms":[]}`,
+		},
+		{
+			name: "success: private",
+			in:   NewPrivate("ABC234", "tonpuusen"),
+			out:  `{"type":"private","code":"ABC234","rule":"tonpuusen"}`,
+		},
+		{
+			name: "success: room",
+			in:   NewRoom(2, 4),
+			out:  `{"type":"room","current":2,"max":4}`,
+		},
+		{
+			name: "success: error",
+			in:   NewError(ProtocolInvalidMessageErr),
+			out:  `{"type":"error","message":"invalid message"}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			bytes, err := json.Marshal(c.in)
+			assert.NoError(t, err)
+			assert.Equal(t, c.out, string(bytes))
+		})
+	}
+}
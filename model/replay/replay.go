@@ -0,0 +1,415 @@
+package replay
+
+import (
+	"mahjong/model/board"
+	"mahjong/model/hai"
+	"mahjong/model/kawa"
+	"mahjong/model/match"
+	"mahjong/model/naki"
+	"mahjong/model/paifu"
+	"mahjong/model/player"
+	"mahjong/model/tehai"
+	"mahjong/model/yama"
+	"math/rand"
+
+	"github.com/google/uuid"
+)
+
+// Replay steps through a recorded match on the board, the events are played as the users did.
+type Replay interface {
+	Board() board.Board
+	Events() []*paifu.Event
+	// Step is the index of the last event played, the first hand is dealt with the start_game
+	Step() int
+	// Hands are the steps of the start_kyoku of every hand
+	Hands() []int
+
+	Forward() error
+	Back() error
+	Jump(step int) error
+}
+
+type replayImpl struct {
+	events []*paifu.Event
+	walls  [][]*hai.Hai
+	hands  []int
+	rule   *match.Rule
+	board  board.Board
+	step   int
+	// the seats declaring riichi with the next dahai
+	riichi []bool
+	// closed to stop draining the channels of the board
+	done chan struct{}
+}
+
+// New replays the events from the start_game by the rule recorded in it.
+func New(events []*paifu.Event) (Replay, error) {
+	if len(events) == 0 || events[0].Type != paifu.StartGame || len(events[0].Names) != board.MaxNumberOfUsers {
+		return nil, ReplayInvalidPaifuErr
+	}
+	ws, err := walls(events)
+	if err != nil {
+		return nil, err
+	}
+	hands := []int{}
+	for i, e := range events {
+		if e.Type == paifu.StartKyoku {
+			hands = append(hands, i)
+		}
+	}
+	if len(hands) != len(ws) {
+		return nil, ReplayInvalidPaifuErr
+	}
+
+	rule, err := match.AtoRule(events[0].Rule)
+	if err != nil {
+		return nil, ReplayInvalidPaifuErr
+	}
+	// no time limit on the replay
+	noTime := *rule
+	noTime.BaseTime, noTime.ReserveTime = 0, 0
+	r := &replayImpl{events: events, walls: ws, hands: hands, rule: &noTime, step: 0}
+	// the whole match is played once to check the paifu
+	if err := r.Jump(len(events) - 1); err != nil {
+		r.stop()
+		return nil, err
+	}
+	return r, r.Jump(0)
+}
+
+func (r *replayImpl) Board() board.Board {
+	return r.board
+}
+
+func (r *replayImpl) Events() []*paifu.Event {
+	return r.events
+}
+
+func (r *replayImpl) Step() int {
+	return r.step
+}
+
+func (r *replayImpl) Hands() []int {
+	return r.hands
+}
+
+func (r *replayImpl) Forward() error {
+	return r.Jump(r.step + 1)
+}
+
+func (r *replayImpl) Back() error {
+	return r.Jump(r.step - 1)
+}
+
+// Jump plays the events up to the step, going back deals the match again from the start.
+func (r *replayImpl) Jump(step int) error {
+	if step < 0 || step >= len(r.events) {
+		return ReplayOutOfRangeErr
+	}
+	if r.board == nil || step < r.step {
+		if err := r.reset(); err != nil {
+			return err
+		}
+	}
+	for r.step < step {
+		if err := r.play(r.events[r.step+1]); err != nil {
+			return err
+		}
+		r.step++
+	}
+	return nil
+}
+
+// reset seats the players and deals the first hand, the walls are taken in the order of the hands.
+func (r *replayImpl) reset() error {
+	r.stop()
+	r.done = make(chan struct{})
+	r.riichi = make([]bool, board.MaxNumberOfUsers)
+	r.step = 0
+	hand := 0
+	newYama := func(*rand.Rand) yama.Yama {
+		if hand >= len(r.walls) {
+			// never played, the paifu ends before the next hand
+			return yama.New()
+		}
+		y, _ := yama.NewWithWall(r.walls[hand])
+		hand++
+		return y
+	}
+	m := match.New(r.rule, board.MaxNumberOfUsers)
+	r.board = board.New(board.MaxNumberOfUsers, m, 0, newYama, nil)
+	for i := 0; i < board.MaxNumberOfUsers; i++ {
+		p := player.New(uuid.New(), kawa.New(), tehai.New(), naki.New())
+		channel, err := r.board.JoinPlayer(p)
+		if err != nil {
+			return err
+		}
+		go r.drain(channel, r.done)
+	}
+	return nil
+}
+
+// drain discards the boards broadcast to the seats, nobody is watching them.
+func (r *replayImpl) drain(channel chan board.Board, done chan struct{}) {
+	for {
+		select {
+		case <-channel:
+		case <-done:
+			return
+		}
+	}
+}
+
+func (r *replayImpl) stop() {
+	if r.done != nil {
+		close(r.done)
+		r.done = nil
+	}
+}
+
+func (r *replayImpl) player(seat int) (player.Player, error) {
+	players := r.board.Players()
+	if seat < 0 || seat >= len(players) {
+		return nil, ReplayInvalidPaifuErr
+	}
+	return players[seat].Player, nil
+}
+
+// play applies the event to the board in the same way as the commands of the users.
+func (r *replayImpl) play(e *paifu.Event) error {
+	b := r.board
+	switch e.Type {
+	case paifu.ReachAccepted, paifu.EndGame:
+	default:
+		// the calls are waited until the next event tells who took the discard
+		if err := r.resolve(e); err != nil {
+			return err
+		}
+	}
+
+	switch e.Type {
+	case paifu.StartKyoku:
+		if b.Result() != nil {
+			for _, tp := range b.Players() {
+				if err := b.Ready(tp.Player); err != nil {
+					return err
+				}
+			}
+		}
+		return r.checkStartKyoku(e)
+	case paifu.Tsumo:
+		p, h, err := r.playerAndPai(e.Actor, e.Pai)
+		if err != nil {
+			return err
+		}
+		if p.Tsumohai() != h {
+			return ReplayMismatchErr
+		}
+	case paifu.Dahai:
+		p, h, err := r.playerAndPai(e.Actor, e.Pai)
+		if err != nil {
+			return err
+		}
+		if r.riichi[e.Actor] {
+			r.riichi[e.Actor] = false
+			return b.DeclareRiichi(p, h)
+		}
+		return b.Discard(p, h)
+	case paifu.Reach:
+		if e.Actor < 0 || e.Actor >= len(r.riichi) {
+			return ReplayInvalidPaifuErr
+		}
+		r.riichi[e.Actor] = true
+	case paifu.Ankan:
+		p, h, err := r.playerAndPai(e.Actor, firstPai(e.Consumed))
+		if err != nil {
+			return err
+		}
+		return b.AnKan(p, [4]*hai.Hai{h, h, h, h})
+	case paifu.Kakan:
+		p, h, err := r.playerAndPai(e.Actor, e.Pai)
+		if err != nil {
+			return err
+		}
+		return b.Kakan(p, h)
+	case paifu.Dora:
+		doras := b.Yama().OmoteDora()
+		if paifu.Pai(doras[len(doras)-1]) != e.DoraMarker {
+			return ReplayMismatchErr
+		}
+	case paifu.Hora:
+		if e.Actor == e.Target {
+			p, h, err := r.playerAndPai(e.Actor, e.Pai)
+			if err != nil {
+				return err
+			}
+			if p.Tsumohai() != h {
+				return ReplayMismatchErr
+			}
+			if err := b.TsumoAgari(p); err != nil {
+				return err
+			}
+		}
+		if b.Result() == nil || b.Result().IsRyuukyoku {
+			return ReplayMismatchErr
+		}
+		return r.checkScores(e.Scores)
+	case paifu.Ryukyoku:
+		if b.Result() == nil || !b.Result().IsRyuukyoku {
+			return ReplayMismatchErr
+		}
+		return r.checkScores(e.Scores)
+	case paifu.ReachAccepted:
+		if len(b.WaitingPlayers()) != 0 {
+			// the stick is put once the calls on the riichi dahai are decided by the next event
+			return nil
+		}
+		return r.checkScores(e.Scores)
+	case paifu.EndGame:
+		return r.checkScores(e.Scores)
+	}
+	return nil
+}
+
+// resolve decides the calls waited on the board, the caller of the event takes the discard and the others pass.
+func (r *replayImpl) resolve(e *paifu.Event) error {
+	b := r.board
+	var action board.ActionType
+	var take func(p player.Player, consumed []*hai.Hai) func(*hai.Hai) error
+	switch e.Type {
+	case paifu.Chi:
+		action = board.Chii
+		take = func(p player.Player, consumed []*hai.Hai) func(*hai.Hai) error {
+			return func(inHai *hai.Hai) error {
+				return p.Chii(inHai, [2]*hai.Hai{consumed[0], consumed[1]})
+			}
+		}
+	case paifu.Pon:
+		action = board.Pon
+		take = func(p player.Player, consumed []*hai.Hai) func(*hai.Hai) error {
+			return func(inHai *hai.Hai) error {
+				return p.Pon(inHai, [2]*hai.Hai{consumed[0], consumed[1]})
+			}
+		}
+	case paifu.Daiminkan:
+		action = board.Kan
+		take = func(p player.Player, consumed []*hai.Hai) func(*hai.Hai) error {
+			return func(inHai *hai.Hai) error {
+				if err := p.MinKan(inHai, [3]*hai.Hai{consumed[0], consumed[1], consumed[2]}); err != nil {
+					return err
+				}
+				return p.Rinshan()
+			}
+		}
+	case paifu.Hora:
+		if e.Actor == e.Target {
+			break
+		}
+		action = board.Ron
+		// the board plays the ron itself
+		take = func(player.Player, []*hai.Hai) func(*hai.Hai) error { return nil }
+	}
+
+	if len(b.WaitingPlayers()) == 0 {
+		if take != nil {
+			// nobody could take the discard
+			return ReplayMismatchErr
+		}
+		return nil
+	}
+	if take != nil {
+		p, err := r.player(e.Actor)
+		if err != nil {
+			return err
+		}
+		consumed := []*hai.Hai{}
+		for _, name := range e.Consumed {
+			h, err := paifu.AtoPai(name)
+			if err != nil {
+				return err
+			}
+			consumed = append(consumed, h)
+		}
+		if action != board.Ron && len(consumed) != callConsumed[action] {
+			return ReplayInvalidPaifuErr
+		}
+		if action == board.Ron {
+			err = b.RonAgari(p)
+		} else {
+			err = b.TakeAction(p, action, take(p, consumed))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	for _, p := range b.WaitingPlayers() {
+		if err := b.CancelAction(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var (
+	// the number of hais consumed from the tehai by the call
+	callConsumed = map[board.ActionType]int{board.Chii: 2, board.Pon: 2, board.Kan: 3}
+)
+
+func (r *replayImpl) playerAndPai(seat int, name string) (player.Player, *hai.Hai, error) {
+	p, err := r.player(seat)
+	if err != nil {
+		return nil, nil, err
+	}
+	h, err := paifu.AtoPai(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return p, h, nil
+}
+
+func firstPai(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	return names[0]
+}
+
+func (r *replayImpl) checkStartKyoku(e *paifu.Event) error {
+	b := r.board
+	m := b.Match()
+	if paifu.Pai(m.Bakaze()) != e.Bakaze || m.Kyoku() != e.Kyoku || m.Honba() != e.Honba ||
+		m.Kyoutaku() != e.Kyotaku || m.OyaIndex() != e.Oya {
+		return ReplayMismatchErr
+	}
+	for i, tp := range b.Players() {
+		if i >= len(e.Tehais) || !equalPais(tp.Tehai().Hais(), e.Tehais[i]) {
+			return ReplayMismatchErr
+		}
+	}
+	return r.checkScores(e.Scores)
+}
+
+func (r *replayImpl) checkScores(scores []int) error {
+	players := r.board.Players()
+	if len(scores) != len(players) {
+		return ReplayMismatchErr
+	}
+	for i, tp := range players {
+		if tp.Point() != scores[i] {
+			return ReplayMismatchErr
+		}
+	}
+	return nil
+}
+
+func equalPais(hais []*hai.Hai, names []string) bool {
+	if len(hais) != len(names) {
+		return false
+	}
+	for i, h := range hais {
+		if paifu.Pai(h) != names[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,9 @@
+package replay
+
+import "errors"
+
+var (
+	ReplayInvalidPaifuErr = errors.New("the paifu is not a recorded match")
+	ReplayMismatchErr     = errors.New("the board does not match the paifu")
+	ReplayOutOfRangeErr   = errors.New("the step is out of the paifu")
+)
@@ -0,0 +1,132 @@
+package replay_test
+
+import (
+	"io/ioutil"
+	"mahjong/model/agent"
+	"mahjong/model/board"
+	"mahjong/model/bot"
+	"mahjong/model/match"
+	"mahjong/model/paifu"
+	"mahjong/model/replay"
+	"mahjong/simulator"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// caller takes every call it can, to play the chii, pon and kan on the replay.
+type caller struct {
+	agent.Agent
+}
+
+func (c *caller) Choose(s *agent.State, actions []*agent.Action) (int, error) {
+	for i, a := range actions {
+		switch a.Type {
+		case board.Chii, board.Pon, board.Kan, board.Kakan:
+			return i, nil
+		}
+	}
+	return c.Agent.Choose(s, actions)
+}
+
+func record(t *testing.T, seed int64) []*paifu.Event {
+	dir, err := ioutil.TempDir("", "paifu")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	newAgents := []func() (agent.Agent, error){}
+	names := []string{}
+	for i := 0; i < board.MaxNumberOfUsers; i++ {
+		newAgents = append(newAgents, func() (agent.Agent, error) { return &caller{bot.New()}, nil })
+		names = append(names, "caller")
+	}
+	s, err := simulator.New(match.Tonpuusen, names, newAgents, seed, 1, dir)
+	assert.NoError(t, err)
+	_, err = s.Run(1, false)
+	assert.NoError(t, err)
+
+	events, err := paifu.ReadFile(paifu.Path(dir, strconv.FormatInt(seed, 10)))
+	assert.NoError(t, err)
+	return events
+}
+
+func TestNew(t *testing.T) {
+	events := record(t, 1)
+	r, err := replay.New(events)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, r.Step())
+	assert.Equal(t, paifu.StartKyoku, events[r.Hands()[0]].Type)
+
+	// the whole match is played out
+	assert.NoError(t, r.Jump(len(events)-1))
+	assert.True(t, r.Board().Match().IsEnd())
+	calls := 0
+	for _, e := range events {
+		switch e.Type {
+		case paifu.Chi, paifu.Pon, paifu.Daiminkan, paifu.Ankan, paifu.Kakan:
+			calls++
+		}
+	}
+	assert.NotEqual(t, 0, calls)
+
+	cases := []struct {
+		name   string
+		modify func([]*paifu.Event) []*paifu.Event
+		outErr error
+	}{
+		{
+			name:   "empty",
+			modify: func(events []*paifu.Event) []*paifu.Event { return []*paifu.Event{} },
+			outErr: replay.ReplayInvalidPaifuErr,
+		},
+		{
+			name: "other scores",
+			modify: func(events []*paifu.Event) []*paifu.Event {
+				other := *events[len(events)-1]
+				other.Scores = []int{100000, 0, 0, 0}
+				return append(append([]*paifu.Event{}, events[:len(events)-1]...), &other)
+			},
+			outErr: replay.ReplayMismatchErr,
+		},
+		{
+			name: "unknown rule",
+			modify: func(events []*paifu.Event) []*paifu.Event {
+				start := *events[0]
+				start.Rule = "sanma"
+				return append([]*paifu.Event{&start}, events[1:]...)
+			},
+			outErr: replay.ReplayInvalidPaifuErr,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := replay.New(c.modify(events))
+			assert.Equal(t, c.outErr, err)
+		})
+	}
+}
+
+func TestJump(t *testing.T) {
+	events := record(t, 2)
+	r, err := replay.New(events)
+	assert.NoError(t, err)
+
+	assert.NoError(t, r.Forward())
+	assert.Equal(t, 1, r.Step())
+	assert.NoError(t, r.Back())
+	assert.Equal(t, 0, r.Step())
+	assert.Equal(t, replay.ReplayOutOfRangeErr, r.Back())
+	assert.Equal(t, replay.ReplayOutOfRangeErr, r.Jump(len(events)))
+
+	// going back deals the same hand again
+	hand := r.Hands()[len(r.Hands())-1]
+	assert.NoError(t, r.Jump(hand))
+	tehai := r.Board().Players()[0].Tehai().Hais()
+	assert.NoError(t, r.Jump(hand+5))
+	assert.NoError(t, r.Jump(hand))
+	assert.Equal(t, tehai, r.Board().Players()[0].Tehai().Hais())
+	assert.Equal(t, events[hand].Kyoku, r.Board().Match().Kyoku())
+	assert.Equal(t, events[hand].Honba, r.Board().Match().Honba())
+}
@@ -0,0 +1,166 @@
+package replay
+
+import (
+	"mahjong/model/hai"
+	"mahjong/model/paifu"
+	"mahjong/model/tehai"
+)
+
+var (
+	// the positions in the wall of 136 hais, as dealt by the yama
+	wallLen    = 136
+	liveLen    = 122
+	rinshanLen = 4
+	// every kind of hai is in the wall four times
+	haiCount = 4
+)
+
+// wall keeps the hais seen in one hand at their positions in the wall.
+type wall struct {
+	hais    []*hai.Hai
+	live    int
+	rinshan int
+	// the omote dora indicators revealed
+	dora int
+	// the next tsumo is the rinshan hai of a kan
+	isRinshan bool
+}
+
+func newWall(e *paifu.Event) (*wall, error) {
+	w := &wall{hais: make([]*hai.Hai, wallLen), live: 0, rinshan: 0, dora: 0}
+	// the haipai is drawn by 13 hais for each seat in order
+	for _, names := range e.Tehais {
+		if len(names) != tehai.MaxHaisLen-1 {
+			return nil, ReplayInvalidPaifuErr
+		}
+		for _, name := range names {
+			if err := w.draw(name); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := w.flip(e.DoraMarker); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *wall) put(idx int, name string) error {
+	h, err := paifu.AtoPai(name)
+	if err != nil {
+		return err
+	}
+	if idx >= len(w.hais) || (w.hais[idx] != nil && w.hais[idx] != h) {
+		return ReplayInvalidPaifuErr
+	}
+	w.hais[idx] = h
+	return nil
+}
+
+func (w *wall) draw(name string) error {
+	if w.isRinshan {
+		w.isRinshan = false
+		w.rinshan++
+		if w.rinshan > rinshanLen {
+			return ReplayInvalidPaifuErr
+		}
+		return w.put(liveLen+w.rinshan-1, name)
+	}
+	w.live++
+	if w.live > liveLen {
+		return ReplayInvalidPaifuErr
+	}
+	return w.put(w.live-1, name)
+}
+
+// flip puts the next omote dora indicator, the indicators are the pairs of omote and ura after the rinshan hais.
+func (w *wall) flip(name string) error {
+	w.dora++
+	return w.put(liveLen+rinshanLen+(w.dora-1)*2, name)
+}
+
+func (w *wall) uraDoras(names []string) error {
+	for i, name := range names {
+		if err := w.put(liveLen+rinshanLen+i*2+1, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// build fills the positions never seen by the rest of the hais.
+func (w *wall) build() ([]*hai.Hai, error) {
+	cnt := map[*hai.Hai]int{}
+	for _, h := range w.hais {
+		if h != nil {
+			cnt[h]++
+		}
+	}
+	rest := []*hai.Hai{}
+	for _, h := range hai.All {
+		if cnt[h] > haiCount {
+			return nil, ReplayInvalidPaifuErr
+		}
+		for i := cnt[h]; i < haiCount; i++ {
+			rest = append(rest, h)
+		}
+	}
+	hais := append([]*hai.Hai{}, w.hais...)
+	for i := range hais {
+		if hais[i] == nil {
+			hais[i], rest = rest[0], rest[1:]
+		}
+	}
+	return hais, nil
+}
+
+// walls rebuilds the wall of every hand from the hais seen in the paifu.
+func walls(events []*paifu.Event) ([][]*hai.Hai, error) {
+	ws := [][]*hai.Hai{}
+	var w *wall
+	flush := func() error {
+		if w == nil {
+			return nil
+		}
+		hais, err := w.build()
+		if err != nil {
+			return err
+		}
+		ws = append(ws, hais)
+		w = nil
+		return nil
+	}
+	for _, e := range events {
+		if e.Type == paifu.StartKyoku {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			var err error
+			if w, err = newWall(e); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if w == nil {
+			continue
+		}
+		var err error
+		switch e.Type {
+		case paifu.Tsumo:
+			err = w.draw(e.Pai)
+		case paifu.Ankan, paifu.Kakan, paifu.Daiminkan:
+			w.isRinshan = true
+		case paifu.Dora:
+			err = w.flip(e.DoraMarker)
+		case paifu.Hora:
+			err = w.uraDoras(e.UradoraMarkers)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return ws, nil
+}
@@ -0,0 +1,175 @@
+package score
+
+import (
+	"mahjong/model/hai"
+	"mahjong/model/hai/attribute"
+	"mahjong/model/naki"
+	"mahjong/model/tehai"
+	"mahjong/model/yaku"
+)
+
+type Limit string
+
+var (
+	NoLimit   Limit = ""
+	Mangan    Limit = "mangan"
+	Haneman   Limit = "haneman"
+	Baiman    Limit = "baiman"
+	Sanbaiman Limit = "sanbaiman"
+	Yakuman   Limit = "yakuman"
+)
+
+type Score struct {
+	Agari *tehai.Agari
+	Yakus []*yaku.Yaku
+	Han   int
+	Fu    int
+	Base  int
+	Limit Limit
+}
+
+// Calculate scores every agari and returns the most valuable one, nil if no agari has yaku.
+func Calculate(agaris []*tehai.Agari, n naki.Naki, agariHai *hai.Hai, s *yaku.Situation) (*Score, error) {
+	var best *Score
+	for _, a := range agaris {
+		yakus, err := yaku.Evaluate(a, n, agariHai, s)
+		if err != nil {
+			return nil, err
+		}
+		if len(yakus) == 0 {
+			continue
+		}
+		fu, err := Fu(a, n, agariHai, s)
+		if err != nil {
+			return nil, err
+		}
+		han := yaku.Han(yakus)
+		base, limit := Base(han, fu)
+		score := &Score{Agari: a, Yakus: yakus, Han: han, Fu: fu, Base: base, Limit: limit}
+		if best == nil || score.Base > best.Base || (score.Base == best.Base && score.Han > best.Han) {
+			best = score
+		}
+	}
+	return best, nil
+}
+
+// Fu counts the fu of the agari, rounded up to 10.
+func Fu(a *tehai.Agari, n naki.Naki, agariHai *hai.Hai, s *yaku.Situation) (int, error) {
+	if a == nil || n == nil || agariHai == nil || s == nil {
+		return 0, ScoreInvalidArgumentErr
+	}
+	switch a.Type {
+	case tehai.Chiitoitsu:
+		return 25, nil
+	case tehai.Kokushi:
+		// yakuman does not depend on fu
+		return 30, nil
+	}
+	isMenzen := len(n.Chiis())+len(n.Pons())+len(n.MinKans()) == 0
+
+	fu := 0
+	// machi
+	switch a.Machi {
+	case tehai.Kanchan, tehai.Penchan, tehai.Tanki:
+		fu += 2
+	}
+	// janto
+	if a.Janto.HasAttribute(&attribute.Sangen) {
+		fu += 2
+	}
+	if a.Janto == s.Bakaze {
+		fu += 2
+	}
+	if a.Janto == s.Jikaze {
+		fu += 2
+	}
+	// mentsu
+	for _, m := range a.Mentsus {
+		if m[0] != m[1] {
+			continue
+		}
+		// the kotsu completed by ron is treated as an open one
+		isConcealed := s.IsTsumo || a.Machi != tehai.Shanpon || m[0] != agariHai
+		fu += kotsuFu(m[0], isConcealed, false)
+	}
+	for _, m := range n.Pons() {
+		fu += kotsuFu(m[0], false, false)
+	}
+	for _, m := range n.MinKans() {
+		fu += kotsuFu(m[0], false, true)
+	}
+	for _, m := range n.AnKans() {
+		fu += kotsuFu(m[0], true, true)
+	}
+
+	switch {
+	case isMenzen && s.IsTsumo && fu == 0:
+		// pinfu tsumo
+		return 20, nil
+	case isMenzen && !s.IsTsumo:
+		fu += 10
+	case s.IsTsumo:
+		fu += 2
+	}
+	fu += 20
+	if fu == 20 {
+		// kui pinfu
+		fu = 30
+	}
+	return (fu + 9) / 10 * 10, nil
+}
+
+func kotsuFu(h *hai.Hai, isConcealed bool, isKantsu bool) int {
+	fu := 2
+	if isConcealed {
+		fu *= 2
+	}
+	if isKantsu {
+		fu *= 4
+	}
+	if h.HasAttribute(&attribute.Jihai) || h.HasAttribute(&attribute.One) || h.HasAttribute(&attribute.Nine) {
+		fu *= 2
+	}
+	return fu
+}
+
+// Base returns the basic points of han and fu with the limit it reached.
+func Base(han int, fu int) (int, Limit) {
+	switch {
+	case han >= yaku.YakumanHan:
+		return 8000 * (han / yaku.YakumanHan), Yakuman
+	case han >= 11:
+		return 6000, Sanbaiman
+	case han >= 8:
+		return 4000, Baiman
+	case han >= 6:
+		return 3000, Haneman
+	case han >= 5:
+		return 2000, Mangan
+	}
+	base := fu * (1 << uint(2+han))
+	if base >= 2000 {
+		return 2000, Mangan
+	}
+	return base, NoLimit
+}
+
+// RonPoint is the point the loser pays to the winner.
+func RonPoint(base int, isOya bool) int {
+	if isOya {
+		return ceil100(base * 6)
+	}
+	return ceil100(base * 4)
+}
+
+// TsumoPoint is the point the oya and each ko pay to the winner.
+func TsumoPoint(base int, isOya bool) (int, int) {
+	if isOya {
+		return 0, ceil100(base * 2)
+	}
+	return ceil100(base * 2), ceil100(base)
+}
+
+func ceil100(point int) int {
+	return (point + 99) / 100 * 100
+}
@@ -0,0 +1,7 @@
+package score
+
+import "errors"
+
+var (
+	ScoreInvalidArgumentErr = errors.New("invalid argument")
+)
@@ -0,0 +1,165 @@
+package score
+
+import (
+	"mahjong/model/hai"
+	"mahjong/model/naki"
+	"mahjong/model/tehai"
+	"mahjong/model/yaku"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculate(t *testing.T) {
+	cases := []struct {
+		name        string
+		inHais      []*hai.Hai
+		inHai       *hai.Hai
+		inNaki      naki.Naki
+		inSituation *yaku.Situation
+		outHan      int
+		outFu       int
+		outBase     int
+		outLimit    Limit
+	}{
+		{
+			name: "pinfu tsumo",
+			inHais: []*hai.Hai{
+				hai.Manzu2, hai.Manzu3, hai.Manzu4, hai.Pinzu2, hai.Pinzu3, hai.Pinzu4,
+				hai.Souzu1, hai.Souzu2, hai.Souzu3, hai.Souzu6, hai.Souzu7, hai.Pinzu8,
+				hai.Pinzu8,
+			},
+			inHai:       hai.Souzu5,
+			inNaki:      &naki.NakiMock{},
+			inSituation: &yaku.Situation{IsTsumo: true},
+			outHan:      2,
+			outFu:       20,
+			outBase:     320,
+		},
+		{
+			name: "menzen ron with ankou",
+			inHais: []*hai.Hai{
+				hai.Manzu2, hai.Manzu3, hai.Manzu4, hai.Pinzu9, hai.Pinzu9, hai.Pinzu9,
+				hai.Souzu1, hai.Souzu2, hai.Souzu3, hai.Souzu6, hai.Souzu7, hai.Pinzu8,
+				hai.Pinzu8,
+			},
+			inHai:       hai.Souzu5,
+			inNaki:      &naki.NakiMock{},
+			inSituation: &yaku.Situation{IsRiichi: true},
+			outHan:      1,
+			outFu:       40,
+			outBase:     320,
+		},
+		{
+			name: "kui pinfu",
+			inHais: []*hai.Hai{
+				hai.Manzu2, hai.Manzu3, hai.Manzu4, hai.Pinzu2, hai.Pinzu3, hai.Pinzu4,
+				hai.Souzu6, hai.Souzu7, hai.Pinzu8, hai.Pinzu8,
+			},
+			inHai:       hai.Souzu5,
+			inNaki:      &naki.NakiMock{ChiisMock: [][3]*hai.Hai{{hai.Souzu3, hai.Souzu4, hai.Souzu2}}},
+			inSituation: &yaku.Situation{},
+			outHan:      2,
+			outFu:       30,
+			outBase:     480,
+		},
+		{
+			name: "chiitoitsu",
+			inHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu1, hai.Manzu5, hai.Manzu5, hai.Pinzu2, hai.Pinzu2,
+				hai.Pinzu7, hai.Pinzu7, hai.Souzu3, hai.Souzu3, hai.Pei, hai.Pei,
+				hai.Chun,
+			},
+			inHai:       hai.Chun,
+			inNaki:      &naki.NakiMock{},
+			inSituation: &yaku.Situation{IsRiichi: true},
+			outHan:      3,
+			outFu:       25,
+			outBase:     800,
+		},
+		{
+			name: "haneman",
+			inHais: []*hai.Hai{
+				hai.Souzu1, hai.Souzu2, hai.Souzu3, hai.Souzu4, hai.Souzu5, hai.Souzu6,
+				hai.Souzu7, hai.Souzu8, hai.Souzu9, hai.Souzu2,
+			},
+			inHai:       hai.Souzu2,
+			inNaki:      &naki.NakiMock{ChiisMock: [][3]*hai.Hai{{hai.Souzu6, hai.Souzu4, hai.Souzu5}}},
+			inSituation: &yaku.Situation{},
+			outHan:      6,
+			outFu:       30,
+			outBase:     3000,
+			outLimit:    Haneman,
+		},
+		{
+			name: "yakuman",
+			inHais: []*hai.Hai{
+				hai.Haku, hai.Haku, hai.Haku, hai.Chun, hai.Chun, hai.Chun,
+				hai.Manzu5, hai.Manzu5, hai.Manzu6, hai.Manzu7,
+			},
+			inHai:       hai.Manzu8,
+			inNaki:      &naki.NakiMock{PonsMock: [][3]*hai.Hai{{hai.Hatsu, hai.Hatsu, hai.Hatsu}}},
+			inSituation: &yaku.Situation{},
+			outHan:      13,
+			outFu:       40,
+			outBase:     8000,
+			outLimit:    Yakuman,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			th := tehai.New()
+			assert.NoError(t, th.Adds(c.inHais))
+			agaris, err := th.Agaris(c.inHai)
+			assert.NoError(t, err)
+			score, err := Calculate(agaris, c.inNaki, c.inHai, c.inSituation)
+			assert.NoError(t, err)
+			assert.Equal(t, c.outHan, score.Han)
+			assert.Equal(t, c.outFu, score.Fu)
+			assert.Equal(t, c.outBase, score.Base)
+			assert.Equal(t, c.outLimit, score.Limit)
+		})
+	}
+}
+
+func TestBase(t *testing.T) {
+	cases := []struct {
+		name     string
+		inHan    int
+		inFu     int
+		outBase  int
+		outLimit Limit
+	}{
+		{name: "1 han 30 fu", inHan: 1, inFu: 30, outBase: 240},
+		{name: "4 han 30 fu", inHan: 4, inFu: 30, outBase: 1920},
+		{name: "4 han 40 fu", inHan: 4, inFu: 40, outBase: 2000, outLimit: Mangan},
+		{name: "7 han", inHan: 7, inFu: 30, outBase: 3000, outLimit: Haneman},
+		{name: "10 han", inHan: 10, inFu: 30, outBase: 4000, outLimit: Baiman},
+		{name: "12 han", inHan: 12, inFu: 30, outBase: 6000, outLimit: Sanbaiman},
+		{name: "double yakuman", inHan: 26, inFu: 30, outBase: 16000, outLimit: Yakuman},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			base, limit := Base(c.inHan, c.inFu)
+			assert.Equal(t, c.outBase, base)
+			assert.Equal(t, c.outLimit, limit)
+		})
+	}
+}
+
+func TestRonPoint(t *testing.T) {
+	assert.Equal(t, 1000, RonPoint(240, false))
+	assert.Equal(t, 1500, RonPoint(240, true))
+	assert.Equal(t, 12000, RonPoint(2000, true))
+}
+
+func TestTsumoPoint(t *testing.T) {
+	oya, ko := TsumoPoint(240, false)
+	assert.Equal(t, 500, oya)
+	assert.Equal(t, 300, ko)
+	oya, ko = TsumoPoint(2000, true)
+	assert.Equal(t, 0, oya)
+	assert.Equal(t, 4000, ko)
+}
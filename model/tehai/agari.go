@@ -0,0 +1,207 @@
+package tehai
+
+import (
+	"mahjong/model/hai"
+	"mahjong/model/hai/attribute"
+)
+
+type Machi string
+
+var (
+	Ryanmen Machi = "ryanmen"
+	Kanchan Machi = "kanchan"
+	Penchan Machi = "penchan"
+	Shanpon Machi = "shanpon"
+	Tanki   Machi = "tanki"
+	// kokushi musou waiting for all 13 yaochu hais
+	Juusanmen Machi = "juusanmen"
+)
+
+type AgariType string
+
+var (
+	Ippan      AgariType = "ippan"
+	Chiitoitsu AgariType = "chiitoitsu"
+	Kokushi    AgariType = "kokushi"
+)
+
+var (
+	Yaochu = []*hai.Hai{
+		hai.Manzu1, hai.Manzu9, hai.Pinzu1, hai.Pinzu9, hai.Souzu1, hai.Souzu9,
+		hai.Ton, hai.Nan, hai.Sha, hai.Pei, hai.Haku, hai.Hatsu, hai.Chun,
+	}
+)
+
+// Agari is one interpretation of a closed hand completed by the agari hai.
+// Toitsus is only used by chiitoitsu, and Janto of kokushi is the doubled yaochu hai.
+type Agari struct {
+	Type    AgariType
+	Janto   *hai.Hai
+	Mentsus [][3]*hai.Hai
+	Toitsus []*hai.Hai
+	Machi   Machi
+}
+
+// Agaris lists every way the tehai plus inHai split into a janto and mentsus, one per machi.
+func (t *tehaiImpl) Agaris(inHai *hai.Hai) ([]*Agari, error) {
+	agaris := []*Agari{}
+	if inHai == nil {
+		return agaris, nil
+	}
+
+	cnt := [34]int{}
+	for _, h := range append([]*hai.Hai{inHai}, t.hais...) {
+		idx, err := haiIndex(h)
+		if err != nil {
+			return agaris, err
+		}
+		cnt[idx]++
+	}
+
+	for i := range cnt {
+		if cnt[i] < 2 {
+			continue
+		}
+		cnt[i] -= 2
+		for _, mentsus := range mentsuPatterns(&cnt, 0) {
+			agaris = append(agaris, withMachi(hai.All[i], mentsus, inHai)...)
+		}
+		cnt[i] += 2
+	}
+
+	if len(t.hais) != MaxHaisLen-1 {
+		return agaris, nil
+	}
+	if a := chiitoitsu(&cnt); a != nil {
+		agaris = append(agaris, a)
+	}
+	if a := kokushi(&cnt, inHai); a != nil {
+		agaris = append(agaris, a)
+	}
+	return agaris, nil
+}
+
+func chiitoitsu(cnt *[34]int) *Agari {
+	toitsus := []*hai.Hai{}
+	for i, c := range cnt {
+		switch c {
+		case 0:
+			continue
+		case 2:
+			toitsus = append(toitsus, hai.All[i])
+		default:
+			// four same hais can not be two toitsus
+			return nil
+		}
+	}
+	return &Agari{Type: Chiitoitsu, Toitsus: toitsus, Machi: Tanki}
+}
+
+func kokushi(cnt *[34]int, inHai *hai.Hai) *Agari {
+	var janto *hai.Hai
+	for _, h := range Yaochu {
+		idx, _ := haiIndex(h)
+		switch cnt[idx] {
+		case 1:
+		case 2:
+			if janto != nil {
+				return nil
+			}
+			janto = h
+		default:
+			return nil
+		}
+	}
+	if janto == nil {
+		return nil
+	}
+
+	machi := Tanki
+	if janto == inHai {
+		machi = Juusanmen
+	}
+	return &Agari{Type: Kokushi, Janto: janto, Machi: machi}
+}
+
+func mentsuPatterns(cnt *[34]int, start int) [][][3]*hai.Hai {
+	for start < len(cnt) && cnt[start] == 0 {
+		start++
+	}
+	if start == len(cnt) {
+		return [][][3]*hai.Hai{{}}
+	}
+
+	patterns := [][][3]*hai.Hai{}
+	h := hai.All[start]
+	// kotsu
+	if cnt[start] >= 3 {
+		cnt[start] -= 3
+		for _, p := range mentsuPatterns(cnt, start) {
+			patterns = append(patterns, append([][3]*hai.Hai{{h, h, h}}, p...))
+		}
+		cnt[start] += 3
+	}
+	// shuntsu
+	if start < 27 && start%9 <= 6 && cnt[start+1] > 0 && cnt[start+2] > 0 {
+		cnt[start]--
+		cnt[start+1]--
+		cnt[start+2]--
+		for _, p := range mentsuPatterns(cnt, start) {
+			patterns = append(patterns, append([][3]*hai.Hai{{h, hai.All[start+1], hai.All[start+2]}}, p...))
+		}
+		cnt[start]++
+		cnt[start+1]++
+		cnt[start+2]++
+	}
+
+	return patterns
+}
+
+func withMachi(janto *hai.Hai, mentsus [][3]*hai.Hai, inHai *hai.Hai) []*Agari {
+	agaris := []*Agari{}
+	if janto == inHai {
+		agaris = append(agaris, &Agari{Type: Ippan, Janto: janto, Mentsus: mentsus, Machi: Tanki})
+	}
+
+	seen := map[[3]*hai.Hai]bool{}
+	for _, m := range mentsus {
+		if seen[m] {
+			continue
+		}
+		seen[m] = true
+
+		var machi Machi
+		switch {
+		case m[0] == m[1]:
+			if m[0] != inHai {
+				continue
+			}
+			machi = Shanpon
+		case m[1] == inHai:
+			machi = Kanchan
+		case m[0] == inHai:
+			machi = Ryanmen
+			if m[2].HasAttribute(&attribute.Nine) {
+				machi = Penchan
+			}
+		case m[2] == inHai:
+			machi = Ryanmen
+			if m[0].HasAttribute(&attribute.One) {
+				machi = Penchan
+			}
+		default:
+			continue
+		}
+		agaris = append(agaris, &Agari{Type: Ippan, Janto: janto, Mentsus: mentsus, Machi: machi})
+	}
+	return agaris
+}
+
+func haiIndex(h *hai.Hai) (int, error) {
+	for i, x := range hai.All {
+		if x == h {
+			return i, nil
+		}
+	}
+	return -1, hai.HaiInvalidArgumentErr
+}
@@ -0,0 +1,218 @@
+package tehai
+
+import "sync"
+
+// Shanten counts the hais to be replaced before tenpai, 0 is tenpai and -1 is agari.
+// The mentsus called out are not in the tehai and are counted as completed.
+func (t *tehaiImpl) Shanten() (int, error) {
+	cnt := [34]int{}
+	for _, h := range t.hais {
+		idx, err := haiIndex(h)
+		if err != nil {
+			return 0, err
+		}
+		cnt[idx]++
+	}
+
+	// the number of mentsus the tehai has to make by itself
+	need := (MaxHaisLen - 1 - len(t.hais)) / 3
+	need = 4 - need
+	shanten := ippanShanten(&cnt, need)
+	if len(t.hais) < MaxHaisLen-1 {
+		return shanten, nil
+	}
+	if s := chiitoitsuShanten(&cnt); s < shanten {
+		shanten = s
+	}
+	if s := kokushiShanten(&cnt); s < shanten {
+		shanten = s
+	}
+	return shanten, nil
+}
+
+func ippanShanten(cnt *[34]int, need int) int {
+	best := blockShanten(cnt, need)
+	for i := range cnt {
+		if cnt[i] < 2 {
+			continue
+		}
+		cnt[i] -= 2
+		if s := blockShanten(cnt, need) - 1; s < best {
+			best = s
+		}
+		cnt[i] += 2
+	}
+	return best
+}
+
+// block is the number of mentsus and taatsus taken out of the hais.
+type block struct {
+	mentsu int
+	taatsu int
+}
+
+// blockShanten searches the best split into mentsus and taatsus without janto, suit by suit.
+func blockShanten(cnt *[34]int, need int) int {
+	// jihai can not make a shuntsu, the kotsu is always the best
+	mentsu, taatsu := 0, 0
+	for i := 27; i < len(cnt); i++ {
+		mentsu += cnt[i] / 3
+		if cnt[i]%3 == 2 {
+			taatsu++
+		}
+	}
+
+	best := 2 * need
+	var combine func(suit int, mentsu int, taatsu int)
+	combine = func(suit int, mentsu int, taatsu int) {
+		if suit == 3 {
+			if s := blocksShanten(need, mentsu, taatsu); s < best {
+				best = s
+			}
+			return
+		}
+		for _, b := range suitBlocks(cnt[suit*9 : suit*9+9]) {
+			combine(suit+1, mentsu+b.mentsu, taatsu+b.taatsu)
+		}
+	}
+	combine(0, mentsu, taatsu)
+	return best
+}
+
+func blocksShanten(need int, mentsu int, taatsu int) int {
+	if mentsu > need {
+		mentsu = need
+	}
+	if mentsu+taatsu > need {
+		taatsu = need - mentsu
+	}
+	return 2*(need-mentsu) - taatsu
+}
+
+var (
+	// the splits of a suit by the pattern of the counts, the same pattern appears again and again
+	suitBlocksCache   = map[int][]block{}
+	suitBlocksCacheMu sync.RWMutex
+)
+
+// suitBlocks returns the splits of the nine counts of a suit, only the ones no other split beats.
+func suitBlocks(cnt []int) []block {
+	key := 0
+	for _, c := range cnt {
+		key = key*5 + c
+	}
+	suitBlocksCacheMu.RLock()
+	blocks, ok := suitBlocksCache[key]
+	suitBlocksCacheMu.RUnlock()
+	if ok {
+		return blocks
+	}
+
+	c := [9]int{}
+	for i := range cnt {
+		c[i] = cnt[i]
+	}
+	found := map[block]bool{}
+	searchBlocks(&c, 0, block{}, found)
+	blocks = []block{}
+	for b := range found {
+		isBeaten := false
+		for o := range found {
+			if o != b && o.mentsu >= b.mentsu && o.taatsu >= b.taatsu {
+				isBeaten = true
+				break
+			}
+		}
+		if !isBeaten {
+			blocks = append(blocks, b)
+		}
+	}
+
+	suitBlocksCacheMu.Lock()
+	suitBlocksCache[key] = blocks
+	suitBlocksCacheMu.Unlock()
+	return blocks
+}
+
+func searchBlocks(cnt *[9]int, start int, b block, found map[block]bool) {
+	for start < len(cnt) && cnt[start] == 0 {
+		start++
+	}
+	if start == len(cnt) {
+		found[b] = true
+		return
+	}
+
+	// mentsu
+	if cnt[start] >= 3 {
+		cnt[start] -= 3
+		searchBlocks(cnt, start, block{b.mentsu + 1, b.taatsu}, found)
+		cnt[start] += 3
+	}
+	if start <= 6 && cnt[start+1] > 0 && cnt[start+2] > 0 {
+		cnt[start]--
+		cnt[start+1]--
+		cnt[start+2]--
+		searchBlocks(cnt, start, block{b.mentsu + 1, b.taatsu}, found)
+		cnt[start]++
+		cnt[start+1]++
+		cnt[start+2]++
+	}
+	// taatsu
+	if cnt[start] >= 2 {
+		cnt[start] -= 2
+		searchBlocks(cnt, start, block{b.mentsu, b.taatsu + 1}, found)
+		cnt[start] += 2
+	}
+	for _, o := range []int{1, 2} {
+		if start+o < len(cnt) && cnt[start+o] > 0 {
+			cnt[start]--
+			cnt[start+o]--
+			searchBlocks(cnt, start, block{b.mentsu, b.taatsu + 1}, found)
+			cnt[start]++
+			cnt[start+o]++
+		}
+	}
+	// isolated hai
+	cnt[start]--
+	searchBlocks(cnt, start, b, found)
+	cnt[start]++
+}
+
+func chiitoitsuShanten(cnt *[34]int) int {
+	toitsu := 0
+	kinds := 0
+	for _, c := range cnt {
+		if c == 0 {
+			continue
+		}
+		kinds++
+		if c >= 2 {
+			toitsu++
+		}
+	}
+	shanten := 6 - toitsu
+	if kinds < 7 {
+		// the same toitsu can not be counted twice
+		shanten += 7 - kinds
+	}
+	return shanten
+}
+
+func kokushiShanten(cnt *[34]int) int {
+	kinds := 0
+	hasToitsu := false
+	for _, h := range Yaochu {
+		idx, _ := haiIndex(h)
+		if cnt[idx] > 0 {
+			kinds++
+		}
+		if cnt[idx] >= 2 {
+			hasToitsu = true
+		}
+	}
+	if hasToitsu {
+		return 12 - kinds
+	}
+	return 13 - kinds
+}
@@ -24,6 +24,8 @@ type Tehai interface {
 	MinKanPairs(*hai.Hai) ([][3]*hai.Hai, error)
 	AnKanPairs(*hai.Hai) ([][4]*hai.Hai, error)
 	RiichiHais(*hai.Hai) ([]*hai.Hai, error)
+	Agaris(*hai.Hai) ([]*Agari, error)
+	Shanten() (int, error)
 
 	CanChii(*hai.Hai) (bool, error)
 	CanPon(*hai.Hai) (bool, error)
@@ -222,63 +224,8 @@ func (t *tehaiImpl) AnKanPairs(inHai *hai.Hai) ([][4]*hai.Hai, error) {
 }
 
 func (t *tehaiImpl) CanRon(inHai *hai.Hai) (bool, error) {
-	if inHai == nil {
-		return false, nil
-	}
-	tehai := tehaiImpl{append([]*hai.Hai{inHai}, t.hais...)}
-	tehai.Sort()
-
-	// count by hai
-	haisMap := map[*hai.Hai]int{}
-	for _, h := range tehai.hais {
-		haisMap[h]++
-	}
-	for k, v := range haisMap {
-		if v < 2 {
-			continue
-		}
-		// deep copy
-		tehai := tehaiImpl{append([]*hai.Hai{}, tehai.hais...)}
-		if _, err := tehai.Removes([]*hai.Hai{k, k}); err != nil {
-			return false, err
-		}
-
-		// kotsu
-		pairs := Kotsu(tehai.hais)
-		for i := 0; i < 1<<len(pairs); i++ {
-			// deep copy
-			tehai := tehaiImpl{append([]*hai.Hai{}, tehai.hais...)}
-			// remove
-			for j := 0; j < len(pairs); j++ {
-				if i>>j&1 == 1 {
-					if _, err := tehai.Removes(pairs[j]); err != nil {
-						return false, err
-					}
-				}
-			}
-
-			// shuntsu
-			pairs, err := Shuntsu(tehai.hais)
-			if err != nil {
-				return false, err
-			}
-			// remove
-			for j := 0; j < len(pairs); j++ {
-				if !(tehai.HasHai(pairs[j][0]) && tehai.HasHai(pairs[j][1]) && tehai.HasHai(pairs[j][2])) {
-					continue
-				}
-				if _, err := tehai.Removes(pairs[j]); err != nil {
-					return false, err
-				}
-			}
-
-			if len(tehai.hais) == 0 {
-				return true, nil
-			}
-		}
-	}
-
-	return false, nil
+	agaris, err := t.Agaris(inHai)
+	return len(agaris) != 0, err
 }
 
 func (t *tehaiImpl) CanRiichi(inHai *hai.Hai) (bool, error) {
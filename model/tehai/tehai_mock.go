@@ -11,6 +11,8 @@ type TehaiMock struct {
 	PonMock    [][2]*hai.Hai
 	MinKanMock [][3]*hai.Hai
 	AnKanMock  [][4]*hai.Hai
+	AgarisMock []*Agari
+	IntMock    int
 	BoolMock   bool
 	ErrorMock  error
 }
@@ -65,6 +67,14 @@ func (t *TehaiMock) RiichiHais(_ *hai.Hai) ([]*hai.Hai, error) {
 	return t.HaisMock, t.ErrorMock
 }
 
+func (t *TehaiMock) Agaris(_ *hai.Hai) ([]*Agari, error) {
+	return t.AgarisMock, t.ErrorMock
+}
+
+func (t *TehaiMock) Shanten() (int, error) {
+	return t.IntMock, t.ErrorMock
+}
+
 func (t *TehaiMock) CanChii(_ *hai.Hai) (bool, error) {
 	return t.BoolMock, t.ErrorMock
 }
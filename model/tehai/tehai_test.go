@@ -551,6 +551,45 @@ func TestMachihai(t *testing.T) {
 			},
 			outHais: []*hai.Hai{hai.Pinzu2, hai.Pinzu3, hai.Pinzu5, hai.Pinzu6},
 		},
+		{
+			name: "七対子",
+			beforeHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu1, hai.Manzu5, hai.Manzu5, hai.Pinzu2, hai.Pinzu2,
+				hai.Pinzu7, hai.Pinzu7, hai.Souzu3, hai.Souzu3, hai.Pei, hai.Pei,
+				hai.Chun,
+			},
+			outHais: []*hai.Hai{hai.Chun},
+		},
+		{
+			name: "七対子 with 4 same hais",
+			beforeHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu1, hai.Manzu5, hai.Manzu5, hai.Pinzu2, hai.Pinzu2,
+				hai.Pinzu7, hai.Pinzu7, hai.Souzu3, hai.Souzu3, hai.Chun, hai.Chun,
+				hai.Chun,
+			},
+			outHais: []*hai.Hai{},
+		},
+		{
+			name: "国士無双",
+			beforeHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu9, hai.Pinzu1, hai.Pinzu9, hai.Souzu1, hai.Souzu9,
+				hai.Ton, hai.Nan, hai.Sha, hai.Pei, hai.Haku, hai.Hatsu,
+				hai.Hatsu,
+			},
+			outHais: []*hai.Hai{hai.Chun},
+		},
+		{
+			name: "国士無双十三面",
+			beforeHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu9, hai.Pinzu1, hai.Pinzu9, hai.Souzu1, hai.Souzu9,
+				hai.Ton, hai.Nan, hai.Sha, hai.Pei, hai.Haku, hai.Hatsu,
+				hai.Chun,
+			},
+			outHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu9, hai.Pinzu1, hai.Pinzu9, hai.Souzu1, hai.Souzu9,
+				hai.Ton, hai.Nan, hai.Sha, hai.Pei, hai.Haku, hai.Hatsu, hai.Chun,
+			},
+		},
 	}
 
 	for _, c := range cases {
@@ -617,3 +656,236 @@ func TestCanRon(t *testing.T) {
 		})
 	}
 }
+
+func TestAgaris(t *testing.T) {
+	cases := []struct {
+		name     string
+		inHais   []*hai.Hai
+		inHai    *hai.Hai
+		outMachi []Machi
+	}{
+		{
+			name: "success: ryanmen",
+			inHais: []*hai.Hai{
+				hai.Manzu2, hai.Manzu3, hai.Manzu4, hai.Pinzu2, hai.Pinzu3, hai.Pinzu4,
+				hai.Souzu2, hai.Souzu3, hai.Souzu4, hai.Souzu6, hai.Souzu7, hai.Pinzu9,
+				hai.Pinzu9,
+			},
+			inHai:    hai.Souzu8,
+			outMachi: []Machi{Ryanmen},
+		},
+		{
+			name: "success: kanchan",
+			inHais: []*hai.Hai{
+				hai.Manzu2, hai.Manzu3, hai.Manzu4, hai.Pinzu2, hai.Pinzu3, hai.Pinzu4,
+				hai.Souzu2, hai.Souzu3, hai.Souzu4, hai.Souzu4, hai.Souzu6, hai.Pinzu9,
+				hai.Pinzu9,
+			},
+			inHai:    hai.Souzu5,
+			outMachi: []Machi{Kanchan},
+		},
+		{
+			name: "success: kotsu or shuntsu",
+			inHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu1, hai.Manzu1, hai.Manzu2, hai.Manzu2, hai.Manzu2,
+				hai.Manzu3, hai.Manzu3, hai.Manzu3, hai.Souzu5, hai.Souzu6, hai.Pinzu9,
+				hai.Pinzu9,
+			},
+			inHai:    hai.Souzu7,
+			outMachi: []Machi{Ryanmen, Ryanmen},
+		},
+		{
+			name: "success: tanki",
+			inHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu2, hai.Manzu3, hai.Pinzu4, hai.Pinzu5, hai.Pinzu6,
+				hai.Souzu7, hai.Souzu8, hai.Souzu9, hai.Haku, hai.Haku, hai.Haku,
+				hai.Hatsu,
+			},
+			inHai:    hai.Hatsu,
+			outMachi: []Machi{Tanki},
+		},
+		{
+			name: "success: penchan",
+			inHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu2, hai.Pinzu4, hai.Pinzu5, hai.Pinzu6, hai.Souzu1,
+				hai.Souzu1, hai.Souzu7, hai.Souzu8, hai.Souzu9, hai.Haku, hai.Haku,
+				hai.Haku,
+			},
+			inHai:    hai.Manzu3,
+			outMachi: []Machi{Penchan},
+		},
+		{
+			name: "success: shanpon",
+			inHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu2, hai.Manzu3, hai.Pinzu4, hai.Pinzu5, hai.Pinzu6,
+				hai.Souzu7, hai.Souzu8, hai.Souzu9, hai.Haku, hai.Haku, hai.Hatsu,
+				hai.Hatsu,
+			},
+			inHai:    hai.Haku,
+			outMachi: []Machi{Shanpon},
+		},
+		{
+			name: "success: ryanpeikou or chiitoitsu",
+			inHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu1, hai.Manzu2, hai.Manzu2, hai.Manzu3, hai.Manzu3,
+				hai.Pinzu4, hai.Pinzu4, hai.Pinzu5, hai.Pinzu5, hai.Pinzu6, hai.Pinzu6,
+				hai.Hatsu,
+			},
+			inHai:    hai.Hatsu,
+			outMachi: []Machi{Tanki, Tanki},
+		},
+		{
+			name: "success: kokushi juusanmen",
+			inHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu9, hai.Pinzu1, hai.Pinzu9, hai.Souzu1, hai.Souzu9,
+				hai.Ton, hai.Nan, hai.Sha, hai.Pei, hai.Haku, hai.Hatsu,
+				hai.Chun,
+			},
+			inHai:    hai.Ton,
+			outMachi: []Machi{Juusanmen},
+		},
+		{
+			name: "failure",
+			inHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu1, hai.Manzu1, hai.Manzu2, hai.Manzu2, hai.Manzu2,
+				hai.Manzu3, hai.Manzu3, hai.Manzu3, hai.Souzu5, hai.Souzu6, hai.Pinzu9,
+				hai.Pinzu9,
+			},
+			inHai:    hai.Souzu8,
+			outMachi: []Machi{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tehai := tehaiImpl{c.inHais}
+			agaris, err := tehai.Agaris(c.inHai)
+			assert.NoError(t, err)
+			machis := []Machi{}
+			for _, a := range agaris {
+				machis = append(machis, a.Machi)
+			}
+			assert.Equal(t, c.outMachi, machis)
+		})
+	}
+}
+
+func TestRiichiHais(t *testing.T) {
+	cases := []struct {
+		name       string
+		beforeHais []*hai.Hai
+		inHai      *hai.Hai
+		outHais    []*hai.Hai
+	}{
+		{
+			name: "success: chiitoitsu",
+			beforeHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu1, hai.Manzu5, hai.Manzu5, hai.Pinzu2, hai.Pinzu2,
+				hai.Pinzu7, hai.Pinzu7, hai.Souzu3, hai.Souzu3, hai.Pei, hai.Pei,
+				hai.Chun,
+			},
+			inHai:   hai.Haku,
+			outHais: []*hai.Hai{hai.Chun, hai.Haku},
+		},
+		{
+			name: "success: kokushi",
+			beforeHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu9, hai.Pinzu1, hai.Pinzu9, hai.Souzu1, hai.Souzu9,
+				hai.Ton, hai.Nan, hai.Sha, hai.Pei, hai.Haku, hai.Hatsu,
+				hai.Manzu5,
+			},
+			inHai:   hai.Hatsu,
+			outHais: []*hai.Hai{hai.Manzu5},
+		},
+		{
+			name: "failure",
+			beforeHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu9, hai.Pinzu1, hai.Pinzu9, hai.Souzu1, hai.Souzu9,
+				hai.Ton, hai.Nan, hai.Sha, hai.Pei, hai.Manzu4, hai.Manzu5,
+				hai.Manzu5,
+			},
+			inHai:   hai.Hatsu,
+			outHais: []*hai.Hai{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tehai := tehaiImpl{c.beforeHais}
+			hais, err := tehai.RiichiHais(c.inHai)
+			assert.NoError(t, err)
+			assert.Equal(t, c.outHais, hais)
+		})
+	}
+}
+
+func TestShanten(t *testing.T) {
+	cases := []struct {
+		name       string
+		beforeHais []*hai.Hai
+		outShanten int
+	}{
+		{
+			name: "agari",
+			beforeHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu2, hai.Manzu3, hai.Pinzu4, hai.Pinzu5, hai.Pinzu6,
+				hai.Souzu7, hai.Souzu8, hai.Souzu9, hai.Ton, hai.Ton, hai.Ton,
+				hai.Chun, hai.Chun,
+			},
+			outShanten: -1,
+		},
+		{
+			name: "tenpai",
+			beforeHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu2, hai.Manzu3, hai.Pinzu4, hai.Pinzu5, hai.Pinzu6,
+				hai.Souzu7, hai.Souzu8, hai.Souzu9, hai.Ton, hai.Ton, hai.Chun,
+				hai.Chun,
+			},
+			outShanten: 0,
+		},
+		{
+			name: "iishanten",
+			beforeHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu2, hai.Manzu3, hai.Pinzu4, hai.Pinzu5, hai.Pinzu6,
+				hai.Souzu7, hai.Souzu8, hai.Manzu5, hai.Manzu7, hai.Ton, hai.Chun,
+				hai.Chun,
+			},
+			outShanten: 1,
+		},
+		{
+			name: "with naki",
+			beforeHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu2, hai.Manzu3, hai.Souzu7, hai.Souzu8, hai.Haku,
+				hai.Chun,
+			},
+			outShanten: 1,
+		},
+		{
+			name: "chiitoitsu",
+			beforeHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu1, hai.Manzu5, hai.Manzu5, hai.Pinzu2, hai.Pinzu2,
+				hai.Pinzu7, hai.Pinzu7, hai.Souzu3, hai.Souzu3, hai.Pei, hai.Haku,
+				hai.Chun,
+			},
+			outShanten: 1,
+		},
+		{
+			name: "kokushi",
+			beforeHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu9, hai.Pinzu1, hai.Pinzu9, hai.Souzu1, hai.Souzu9,
+				hai.Ton, hai.Nan, hai.Sha, hai.Pei, hai.Haku, hai.Hatsu,
+				hai.Chun,
+			},
+			outShanten: 0,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tehai := tehaiImpl{c.beforeHais}
+			shanten, err := tehai.Shanten()
+			assert.NoError(t, err)
+			assert.Equal(t, c.outShanten, shanten)
+		})
+	}
+}
@@ -1,12 +1,20 @@
 package view
 
 import (
+	"encoding/hex"
+	"fmt"
 	"mahjong/model/board"
 	"mahjong/model/hai"
 	"mahjong/model/player"
+	"mahjong/model/yama"
+	"sort"
 	"strings"
 )
 
+var (
+	seatNames = []string{"you", "shimocha", "toimen", "kamicha"}
+)
+
 type boardViewHai struct {
 	*hai.Hai
 	isOpen   bool
@@ -138,6 +146,23 @@ func (p *boardViewPlayer) String() string {
 }
 
 func TehaiKamichaShimochaAndKawaAll(p player.Player, b board.Board) *boardViewBoard {
+	return tehaiKamichaShimochaAndKawaAll(p, b, false)
+}
+
+// tehaiSide is the tehai of kamicha or shimocha, the open hais lie down to show their names.
+func tehaiSide(p player.Player, isOpen bool) *boardViewPlayer {
+	v := NewBoardPlayer(p, isOpen)
+	if isOpen {
+		for _, h := range v.hais {
+			if h != nil {
+				h.isDown = true
+			}
+		}
+	}
+	return v
+}
+
+func tehaiKamichaShimochaAndKawaAll(p player.Player, b board.Board, isOpen bool) *boardViewBoard {
 	hais := [20][20]*boardViewHai{}
 	idx, _ := b.MyTurn(p)
 
@@ -148,13 +173,13 @@ func TehaiKamichaShimochaAndKawaAll(p player.Player, b board.Board) *boardViewBo
 	kamicha := players[(idx+3)%b.MaxNumberOfUser()]
 
 	// tehai
-	for i, h := range TehaiHide(kamicha).hais {
+	for i, h := range tehaiSide(kamicha, isOpen).hais {
 		if h == nil {
 			continue
 		}
 		hais[i][0] = h
 	}
-	for i, h := range TehaiHide(shimocha).hais {
+	for i, h := range tehaiSide(shimocha, isOpen).hais {
 		if h == nil {
 			continue
 		}
@@ -246,14 +271,181 @@ func (b *boardViewBoard) String() string {
 }
 
 func BoardString(p player.Player, b board.Board) (string, error) {
+	return boardString(p, b, false)
+}
+
+// OpenBoardString shows every tehai open, to replay a recorded match.
+func OpenBoardString(p player.Player, b board.Board) (string, error) {
+	return boardString(p, b, true)
+}
+
+func boardString(p player.Player, b board.Board, isOpen bool) (string, error) {
 	str := ""
 	idx, err := b.MyTurn(p)
 	if err != nil {
 		return str, err
 	}
+	str += MatchString(b)
+	seats, err := seatsString(idx, b)
+	if err != nil {
+		return str, err
+	}
+	str += seats
+	str += doraString("dora", b.Yama().OmoteDora())
+	if b.Yama().Commitment() != "" {
+		str += "commitment: " + b.Yama().Commitment() + "\n"
+	}
 	toimen := b.Players()[(idx+2)%b.MaxNumberOfUser()]
-	str += TehaiHide(toimen).Reverse().String()
-	str += TehaiKamichaShimochaAndKawaAll(p, b).String()
+	str += NewBoardPlayer(toimen, isOpen).Reverse().String()
+	str += tehaiKamichaShimochaAndKawaAll(p, b, isOpen).String()
 	str += TehaiOpen(p).String()
+	isFuriten, err := p.IsFuriten()
+	if err != nil {
+		return str, err
+	}
+	if isFuriten {
+		str += "furiten\n"
+	}
+	return str, nil
+}
+
+func ResultString(p player.Player, b board.Board) (string, error) {
+	result := b.Result()
+	if result == nil {
+		return "GAME SET!!\n", nil
+	}
+	if result.IsRyuukyoku {
+		return RyuukyokuString(p, b)
+	}
+	str := "GAME SET!!\n"
+	idx, err := b.MyTurn(p)
+	if err != nil {
+		return str, err
+	}
+
+	str += TehaiOpen(result.Winner).String()
+	if result.Loser == nil {
+		str += "tsumo\n"
+	} else {
+		str += "ron\n"
+	}
+	str += doraString("dora", b.Yama().OmoteDora())
+	if result.Winner.IsRiichi() {
+		str += doraString("ura dora", b.Yama().UraDora())
+	}
+	for _, y := range result.Score.Yakus {
+		str += fmt.Sprintf("%-16s %2d han\n", y.Name, y.Han)
+	}
+	str += fmt.Sprintf("%d fu %d han %s\n", result.Score.Fu, result.Score.Han, result.Score.Limit)
+	str += pointsString(idx, b)
+	str += revealString(b.Yama())
 	return str, nil
 }
+
+func RyuukyokuString(p player.Player, b board.Board) (string, error) {
+	str := "RYUUKYOKU!!\n"
+	result := b.Result()
+	idx, err := b.MyTurn(p)
+	if err != nil {
+		return str, err
+	}
+
+	for i := range b.Players() {
+		turnIdx := (idx + i) % len(b.Players())
+		if !result.Tenpais[turnIdx] {
+			str += fmt.Sprintf("%-8s noten\n", seatNames[i])
+			continue
+		}
+		str += fmt.Sprintf("%-8s tenpai\n", seatNames[i])
+		str += TehaiOpen(b.Players()[turnIdx]).String()
+	}
+	str += pointsString(idx, b)
+	str += revealString(b.Yama())
+	return str, nil
+}
+
+func pointsString(idx int, b board.Board) string {
+	str := ""
+	result := b.Result()
+	for i := range b.Players() {
+		tp := b.Players()[(idx+i)%len(b.Players())]
+		str += fmt.Sprintf("%-8s %+6d %6d\n", seatNames[i], result.Points[(idx+i)%len(b.Players())], tp.Point())
+	}
+	return str
+}
+
+func MatchString(b board.Board) string {
+	m := b.Match()
+	return fmt.Sprintf("%s %d kyoku %d honba %d kyoutaku\n", m.Bakaze().Name(), m.Kyoku(), m.Honba(), m.Kyoutaku())
+}
+
+// SeatName is the name of the seat of the other player seen from the player.
+func SeatName(p player.Player, other player.Player, b board.Board) (string, error) {
+	idx, err := b.MyTurn(p)
+	if err != nil {
+		return "", err
+	}
+	otherIdx, err := b.MyTurn(other)
+	if err != nil {
+		return "", err
+	}
+	return seatNames[(otherIdx-idx+len(b.Players()))%len(b.Players())], nil
+}
+
+func seatsString(idx int, b board.Board) (string, error) {
+	strs := []string{}
+	for i := range b.Players() {
+		tp := b.Players()[(idx+i)%len(b.Players())]
+		jikaze, err := b.Jikaze(tp.Player)
+		if err != nil {
+			return "", err
+		}
+		strs = append(strs, fmt.Sprintf("%s %s %d", seatLabel(i, tp.Player), jikaze.Name(), tp.Point()))
+	}
+	return strings.Join(strs, " | ") + "\n", nil
+}
+
+// seatLabel is the name of the seat with the nickname of the player.
+func seatLabel(seat int, p player.Player) string {
+	if p.Name() == "" {
+		return seatNames[seat]
+	}
+	return seatNames[seat] + " " + p.Name()
+}
+
+func MatchResultString(p player.Player, b board.Board) string {
+	str := "MATCH END!!\n"
+	idx, _ := b.MyTurn(p)
+	seats := []int{}
+	for i := range b.Players() {
+		seats = append(seats, i)
+	}
+	sort.SliceStable(seats, func(i, j int) bool {
+		return b.Players()[(idx+seats[i])%len(seats)].Point() > b.Players()[(idx+seats[j])%len(seats)].Point()
+	})
+	for rank, seat := range seats {
+		tp := b.Players()[(idx+seat)%len(seats)]
+		str += fmt.Sprintf("%d %-8s %6d\n", rank+1, seatLabel(seat, tp.Player), tp.Point())
+	}
+	// revealed only at the end, the seed tells every wall
+	str += fmt.Sprintf("seed: %d\n", b.Seed())
+	return str
+}
+
+// revealString shows the wall and the salt to check the commitment of the hand.
+// a replayed wall has nothing to check.
+func revealString(y yama.Yama) string {
+	if y.Commitment() == "" {
+		return ""
+	}
+	wall, salt := y.Reveal()
+	return "salt: " + hex.EncodeToString(salt) + "\nwall: " + yama.WallString(wall) + "\n"
+}
+
+func doraString(name string, indicators []*hai.Hai) string {
+	names := []string{}
+	for _, h := range indicators {
+		names = append(names, h.Name())
+	}
+	return name + ": " + strings.Join(names, " ") + "\n"
+}
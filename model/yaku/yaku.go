@@ -0,0 +1,725 @@
+package yaku
+
+import (
+	"mahjong/model/hai"
+	"mahjong/model/hai/attribute"
+	"mahjong/model/naki"
+	"mahjong/model/tehai"
+)
+
+type Yaku struct {
+	Name    string
+	Han     int
+	Yakuman bool
+}
+
+// Situation is the context of the agari which is not visible from the hais.
+type Situation struct {
+	IsTsumo  bool
+	IsRiichi bool
+	Bakaze   *hai.Hai
+	Jikaze   *hai.Hai
+	// dora indicators revealed by the yama, ura dora counts only for riichi
+	OmoteDora []*hai.Hai
+	UraDora   []*hai.Hai
+	// the ron robbing the kakan of the other
+	IsChankan bool
+	// the tsumo of the rinshan hai after the kan
+	IsRinshan bool
+	// the tsumo of the last hai of the live wall, and the ron on the discard after it
+	IsHaitei bool
+	IsHoutei bool
+	// the riichi declared on the first go-around, IsRiichi is set too
+	IsDoubleRiichi bool
+	// the agari before the next dahai of the riichi without any call
+	IsIppatsu bool
+	// the tsumo on the first draw without any call, by the oya and by the ko
+	IsTenhou  bool
+	IsChiihou bool
+}
+
+var (
+	YakumanHan = 13
+)
+
+type definition struct {
+	name    string
+	han     int
+	nakiHan int // 0 means menzen only
+	yakuman bool
+	check   func(*hand) bool
+}
+
+var definitions = []definition{
+	// yakuman
+	{name: "kokushi musou", yakuman: true, check: isKokushi},
+	{name: "suuankou", yakuman: true, check: isSuuankou},
+	{name: "daisangen", yakuman: true, check: isDaisangen},
+	{name: "shousuushii", yakuman: true, check: isShousuushii},
+	{name: "daisuushii", yakuman: true, check: isDaisuushii},
+	{name: "tsuuiisou", yakuman: true, check: isTsuuiisou},
+	{name: "ryuuiisou", yakuman: true, check: isRyuuiisou},
+	{name: "chinroutou", yakuman: true, check: isChinroutou},
+	{name: "chuuren poutou", yakuman: true, check: isChuurenPoutou},
+	{name: "suukantsu", yakuman: true, check: isSuukantsu},
+	{name: "tenhou", yakuman: true, check: isTenhou},
+	{name: "chiihou", yakuman: true, check: isChiihou},
+
+	// 1 han
+	{name: "riichi", han: 1, check: isRiichi},
+	{name: "ippatsu", han: 1, check: isIppatsu},
+	{name: "menzen tsumo", han: 1, check: isMenzenTsumo},
+	{name: "pinfu", han: 1, check: isPinfu},
+	{name: "tanyao", han: 1, nakiHan: 1, check: isTanyao},
+	{name: "iipeikou", han: 1, check: isIipeikou},
+	{name: "yakuhai haku", han: 1, nakiHan: 1, check: isYakuhai(hai.Haku)},
+	{name: "yakuhai hatsu", han: 1, nakiHan: 1, check: isYakuhai(hai.Hatsu)},
+	{name: "yakuhai chun", han: 1, nakiHan: 1, check: isYakuhai(hai.Chun)},
+	{name: "bakaze", han: 1, nakiHan: 1, check: isBakaze},
+	{name: "jikaze", han: 1, nakiHan: 1, check: isJikaze},
+	{name: "chankan", han: 1, nakiHan: 1, check: isChankan},
+	{name: "rinshan kaihou", han: 1, nakiHan: 1, check: isRinshan},
+	{name: "haitei raoyue", han: 1, nakiHan: 1, check: isHaitei},
+	{name: "houtei raoyui", han: 1, nakiHan: 1, check: isHoutei},
+
+	// 2 han
+	{name: "double riichi", han: 2, check: isDoubleRiichi},
+	{name: "chiitoitsu", han: 2, check: isChiitoitsu},
+	{name: "sanshoku doujun", han: 2, nakiHan: 1, check: isSanshokuDoujun},
+	{name: "ittsu", han: 2, nakiHan: 1, check: isIttsu},
+	{name: "chanta", han: 2, nakiHan: 1, check: isChanta},
+	{name: "toitoi", han: 2, nakiHan: 2, check: isToitoi},
+	{name: "sanankou", han: 2, nakiHan: 2, check: isSanankou},
+	{name: "sanshoku doukou", han: 2, nakiHan: 2, check: isSanshokuDoukou},
+	{name: "sankantsu", han: 2, nakiHan: 2, check: isSankantsu},
+	{name: "honroutou", han: 2, nakiHan: 2, check: isHonroutou},
+	{name: "shousangen", han: 2, nakiHan: 2, check: isShousangen},
+
+	// 3 han and more
+	{name: "honitsu", han: 3, nakiHan: 2, check: isHonitsu},
+	{name: "junchan", han: 3, nakiHan: 2, check: isJunchan},
+	{name: "ryanpeikou", han: 3, check: isRyanpeikou},
+	{name: "chinitsu", han: 6, nakiHan: 5, check: isChinitsu},
+}
+
+// Evaluate returns every yaku of the agari. only yakuman are returned when the hand has any.
+func Evaluate(a *tehai.Agari, n naki.Naki, agariHai *hai.Hai, s *Situation) ([]*Yaku, error) {
+	h, err := newHand(a, n, agariHai, s)
+	if err != nil {
+		return []*Yaku{}, err
+	}
+
+	yakus := []*Yaku{}
+	yakumans := []*Yaku{}
+	for _, d := range definitions {
+		han := d.han
+		if !h.isMenzen {
+			han = d.nakiHan
+		}
+		if !d.yakuman && han == 0 {
+			continue
+		}
+		if !d.check(h) {
+			continue
+		}
+		if d.yakuman {
+			yakumans = append(yakumans, &Yaku{Name: d.name, Han: YakumanHan, Yakuman: true})
+			continue
+		}
+		yakus = append(yakus, &Yaku{Name: d.name, Han: han})
+	}
+
+	if len(yakumans) != 0 {
+		return yakumans, nil
+	}
+	// dora is not a yaku, it only adds han to the agari having any
+	if len(yakus) == 0 {
+		return yakus, nil
+	}
+	dora, err := h.countDora(s.OmoteDora)
+	if err != nil {
+		return []*Yaku{}, err
+	}
+	if dora != 0 {
+		yakus = append(yakus, &Yaku{Name: "dora", Han: dora})
+	}
+	if s.IsRiichi {
+		uraDora, err := h.countDora(s.UraDora)
+		if err != nil {
+			return []*Yaku{}, err
+		}
+		if uraDora != 0 {
+			yakus = append(yakus, &Yaku{Name: "ura dora", Han: uraDora})
+		}
+	}
+	return yakus, nil
+}
+
+// Best evaluates every agari and returns the yakus of the one having the most han.
+func Best(agaris []*tehai.Agari, n naki.Naki, agariHai *hai.Hai, s *Situation) (*tehai.Agari, []*Yaku, error) {
+	var bestAgari *tehai.Agari
+	bestYakus := []*Yaku{}
+	for _, a := range agaris {
+		yakus, err := Evaluate(a, n, agariHai, s)
+		if err != nil {
+			return nil, []*Yaku{}, err
+		}
+		if bestAgari == nil || Han(yakus) > Han(bestYakus) {
+			bestAgari = a
+			bestYakus = yakus
+		}
+	}
+	return bestAgari, bestYakus, nil
+}
+
+func Han(yakus []*Yaku) int {
+	han := 0
+	for _, y := range yakus {
+		han += y.Han
+	}
+	return han
+}
+
+type mentsu struct {
+	hais        []*hai.Hai
+	isShuntsu   bool
+	isKantsu    bool
+	isConcealed bool
+}
+
+type hand struct {
+	agariType tehai.AgariType
+	janto     *hai.Hai
+	mentsus   []*mentsu
+	hais      []*hai.Hai
+	machi     tehai.Machi
+	isMenzen  bool
+	situation *Situation
+}
+
+func newHand(a *tehai.Agari, n naki.Naki, agariHai *hai.Hai, s *Situation) (*hand, error) {
+	if a == nil || n == nil || agariHai == nil || s == nil {
+		return nil, YakuInvalidArgumentErr
+	}
+	h := &hand{
+		agariType: a.Type,
+		janto:     a.Janto,
+		mentsus:   []*mentsu{},
+		hais:      []*hai.Hai{},
+		machi:     a.Machi,
+		isMenzen:  len(n.Chiis())+len(n.Pons())+len(n.MinKans()) == 0,
+		situation: s,
+	}
+
+	switch a.Type {
+	case tehai.Chiitoitsu:
+		// chiitoitsu has no janto nor mentsu
+		for _, t := range a.Toitsus {
+			h.hais = append(h.hais, t, t)
+		}
+		return h, nil
+	case tehai.Kokushi:
+		h.hais = append(h.hais, tehai.Yaochu...)
+		h.hais = append(h.hais, a.Janto)
+		return h, nil
+	}
+
+	h.hais = append(h.hais, a.Janto, a.Janto)
+	ronKotsu := !s.IsTsumo && a.Machi == tehai.Shanpon
+	for _, m := range a.Mentsus {
+		isShuntsu := m[0] != m[1]
+		isConcealed := true
+		if ronKotsu && !isShuntsu && m[0] == agariHai {
+			// the kotsu completed by ron is treated as an open one
+			isConcealed = false
+			ronKotsu = false
+		}
+		h.add(&mentsu{hais: append([]*hai.Hai{}, m[:]...), isShuntsu: isShuntsu, isConcealed: isConcealed})
+	}
+	for _, m := range n.Chiis() {
+		h.add(&mentsu{hais: append([]*hai.Hai{}, m[:]...), isShuntsu: true})
+	}
+	for _, m := range n.Pons() {
+		h.add(&mentsu{hais: append([]*hai.Hai{}, m[:]...)})
+	}
+	for _, m := range n.MinKans() {
+		h.add(&mentsu{hais: append([]*hai.Hai{}, m[:]...), isKantsu: true})
+	}
+	for _, m := range n.AnKans() {
+		h.add(&mentsu{hais: append([]*hai.Hai{}, m[:]...), isKantsu: true, isConcealed: true})
+	}
+
+	for _, m := range h.mentsus {
+		if !m.isShuntsu {
+			continue
+		}
+		// keep shuntsu sorted by number, chii hais are stored in the called order
+		hais, err := sortShuntsu(m.hais)
+		if err != nil {
+			return nil, err
+		}
+		m.hais = hais
+	}
+	return h, nil
+}
+
+func (h *hand) countDora(indicators []*hai.Hai) (int, error) {
+	cnt := 0
+	for _, indicator := range indicators {
+		dora, err := hai.Dora(indicator)
+		if err != nil {
+			return 0, err
+		}
+		for _, x := range h.hais {
+			if x == dora {
+				cnt++
+			}
+		}
+	}
+	return cnt, nil
+}
+
+func (h *hand) add(m *mentsu) {
+	h.mentsus = append(h.mentsus, m)
+	h.hais = append(h.hais, m.hais...)
+}
+
+func (h *hand) shuntsus() []*mentsu {
+	out := []*mentsu{}
+	for _, m := range h.mentsus {
+		if m.isShuntsu {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func (h *hand) kotsus() []*mentsu {
+	out := []*mentsu{}
+	for _, m := range h.mentsus {
+		if !m.isShuntsu {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func (h *hand) ankous() int {
+	cnt := 0
+	for _, m := range h.kotsus() {
+		if m.isConcealed {
+			cnt++
+		}
+	}
+	return cnt
+}
+
+func (h *hand) kantsus() int {
+	cnt := 0
+	for _, m := range h.mentsus {
+		if m.isKantsu {
+			cnt++
+		}
+	}
+	return cnt
+}
+
+func (h *hand) all(f func(*hai.Hai) bool) bool {
+	for _, x := range h.hais {
+		if !f(x) {
+			return false
+		}
+	}
+	return true
+}
+
+func (h *hand) any(f func(*hai.Hai) bool) bool {
+	for _, x := range h.hais {
+		if f(x) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortShuntsu(hais []*hai.Hai) ([]*hai.Hai, error) {
+	out := append([]*hai.Hai{}, hais...)
+	for i := 0; i < len(out); i++ {
+		for j := i + 1; j < len(out); j++ {
+			a, err := hai.HaitoI(out[i])
+			if err != nil {
+				return out, err
+			}
+			b, err := hai.HaitoI(out[j])
+			if err != nil {
+				return out, err
+			}
+			if b < a {
+				out[i], out[j] = out[j], out[i]
+			}
+		}
+	}
+	return out, nil
+}
+
+func isJihai(h *hai.Hai) bool {
+	return h.HasAttribute(&attribute.Jihai)
+}
+
+func isRoutou(h *hai.Hai) bool {
+	return h.HasAttribute(&attribute.Suhai) && (h.HasAttribute(&attribute.One) || h.HasAttribute(&attribute.Nine))
+}
+
+func isYaochu(h *hai.Hai) bool {
+	return isJihai(h) || isRoutou(h)
+}
+
+func isChunchan(h *hai.Hai) bool {
+	return !isYaochu(h)
+}
+
+func suit(h *hai.Hai) *attribute.Attribute {
+	for _, s := range attribute.Suits {
+		if h.HasAttribute(s) {
+			return s
+		}
+	}
+	return nil
+}
+
+func (h *hand) isYakuhaiHai(x *hai.Hai) bool {
+	return x.HasAttribute(&attribute.Sangen) || x == h.situation.Bakaze || x == h.situation.Jikaze
+}
+
+// yakuman
+
+func isKokushi(h *hand) bool {
+	return h.agariType == tehai.Kokushi
+}
+
+func isSuuankou(h *hand) bool {
+	return h.ankous() == 4
+}
+
+func isDaisangen(h *hand) bool {
+	cnt := 0
+	for _, m := range h.kotsus() {
+		if m.hais[0].HasAttribute(&attribute.Sangen) {
+			cnt++
+		}
+	}
+	return cnt == 3
+}
+
+func isShousuushii(h *hand) bool {
+	cnt := 0
+	for _, m := range h.kotsus() {
+		if m.hais[0].HasAttribute(&attribute.Kaze) {
+			cnt++
+		}
+	}
+	return cnt == 3 && h.janto.HasAttribute(&attribute.Kaze)
+}
+
+func isDaisuushii(h *hand) bool {
+	cnt := 0
+	for _, m := range h.kotsus() {
+		if m.hais[0].HasAttribute(&attribute.Kaze) {
+			cnt++
+		}
+	}
+	return cnt == 4
+}
+
+func isTsuuiisou(h *hand) bool {
+	return h.all(isJihai)
+}
+
+func isRyuuiisou(h *hand) bool {
+	return h.all(func(x *hai.Hai) bool {
+		for _, g := range []*hai.Hai{hai.Souzu2, hai.Souzu3, hai.Souzu4, hai.Souzu6, hai.Souzu8, hai.Hatsu} {
+			if x == g {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func isChinroutou(h *hand) bool {
+	return h.all(isRoutou)
+}
+
+func isChuurenPoutou(h *hand) bool {
+	if !h.isMenzen || h.kantsus() != 0 || !isChinitsu(h) {
+		return false
+	}
+	cnt := [10]int{}
+	for _, x := range h.hais {
+		num, err := hai.HaitoI(x)
+		if err != nil {
+			return false
+		}
+		cnt[num]++
+	}
+	need := [10]int{0, 3, 1, 1, 1, 1, 1, 1, 1, 3}
+	for i := 1; i <= 9; i++ {
+		if cnt[i] < need[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func isSuukantsu(h *hand) bool {
+	return h.kantsus() == 4
+}
+
+func isTenhou(h *hand) bool {
+	return h.situation.IsTenhou
+}
+
+func isChiihou(h *hand) bool {
+	return h.situation.IsChiihou
+}
+
+// 1 han
+
+// isRiichi is not counted with the double riichi
+func isRiichi(h *hand) bool {
+	return h.situation.IsRiichi && !h.situation.IsDoubleRiichi
+}
+
+func isIppatsu(h *hand) bool {
+	return h.situation.IsRiichi && h.situation.IsIppatsu
+}
+
+func isMenzenTsumo(h *hand) bool {
+	return h.situation.IsTsumo
+}
+
+func isChankan(h *hand) bool {
+	return h.situation.IsChankan
+}
+
+func isRinshan(h *hand) bool {
+	return h.situation.IsRinshan
+}
+
+func isHaitei(h *hand) bool {
+	return h.situation.IsHaitei
+}
+
+func isHoutei(h *hand) bool {
+	return h.situation.IsHoutei
+}
+
+func isPinfu(h *hand) bool {
+	return len(h.shuntsus()) == 4 && !h.isYakuhaiHai(h.janto) && h.machi == tehai.Ryanmen
+}
+
+func isTanyao(h *hand) bool {
+	return h.all(isChunchan)
+}
+
+func peikous(h *hand) int {
+	cnt := 0
+	used := map[int]bool{}
+	shuntsus := h.shuntsus()
+	for i := range shuntsus {
+		for j := i + 1; j < len(shuntsus); j++ {
+			if used[i] || used[j] {
+				continue
+			}
+			if shuntsus[i].hais[0] == shuntsus[j].hais[0] {
+				used[i], used[j] = true, true
+				cnt++
+			}
+		}
+	}
+	return cnt
+}
+
+func isIipeikou(h *hand) bool {
+	return peikous(h) == 1
+}
+
+func isYakuhai(target *hai.Hai) func(*hand) bool {
+	return func(h *hand) bool {
+		for _, m := range h.kotsus() {
+			if m.hais[0] == target {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func isBakaze(h *hand) bool {
+	return h.situation.Bakaze != nil && isYakuhai(h.situation.Bakaze)(h)
+}
+
+func isJikaze(h *hand) bool {
+	return h.situation.Jikaze != nil && isYakuhai(h.situation.Jikaze)(h)
+}
+
+// 2 han
+
+func isDoubleRiichi(h *hand) bool {
+	return h.situation.IsRiichi && h.situation.IsDoubleRiichi
+}
+
+func isChiitoitsu(h *hand) bool {
+	return h.agariType == tehai.Chiitoitsu
+}
+
+func isSanshokuDoujun(h *hand) bool {
+	shuntsus := h.shuntsus()
+	for _, a := range shuntsus {
+		num, err := hai.HaitoI(a.hais[0])
+		if err != nil {
+			return false
+		}
+		suits := map[*attribute.Attribute]bool{}
+		for _, b := range shuntsus {
+			n, err := hai.HaitoI(b.hais[0])
+			if err != nil {
+				return false
+			}
+			if n == num {
+				suits[suit(b.hais[0])] = true
+			}
+		}
+		if len(suits) == 3 {
+			return true
+		}
+	}
+	return false
+}
+
+func isIttsu(h *hand) bool {
+	for _, s := range attribute.Suits {
+		found := map[int]bool{}
+		for _, m := range h.shuntsus() {
+			if suit(m.hais[0]) != s {
+				continue
+			}
+			num, err := hai.HaitoI(m.hais[0])
+			if err != nil {
+				return false
+			}
+			found[num] = true
+		}
+		if found[1] && found[4] && found[7] {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *hand) everySet(f func(*hai.Hai) bool) bool {
+	if !f(h.janto) {
+		return false
+	}
+outer:
+	for _, m := range h.mentsus {
+		for _, x := range m.hais {
+			if f(x) {
+				continue outer
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func isChanta(h *hand) bool {
+	return len(h.shuntsus()) != 0 && h.any(isJihai) && h.everySet(isYaochu)
+}
+
+func isToitoi(h *hand) bool {
+	return len(h.kotsus()) == 4
+}
+
+func isSanankou(h *hand) bool {
+	return h.ankous() == 3
+}
+
+func isSanshokuDoukou(h *hand) bool {
+	kotsus := h.kotsus()
+	for _, a := range kotsus {
+		if isJihai(a.hais[0]) {
+			continue
+		}
+		num, err := hai.HaitoI(a.hais[0])
+		if err != nil {
+			return false
+		}
+		suits := map[*attribute.Attribute]bool{}
+		for _, b := range kotsus {
+			if isJihai(b.hais[0]) {
+				continue
+			}
+			n, err := hai.HaitoI(b.hais[0])
+			if err != nil {
+				return false
+			}
+			if n == num {
+				suits[suit(b.hais[0])] = true
+			}
+		}
+		if len(suits) == 3 {
+			return true
+		}
+	}
+	return false
+}
+
+func isSankantsu(h *hand) bool {
+	return h.kantsus() == 3
+}
+
+func isHonroutou(h *hand) bool {
+	return h.all(isYaochu) && h.any(isJihai) && h.any(isRoutou)
+}
+
+func isShousangen(h *hand) bool {
+	cnt := 0
+	for _, m := range h.kotsus() {
+		if m.hais[0].HasAttribute(&attribute.Sangen) {
+			cnt++
+		}
+	}
+	return cnt == 2 && h.janto.HasAttribute(&attribute.Sangen)
+}
+
+// 3 han and more
+
+func isHonitsu(h *hand) bool {
+	suits := map[*attribute.Attribute]bool{}
+	for _, x := range h.hais {
+		if !isJihai(x) {
+			suits[suit(x)] = true
+		}
+	}
+	return len(suits) == 1 && h.any(isJihai)
+}
+
+func isJunchan(h *hand) bool {
+	return len(h.shuntsus()) != 0 && !h.any(isJihai) && h.everySet(isRoutou)
+}
+
+func isRyanpeikou(h *hand) bool {
+	return peikous(h) == 2
+}
+
+func isChinitsu(h *hand) bool {
+	suits := map[*attribute.Attribute]bool{}
+	for _, x := range h.hais {
+		if isJihai(x) {
+			return false
+		}
+		suits[suit(x)] = true
+	}
+	return len(suits) == 1
+}
@@ -0,0 +1,7 @@
+package yaku
+
+import "errors"
+
+var (
+	YakuInvalidArgumentErr = errors.New("invalid argument")
+)
@@ -0,0 +1,306 @@
+package yaku
+
+import (
+	"mahjong/model/hai"
+	"mahjong/model/naki"
+	"mahjong/model/tehai"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func names(yakus []*Yaku) []string {
+	out := []string{}
+	for _, y := range yakus {
+		out = append(out, y.Name)
+	}
+	return out
+}
+
+func TestEvaluate(t *testing.T) {
+	cases := []struct {
+		name        string
+		inHais      []*hai.Hai
+		inHai       *hai.Hai
+		inNaki      naki.Naki
+		inSituation *Situation
+		outNames    []string
+	}{
+		{
+			name: "pinfu tanyao",
+			inHais: []*hai.Hai{
+				hai.Manzu2, hai.Manzu3, hai.Manzu4, hai.Pinzu2, hai.Pinzu3, hai.Pinzu4,
+				hai.Souzu2, hai.Souzu3, hai.Souzu4, hai.Souzu6, hai.Souzu7, hai.Pinzu8,
+				hai.Pinzu8,
+			},
+			inHai:       hai.Souzu5,
+			inNaki:      &naki.NakiMock{},
+			inSituation: &Situation{},
+			outNames:    []string{"pinfu", "tanyao", "sanshoku doujun"},
+		},
+		{
+			name: "riichi tsumo pinfu iipeikou",
+			inHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu2, hai.Manzu3, hai.Manzu1, hai.Manzu2, hai.Manzu3,
+				hai.Souzu2, hai.Souzu3, hai.Souzu4, hai.Pei, hai.Pei, hai.Pinzu7,
+				hai.Pinzu8,
+			},
+			inHai:       hai.Pinzu9,
+			inNaki:      &naki.NakiMock{},
+			inSituation: &Situation{IsTsumo: true, IsRiichi: true},
+			outNames:    []string{"riichi", "menzen tsumo", "pinfu", "iipeikou"},
+		},
+		{
+			name: "yakuhai double ton",
+			inHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu2, hai.Manzu3, hai.Souzu2, hai.Souzu3, hai.Souzu4,
+				hai.Pinzu7, hai.Pinzu7,
+				hai.Ton, hai.Ton,
+			},
+			inHai:       hai.Ton,
+			inNaki:      &naki.NakiMock{PonsMock: [][3]*hai.Hai{{hai.Pinzu1, hai.Pinzu1, hai.Pinzu1}}},
+			inSituation: &Situation{Bakaze: hai.Ton, Jikaze: hai.Ton},
+			outNames:    []string{"bakaze", "jikaze"},
+		},
+		{
+			name: "honitsu toitoi",
+			inHais: []*hai.Hai{
+				hai.Pinzu1, hai.Pinzu1, hai.Pinzu1, hai.Pinzu5, hai.Pinzu5,
+				hai.Pei, hai.Pei,
+			},
+			inHai: hai.Pei,
+			inNaki: &naki.NakiMock{PonsMock: [][3]*hai.Hai{
+				{hai.Pinzu9, hai.Pinzu9, hai.Pinzu9}, {hai.Pinzu2, hai.Pinzu2, hai.Pinzu2},
+			}},
+			inSituation: &Situation{},
+			outNames:    []string{"toitoi", "honitsu"},
+		},
+		{
+			name: "chinitsu ittsu",
+			inHais: []*hai.Hai{
+				hai.Souzu1, hai.Souzu2, hai.Souzu3, hai.Souzu4, hai.Souzu5, hai.Souzu6,
+				hai.Souzu7, hai.Souzu8, hai.Souzu9, hai.Souzu2,
+			},
+			inHai:       hai.Souzu2,
+			inNaki:      &naki.NakiMock{ChiisMock: [][3]*hai.Hai{{hai.Souzu6, hai.Souzu4, hai.Souzu5}}},
+			inSituation: &Situation{},
+			outNames:    []string{"ittsu", "chinitsu"},
+		},
+		{
+			name: "chanta shousangen",
+			inHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu2, hai.Manzu3, hai.Haku, hai.Haku, hai.Haku,
+				hai.Hatsu, hai.Hatsu, hai.Hatsu, hai.Chun, hai.Souzu7, hai.Souzu8,
+				hai.Souzu9,
+			},
+			inHai:       hai.Chun,
+			inNaki:      &naki.NakiMock{},
+			inSituation: &Situation{},
+			outNames:    []string{"yakuhai haku", "yakuhai hatsu", "chanta", "shousangen"},
+		},
+		{
+			name: "suuankou",
+			inHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu1, hai.Manzu1, hai.Pinzu4, hai.Pinzu4, hai.Pinzu4,
+				hai.Souzu7, hai.Souzu7, hai.Souzu7, hai.Sha, hai.Sha, hai.Nan,
+				hai.Nan,
+			},
+			inHai:       hai.Nan,
+			inNaki:      &naki.NakiMock{},
+			inSituation: &Situation{IsTsumo: true},
+			outNames:    []string{"suuankou"},
+		},
+		{
+			name: "no suuankou by ron",
+			inHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu1, hai.Manzu1, hai.Pinzu4, hai.Pinzu4, hai.Pinzu4,
+				hai.Souzu7, hai.Souzu7, hai.Souzu7, hai.Sha, hai.Sha, hai.Nan,
+				hai.Nan,
+			},
+			inHai:       hai.Nan,
+			inNaki:      &naki.NakiMock{},
+			inSituation: &Situation{},
+			outNames:    []string{"toitoi", "sanankou"},
+		},
+		{
+			name: "daisangen",
+			inHais: []*hai.Hai{
+				hai.Haku, hai.Haku, hai.Haku, hai.Chun, hai.Chun, hai.Chun,
+				hai.Manzu5, hai.Manzu5,
+				hai.Manzu6, hai.Manzu7,
+			},
+			inHai:       hai.Manzu8,
+			inNaki:      &naki.NakiMock{PonsMock: [][3]*hai.Hai{{hai.Hatsu, hai.Hatsu, hai.Hatsu}}},
+			inSituation: &Situation{},
+			outNames:    []string{"daisangen"},
+		},
+		{
+			name: "chiitoitsu tanyao",
+			inHais: []*hai.Hai{
+				hai.Manzu2, hai.Manzu2, hai.Manzu5, hai.Manzu5, hai.Pinzu2, hai.Pinzu2,
+				hai.Pinzu7, hai.Pinzu7, hai.Souzu3, hai.Souzu3, hai.Souzu4, hai.Souzu4,
+				hai.Souzu8,
+			},
+			inHai:       hai.Souzu8,
+			inNaki:      &naki.NakiMock{},
+			inSituation: &Situation{},
+			outNames:    []string{"tanyao", "chiitoitsu"},
+		},
+		{
+			name: "kokushi musou",
+			inHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu9, hai.Pinzu1, hai.Pinzu9, hai.Souzu1, hai.Souzu9,
+				hai.Ton, hai.Nan, hai.Sha, hai.Pei, hai.Haku, hai.Hatsu,
+				hai.Hatsu,
+			},
+			inHai:       hai.Chun,
+			inNaki:      &naki.NakiMock{},
+			inSituation: &Situation{},
+			outNames:    []string{"kokushi musou"},
+		},
+		{
+			name: "riichi dora ura dora",
+			inHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu1, hai.Manzu1, hai.Pinzu4, hai.Pinzu5, hai.Pinzu6,
+				hai.Souzu2, hai.Souzu3, hai.Souzu4, hai.Pei, hai.Pei, hai.Pinzu7,
+				hai.Pinzu8,
+			},
+			inHai:       hai.Pinzu9,
+			inNaki:      &naki.NakiMock{},
+			inSituation: &Situation{IsRiichi: true, OmoteDora: []*hai.Hai{hai.Manzu9, hai.Sha}, UraDora: []*hai.Hai{hai.Pinzu8}},
+			outNames:    []string{"riichi", "dora", "ura dora"},
+		},
+		{
+			name: "chankan",
+			inHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu2, hai.Manzu3, hai.Souzu2, hai.Souzu3, hai.Souzu4,
+				hai.Pinzu7, hai.Pinzu7,
+				hai.Pei, hai.Pei,
+			},
+			inHai:       hai.Pei,
+			inNaki:      &naki.NakiMock{ChiisMock: [][3]*hai.Hai{{hai.Pinzu4, hai.Pinzu5, hai.Pinzu6}}},
+			inSituation: &Situation{IsChankan: true},
+			outNames:    []string{"chankan"},
+		},
+		{
+			name: "rinshan kaihou",
+			inHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu2, hai.Manzu3, hai.Souzu2, hai.Souzu3, hai.Souzu4,
+				hai.Pinzu7, hai.Pinzu7,
+				hai.Pei, hai.Pei,
+			},
+			inHai:       hai.Pei,
+			inNaki:      &naki.NakiMock{MinKansMock: [][4]*hai.Hai{{hai.Souzu9, hai.Souzu9, hai.Souzu9, hai.Souzu9}}},
+			inSituation: &Situation{IsTsumo: true, IsRinshan: true},
+			outNames:    []string{"rinshan kaihou"},
+		},
+		{
+			name: "haitei raoyue",
+			inHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu2, hai.Manzu3, hai.Souzu2, hai.Souzu3, hai.Souzu4,
+				hai.Pinzu7, hai.Pinzu7,
+				hai.Pei, hai.Pei,
+			},
+			inHai:       hai.Pei,
+			inNaki:      &naki.NakiMock{ChiisMock: [][3]*hai.Hai{{hai.Pinzu4, hai.Pinzu5, hai.Pinzu6}}},
+			inSituation: &Situation{IsTsumo: true, IsHaitei: true},
+			outNames:    []string{"haitei raoyue"},
+		},
+		{
+			name: "houtei raoyui",
+			inHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu2, hai.Manzu3, hai.Souzu2, hai.Souzu3, hai.Souzu4,
+				hai.Pinzu7, hai.Pinzu7,
+				hai.Pei, hai.Pei,
+			},
+			inHai:       hai.Pei,
+			inNaki:      &naki.NakiMock{ChiisMock: [][3]*hai.Hai{{hai.Pinzu4, hai.Pinzu5, hai.Pinzu6}}},
+			inSituation: &Situation{IsHoutei: true},
+			outNames:    []string{"houtei raoyui"},
+		},
+		{
+			name: "riichi ippatsu",
+			inHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu2, hai.Manzu3, hai.Souzu2, hai.Souzu3, hai.Souzu4,
+				hai.Pinzu4, hai.Pinzu5, hai.Pinzu6, hai.Pinzu7, hai.Pinzu7,
+				hai.Pei, hai.Pei,
+			},
+			inHai:       hai.Pei,
+			inNaki:      &naki.NakiMock{},
+			inSituation: &Situation{IsTsumo: true, IsRiichi: true, IsIppatsu: true},
+			outNames:    []string{"riichi", "ippatsu", "menzen tsumo"},
+		},
+		{
+			name: "double riichi",
+			inHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu2, hai.Manzu3, hai.Souzu2, hai.Souzu3, hai.Souzu4,
+				hai.Pinzu4, hai.Pinzu5, hai.Pinzu6, hai.Pinzu7, hai.Pinzu7,
+				hai.Pei, hai.Pei,
+			},
+			inHai:       hai.Pei,
+			inNaki:      &naki.NakiMock{},
+			inSituation: &Situation{IsRiichi: true, IsDoubleRiichi: true},
+			outNames:    []string{"double riichi"},
+		},
+		{
+			name: "tenhou",
+			inHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu2, hai.Manzu3, hai.Souzu2, hai.Souzu3, hai.Souzu4,
+				hai.Pinzu4, hai.Pinzu5, hai.Pinzu6, hai.Pinzu7, hai.Pinzu7,
+				hai.Pei, hai.Pei,
+			},
+			inHai:       hai.Pei,
+			inNaki:      &naki.NakiMock{},
+			inSituation: &Situation{IsTsumo: true, IsTenhou: true},
+			outNames:    []string{"tenhou"},
+		},
+		{
+			name: "chiihou",
+			inHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu2, hai.Manzu3, hai.Souzu2, hai.Souzu3, hai.Souzu4,
+				hai.Pinzu4, hai.Pinzu5, hai.Pinzu6, hai.Pinzu7, hai.Pinzu7,
+				hai.Pei, hai.Pei,
+			},
+			inHai:       hai.Pei,
+			inNaki:      &naki.NakiMock{},
+			inSituation: &Situation{IsTsumo: true, IsChiihou: true},
+			outNames:    []string{"chiihou"},
+		},
+		{
+			name: "no yaku with dora",
+			inHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu2, hai.Manzu3, hai.Souzu2, hai.Souzu3, hai.Souzu4,
+				hai.Pinzu7, hai.Pinzu7,
+				hai.Pei, hai.Pei,
+			},
+			inHai:       hai.Pei,
+			inNaki:      &naki.NakiMock{ChiisMock: [][3]*hai.Hai{{hai.Pinzu4, hai.Pinzu5, hai.Pinzu6}}},
+			inSituation: &Situation{OmoteDora: []*hai.Hai{hai.Sha}},
+			outNames:    []string{},
+		},
+		{
+			name: "no yaku",
+			inHais: []*hai.Hai{
+				hai.Manzu1, hai.Manzu2, hai.Manzu3, hai.Souzu2, hai.Souzu3, hai.Souzu4,
+				hai.Pinzu7, hai.Pinzu7,
+				hai.Pei, hai.Pei,
+			},
+			inHai:       hai.Pei,
+			inNaki:      &naki.NakiMock{ChiisMock: [][3]*hai.Hai{{hai.Pinzu4, hai.Pinzu5, hai.Pinzu6}}},
+			inSituation: &Situation{},
+			outNames:    []string{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			th := tehai.New()
+			assert.NoError(t, th.Adds(c.inHais))
+			agaris, err := th.Agaris(c.inHai)
+			assert.NoError(t, err)
+			_, yakus, err := Best(agaris, c.inNaki, c.inHai, c.inSituation)
+			assert.NoError(t, err)
+			assert.Equal(t, c.outNames, names(yakus))
+		})
+	}
+}
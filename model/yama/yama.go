@@ -1,28 +1,50 @@
 package yama
 
 import (
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"mahjong/model/hai"
 	"math/rand"
+	"strings"
 	"time"
 )
 
 type Yama interface {
 	SetYamaHai([]*hai.Hai) error
 	Draw() (*hai.Hai, error)
-	Kan() error
+	Kan() (*hai.Hai, error)
+	// CanKan tells a kan can still draw the rinshan hai, not on the last hai of the live wall
+	CanKan() bool
+	// IsEmpty tells the live wall is drawn out, the last tsumo was the haitei
+	IsEmpty() bool
 
 	OmoteDora() []*hai.Hai
 	UraDora() []*hai.Hai
+
+	// Commitment is published at the start of the hand, the sha256 of the wall and the salt
+	Commitment() string
+	// Reveal returns the wall as shuffled and the salt, to be shown only after the hand
+	Reveal() ([]*hai.Hai, []byte)
 }
 
+// the dead wall is made of 4 rinshanHai and 10 wanHai, the pairs of omote and ura dora indicators.
 type yamaImpl struct {
-	yamaHai   []*hai.Hai
-	wanHai    []*hai.Hai
-	uraDora   []*hai.Hai
-	omoteDora []*hai.Hai
+	yamaHai    []*hai.Hai
+	rinshanHai []*hai.Hai
+	wanHai     []*hai.Hai
+	uraDora    []*hai.Hai
+	omoteDora  []*hai.Hai
+	// the wall as shuffled and the salt of the commitment
+	wall       []*hai.Hai
+	salt       []byte
+	commitment string
 }
 
 var (
+	// the bytes of the salt, too long to search the walls from the commitment
+	SaltLen = 16
+
 	all = []*hai.Hai{
 		hai.Manzu1, hai.Manzu2, hai.Manzu3, hai.Manzu4, hai.Manzu5, hai.Manzu6, hai.Manzu7, hai.Manzu8, hai.Manzu9,
 		hai.Manzu1, hai.Manzu2, hai.Manzu3, hai.Manzu4, hai.Manzu5, hai.Manzu6, hai.Manzu7, hai.Manzu8, hai.Manzu9,
@@ -51,15 +73,43 @@ var (
 )
 
 func New() Yama {
+	return NewWithRand(rand.New(rand.NewSource(time.Now().UnixNano())))
+}
+
+// NewWithRand shuffles the wall with r, the same sequence of r builds the same wall.
+func NewWithRand(r *rand.Rand) Yama {
 	allHai := append([]*hai.Hai{}, all...)
-	rand.Seed(time.Now().Unix())
-	rand.Shuffle(len(allHai), func(i, j int) { allHai[i], allHai[j] = allHai[j], allHai[i] })
-	return &yamaImpl{
-		yamaHai:   allHai[:122],
-		wanHai:    allHai[122:],
-		omoteDora: []*hai.Hai{},
-		uraDora:   []*hai.Hai{},
+	r.Shuffle(len(allHai), func(i, j int) { allHai[i], allHai[j] = allHai[j], allHai[i] })
+	y := newYama(allHai)
+	y.salt = make([]byte, SaltLen)
+	// the salt is not from r, the seed must not tell the commitment
+	if _, err := crand.Read(y.salt); err != nil {
+		r.Read(y.salt)
 	}
+	y.commitment = Commit(y.wall, y.salt)
+	return y
+}
+
+// NewWithWall deals the wall as it is, without the salt and the commitment to replay a recorded hand.
+func NewWithWall(wall []*hai.Hai) (Yama, error) {
+	if len(wall) != len(all) {
+		return nil, YamaInvalidWallErr
+	}
+	return newYama(append([]*hai.Hai{}, wall...)), nil
+}
+
+func newYama(allHai []*hai.Hai) *yamaImpl {
+	y := &yamaImpl{
+		yamaHai:    allHai[:122],
+		rinshanHai: allHai[122:126],
+		wanHai:     allHai[126:],
+		omoteDora:  []*hai.Hai{},
+		uraDora:    []*hai.Hai{},
+		wall:       append([]*hai.Hai{}, allHai...),
+	}
+	// the first dora indicator is revealed from the start
+	y.flipDora()
+	return y
 }
 
 func (y *yamaImpl) SetYamaHai(hais []*hai.Hai) error {
@@ -67,6 +117,14 @@ func (y *yamaImpl) SetYamaHai(hais []*hai.Hai) error {
 	return nil
 }
 
+func (y *yamaImpl) Commitment() string {
+	return y.commitment
+}
+
+func (y *yamaImpl) Reveal() ([]*hai.Hai, []byte) {
+	return y.wall, y.salt
+}
+
 func (y *yamaImpl) OmoteDora() []*hai.Hai {
 	return y.omoteDora
 }
@@ -76,7 +134,7 @@ func (y *yamaImpl) UraDora() []*hai.Hai {
 }
 
 func (y *yamaImpl) Draw() (*hai.Hai, error) {
-	if len(y.yamaHai)+(len(y.wanHai)/3) == 4 {
+	if len(y.yamaHai) == 0 {
 		return nil, YamaNoMoreHaiErr
 	}
 	outHai := y.yamaHai[0]
@@ -85,7 +143,31 @@ func (y *yamaImpl) Draw() (*hai.Hai, error) {
 	return outHai, nil
 }
 
-func (y *yamaImpl) Kan() error {
+func (y *yamaImpl) CanKan() bool {
+	return len(y.rinshanHai) != 0 && len(y.yamaHai) != 0 && len(y.wanHai) >= 2
+}
+
+func (y *yamaImpl) IsEmpty() bool {
+	return len(y.yamaHai) == 0
+}
+
+// Kan draws a rinshan hai and reveals a new dora indicator.
+// the last hai of the live wall moves to the dead wall to keep it 14 hais.
+func (y *yamaImpl) Kan() (*hai.Hai, error) {
+	if len(y.rinshanHai) == 0 || len(y.yamaHai) == 0 {
+		return nil, YamaNoMoreHaiErr
+	}
+	if err := y.flipDora(); err != nil {
+		return nil, err
+	}
+	outHai := y.rinshanHai[0]
+	y.rinshanHai = y.rinshanHai[1:]
+	y.yamaHai = y.yamaHai[:len(y.yamaHai)-1]
+
+	return outHai, nil
+}
+
+func (y *yamaImpl) flipDora() error {
 	if len(y.wanHai) < 2 {
 		return YamaNoMoreHaiErr
 	}
@@ -97,3 +179,54 @@ func (y *yamaImpl) Kan() error {
 	y.wanHai = y.wanHai[2:]
 	return nil
 }
+
+// WallString is the names of the wall separated by spaces.
+func WallString(wall []*hai.Hai) string {
+	names := []string{}
+	for _, h := range wall {
+		names = append(names, h.Name())
+	}
+	return strings.Join(names, " ")
+}
+
+// AtoWall parses the wall string.
+func AtoWall(s string) ([]*hai.Hai, error) {
+	wall := []*hai.Hai{}
+	for _, name := range strings.Fields(s) {
+		h, err := hai.AtoHai(name)
+		if err != nil {
+			return nil, err
+		}
+		wall = append(wall, h)
+	}
+	return wall, nil
+}
+
+// Commit is the hex sha256 of the salt in hex and the wall string, separated by a space.
+func Commit(wall []*hai.Hai, salt []byte) string {
+	sum := sha256.Sum256([]byte(hex.EncodeToString(salt) + " " + WallString(wall)))
+	return hex.EncodeToString(sum[:])
+}
+
+// Verify checks the revealed wall is a full set of hais and matches the commitment.
+func Verify(commitment string, wall []*hai.Hai, salt []byte) error {
+	cnt := map[*hai.Hai]int{}
+	for _, h := range all {
+		cnt[h]++
+	}
+	for _, h := range wall {
+		cnt[h]--
+	}
+	for _, c := range cnt {
+		if c != 0 {
+			return YamaInvalidWallErr
+		}
+	}
+	if len(wall) != len(all) {
+		return YamaInvalidWallErr
+	}
+	if Commit(wall, salt) != strings.ToLower(commitment) {
+		return YamaCommitmentMismatchErr
+	}
+	return nil
+}
@@ -3,5 +3,7 @@ package yama
 import "errors"
 
 var (
-	YamaNoMoreHaiErr = errors.New("there is no more hai")
+	YamaNoMoreHaiErr          = errors.New("there is no more hai")
+	YamaInvalidWallErr        = errors.New("the wall is not a full set of hais")
+	YamaCommitmentMismatchErr = errors.New("the wall does not match the commitment")
 )
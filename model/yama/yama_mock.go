@@ -5,9 +5,12 @@ import "mahjong/model/hai"
 var _ Yama = &YamaMock{}
 
 type YamaMock struct {
-	HaiMock   *hai.Hai
-	ErrorMock error
-	HaisMock  []*hai.Hai
+	HaiMock    *hai.Hai
+	ErrorMock  error
+	HaisMock   []*hai.Hai
+	StringMock string
+	BytesMock  []byte
+	BoolMock   bool
 }
 
 func (y *YamaMock) SetYamaHai(_ []*hai.Hai) error {
@@ -26,7 +29,22 @@ func (y *YamaMock) UraDora() []*hai.Hai {
 	return y.HaisMock
 }
 
-func (y *YamaMock) Kan() error {
-	return y.ErrorMock
+func (y *YamaMock) Kan() (*hai.Hai, error) {
+	return y.HaiMock, y.ErrorMock
+}
+
+func (y *YamaMock) CanKan() bool {
+	return y.ErrorMock == nil
+}
+
+func (y *YamaMock) IsEmpty() bool {
+	return y.BoolMock
+}
+
+func (y *YamaMock) Commitment() string {
+	return y.StringMock
+}
 
+func (y *YamaMock) Reveal() ([]*hai.Hai, []byte) {
+	return y.HaisMock, y.BytesMock
 }
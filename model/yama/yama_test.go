@@ -2,13 +2,123 @@ package yama
 
 import (
 	"mahjong/model/hai"
+	"math/rand"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
 func TestNew(t *testing.T) {
-	_ = New()
+	y := New()
+	assert.Len(t, y.OmoteDora(), 1)
+	assert.Len(t, y.UraDora(), 1)
+}
+
+func TestNewWithRand(t *testing.T) {
+	y1 := NewWithRand(rand.New(rand.NewSource(1)))
+	y2 := NewWithRand(rand.New(rand.NewSource(1)))
+	wall1, salt1 := y1.Reveal()
+	wall2, salt2 := y2.Reveal()
+	assert.Equal(t, wall1, wall2)
+	// the salt is not from the seed
+	assert.NotEqual(t, salt1, salt2)
+	assert.NotEqual(t, y1.Commitment(), y2.Commitment())
+	y3 := NewWithRand(rand.New(rand.NewSource(2)))
+	wall3, _ := y3.Reveal()
+	assert.NotEqual(t, wall1, wall3)
+}
+
+func TestNewWithWall(t *testing.T) {
+	y, err := NewWithWall(all)
+	assert.NoError(t, err)
+	wall, salt := y.Reveal()
+	assert.Equal(t, all, wall)
+	assert.Nil(t, salt)
+	assert.Equal(t, "", y.Commitment())
+	assert.Equal(t, []*hai.Hai{all[126]}, y.OmoteDora())
+	h, err := y.Draw()
+	assert.NoError(t, err)
+	assert.Equal(t, all[0], h)
+
+	_, err = NewWithWall(all[1:])
+	assert.Equal(t, YamaInvalidWallErr, err)
+}
+
+func TestCanKan(t *testing.T) {
+	y, err := NewWithWall(all)
+	assert.NoError(t, err)
+	assert.True(t, y.CanKan())
+	for i := 0; i < 121; i++ {
+		_, err := y.Draw()
+		assert.NoError(t, err)
+	}
+	assert.True(t, y.CanKan())
+	_, err = y.Draw()
+	assert.NoError(t, err)
+	// the last hai of the live wall is drawn
+	assert.False(t, y.CanKan())
+}
+
+func TestVerify(t *testing.T) {
+	y := New()
+	wall, salt := y.Reveal()
+	swapped := append([]*hai.Hai{}, wall...)
+	swapped[0], swapped[len(swapped)-1] = swapped[len(swapped)-1], swapped[0]
+	if swapped[0] == wall[0] {
+		swapped[0], swapped[1] = swapped[1], swapped[0]
+	}
+	cases := []struct {
+		name         string
+		inCommitment string
+		inWall       []*hai.Hai
+		inSalt       []byte
+		outError     error
+	}{
+		{
+			name:         "success",
+			inCommitment: y.Commitment(),
+			inWall:       wall,
+			inSalt:       salt,
+		},
+		{
+			name:         "failure: another salt",
+			inCommitment: y.Commitment(),
+			inWall:       wall,
+			inSalt:       []byte("salt"),
+			outError:     YamaCommitmentMismatchErr,
+		},
+		{
+			name:         "failure: another order",
+			inCommitment: y.Commitment(),
+			inWall:       swapped,
+			inSalt:       salt,
+			outError:     YamaCommitmentMismatchErr,
+		},
+		{
+			name:         "failure: not a full set",
+			inCommitment: y.Commitment(),
+			inWall:       wall[1:],
+			inSalt:       salt,
+			outError:     YamaInvalidWallErr,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.outError, Verify(c.inCommitment, c.inWall, c.inSalt))
+		})
+	}
+}
+
+func TestAtoWall(t *testing.T) {
+	wall, salt := New().Reveal()
+	out, err := AtoWall(WallString(wall))
+	assert.NoError(t, err)
+	assert.Equal(t, wall, out)
+	assert.Len(t, salt, SaltLen)
+
+	_, err = AtoWall("m1 x9")
+	assert.Equal(t, hai.HaiInvalidArgumentErr, err)
 }
 
 func TestDraw(t *testing.T) {
@@ -20,13 +130,13 @@ func TestDraw(t *testing.T) {
 	}{
 		{
 			beforeYamaHai: all[:122],
-			beforeWanHai:  all[122:],
+			beforeWanHai:  all[126:],
 			outHai:        hai.Manzu1,
 			outError:      nil,
 		},
 		{
 			beforeYamaHai: []*hai.Hai{},
-			beforeWanHai:  all[122:],
+			beforeWanHai:  all[126:],
 			outHai:        nil,
 			outError:      YamaNoMoreHaiErr,
 		},
@@ -67,7 +177,7 @@ func TestFlipDora(t *testing.T) {
 	for _, c := range cases {
 
 		yama := yamaImpl{wanHai: c.beforeWanHai}
-		err := yama.Kan()
+		err := yama.flipDora()
 		if err != nil {
 			assert.Equal(t, c.outError, err)
 			continue
@@ -78,3 +188,54 @@ func TestFlipDora(t *testing.T) {
 	}
 
 }
+
+func TestKan(t *testing.T) {
+	cases := []struct {
+		name             string
+		beforeYamaHai    []*hai.Hai
+		beforeRinshanHai []*hai.Hai
+		beforeWanHai     []*hai.Hai
+		outHai           *hai.Hai
+		outError         error
+		afterYamaHai     []*hai.Hai
+		afterOmoteDora   []*hai.Hai
+	}{
+		{
+			name:             "success",
+			beforeYamaHai:    []*hai.Hai{hai.Manzu1, hai.Manzu2},
+			beforeRinshanHai: []*hai.Hai{hai.Haku, hai.Hatsu},
+			beforeWanHai:     []*hai.Hai{hai.Ton, hai.Nan},
+			outHai:           hai.Haku,
+			afterYamaHai:     []*hai.Hai{hai.Manzu1},
+			afterOmoteDora:   []*hai.Hai{hai.Ton},
+		},
+		{
+			name:             "failure: no rinshan hai",
+			beforeYamaHai:    []*hai.Hai{hai.Manzu1, hai.Manzu2},
+			beforeRinshanHai: []*hai.Hai{},
+			beforeWanHai:     []*hai.Hai{hai.Ton, hai.Nan},
+			outError:         YamaNoMoreHaiErr,
+		},
+		{
+			name:             "failure: haitei",
+			beforeYamaHai:    []*hai.Hai{},
+			beforeRinshanHai: []*hai.Hai{hai.Haku, hai.Hatsu},
+			beforeWanHai:     []*hai.Hai{hai.Ton, hai.Nan},
+			outError:         YamaNoMoreHaiErr,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			yama := yamaImpl{yamaHai: c.beforeYamaHai, rinshanHai: c.beforeRinshanHai, wanHai: c.beforeWanHai}
+			outHai, err := yama.Kan()
+			if err != nil {
+				assert.Equal(t, c.outError, err)
+				return
+			}
+			assert.Equal(t, c.outHai, outHai)
+			assert.Equal(t, c.afterYamaHai, yama.yamaHai)
+			assert.Equal(t, c.afterOmoteDora, yama.OmoteDora())
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"mahjong/model/paifu"
+	"mahjong/server/usecase"
+	"os"
+)
+
+// replay steps through the paifu file on the terminal.
+func replay(args []string) {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: replay <paifu file>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		log.Fatal(err)
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	events, err := paifu.ReadFile(fs.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	write := func(mess string) error {
+		_, err := os.Stdout.WriteString(mess)
+		return err
+	}
+	// a command for each line, also when the commands are piped
+	stdin := bufio.NewReader(os.Stdin)
+	read := func(buffer []byte) error {
+		line, err := stdin.ReadString('\n')
+		copy(buffer, line)
+		if err == io.EOF && line != "" {
+			return nil
+		}
+		return err
+	}
+	ru := usecase.NewReplayUsecase("", write, read)
+	if err := ru.Play(events); err != nil && err != io.EOF {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,7 @@
+package server
+
+import "errors"
+
+var (
+	WebOriginErr = errors.New("the websocket from the other site")
+)
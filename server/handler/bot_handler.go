@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"io"
+	"log"
+	"mahjong/model/agent"
+	"mahjong/model/kawa"
+	"mahjong/model/naki"
+	"mahjong/model/player"
+	"mahjong/model/tehai"
+	"mahjong/server/usecase"
+
+	"github.com/google/uuid"
+	"github.com/k-jun/northpole/room"
+	"github.com/k-jun/northpole/user"
+)
+
+// BotHandler seats the agent, Join takes the seat of the room before Run waits for the others.
+type BotHandler interface {
+	Handler
+	Join() error
+}
+
+type botHandlerImpl struct {
+	id           uuid.UUID
+	room         room.Room
+	agent        agent.Agent
+	matchUsecase usecase.MatchUsecase
+	gameUsecase  usecase.GameUsecase
+	// closed when the room is full
+	rc chan room.Room
+}
+
+// NewBot is the handler seating the agent to the room.
+func NewBot(id uuid.UUID, r room.Room, a agent.Agent, matchUsecase usecase.MatchUsecase, gameUsecase usecase.GameUsecase) BotHandler {
+	return &botHandlerImpl{id: id, room: r, agent: a, matchUsecase: matchUsecase, gameUsecase: gameUsecase}
+}
+
+// Join takes the seat of the room, the agent is closed when the room is full already.
+func (h *botHandlerImpl) Join() error {
+	rc, err := h.matchUsecase.JoinRoom(user.New(h.id.String()), h.room)
+	if err != nil {
+		h.closeAgent()
+		return err
+	}
+	h.rc = rc
+	return nil
+}
+
+func (h *botHandlerImpl) Run() {
+	if h.rc == nil {
+		if err := h.Join(); err != nil {
+			log.Println(err)
+			return
+		}
+	}
+	for range h.rc {
+	}
+
+	cha := player.New(h.id, kawa.New(), tehai.New(), naki.New())
+	// the agent is closed by the game once seated
+	if err := h.gameUsecase.SeatAgent(h.room.ID(), cha, h.agent); err != nil {
+		log.Println(err)
+		h.closeAgent()
+	}
+}
+
+func (h *botHandlerImpl) closeAgent() {
+	if c, ok := h.agent.(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			log.Println(err)
+		}
+	}
+}
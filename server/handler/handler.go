@@ -2,6 +2,7 @@ package handler
 
 import (
 	"log"
+	"mahjong/model/board"
 	"mahjong/model/kawa"
 	"mahjong/model/naki"
 	"mahjong/model/player"
@@ -19,35 +20,73 @@ type Handler interface {
 var MaxNumberOfUsers = 4
 
 type handlerImpl struct {
-	id           uuid.UUID
-	matchUsecase usecase.MatchUsecase
-	gameUsecase  usecase.GameUsecase
-	close        func() error
+	id            uuid.UUID
+	matchUsecase  usecase.MatchUsecase
+	gameUsecase   usecase.GameUsecase
+	replayUsecase usecase.ReplayUsecase
+	close         func() error
 }
 
-func New(id uuid.UUID, matchUsecase usecase.MatchUsecase, gameUsecase usecase.GameUsecase, close func() error) Handler {
-	return &handlerImpl{id, matchUsecase, gameUsecase, close}
+func New(id uuid.UUID, matchUsecase usecase.MatchUsecase, gameUsecase usecase.GameUsecase, replayUsecase usecase.ReplayUsecase, close func() error) Handler {
+	return &handlerImpl{id, matchUsecase, gameUsecase, replayUsecase, close}
 }
 
 func (h *handlerImpl) Run() {
 	defer h.close()
-	user := user.New(h.id.String())
-
-	roomId, err := h.matchUsecase.JoinRandomRoom(user)
+	fields, err := h.gameUsecase.Entrance()
 	if err != nil {
 		log.Println(err)
 		return
 	}
-
-	t := tehai.New()
-	n := naki.New()
-	k := kawa.New()
-	cha := player.New(h.id, k, t, n)
-	roomChan, err := h.gameUsecase.JoinBoard(roomId, cha)
+	fields, p, err := h.gameUsecase.Negotiate(fields)
 	if err != nil {
 		log.Println(err)
 		return
 	}
+	h.matchUsecase.SetProtocol(p)
+
+	var roomId string
+	var cha player.Player
+	var roomChan chan board.Board
+	user := user.New(h.id.String())
+	for cha == nil {
+		if len(fields) == 2 && fields[0] == "replay" {
+			if err := h.replayUsecase.Replay(fields[1]); err != nil {
+				log.Println(err)
+			}
+			return
+		}
+		if len(fields) == 2 && fields[0] == "resume" {
+			roomId, cha, roomChan, err = h.gameUsecase.Resume(fields[1])
+			if err != nil {
+				log.Println(err)
+				return
+			}
+			// a new game by the quick match when the session is not found
+			fields = []string{}
+			continue
+		}
+
+		roomId, fields, err = h.matchUsecase.Lobby(user, fields)
+		if err != nil {
+			log.Println(err)
+			return
+		}
+		if roomId == "" {
+			continue
+		}
+
+		t := tehai.New()
+		n := naki.New()
+		k := kawa.New()
+		cha = player.New(h.id, k, t, n)
+		cha.SetName(h.matchUsecase.Name())
+		roomChan, err = h.gameUsecase.JoinBoard(roomId, cha)
+		if err != nil {
+			log.Println(err)
+			return
+		}
+	}
 	go h.gameUsecase.InputController(roomId, cha)
 	err = h.gameUsecase.OutputController(roomId, cha, roomChan)
 	if err != nil {
@@ -2,15 +2,24 @@ package server
 
 import (
 	"log"
+	"mahjong/model/agent"
 	"mahjong/model/board"
+	"mahjong/model/match"
+	"mahjong/model/paifu"
 	"mahjong/model/yama"
 	"mahjong/server/handler"
+	"mahjong/server/telnet"
 	"mahjong/server/usecase"
 	"mahjong/storage"
 	"mahjong/utils"
 	"net"
+	"sync"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/k-jun/northpole"
+	"github.com/k-jun/northpole/room"
+	"golang.org/x/crypto/ssh"
 )
 
 type Server interface {
@@ -18,52 +27,165 @@ type Server interface {
 }
 
 type serverImpl struct {
-	listener     net.Listener
-	matches      northpole.Match
-	boardStorage storage.BoardStorage
+	listener net.Listener
+	// the browser client and its websocket, nil not to serve them
+	webListener net.Listener
+	// the host the browser client is opened at, the host of the request when it is empty
+	webHost string
+	// the ssh server and its host key, nil not to serve it
+	sshListener    net.Listener
+	hostKey        ssh.Signer
+	identities     *identities
+	botWait        time.Duration
+	seed           int64
+	paifuDir       string
+	newAgent       func() (agent.Agent, error)
+	matches        northpole.Match
+	boardStorage   storage.BoardStorage
+	sessionStorage storage.SessionStorage
+	// the private rooms joined by the code, apart from the quick match
+	privates     northpole.Match
+	lobbyStorage storage.LobbyStorage
+	// the fills of the rooms one by one, the timers of the users in a room fire together
+	fillLock sync.Mutex
 }
 
-func New(listener net.Listener) Server {
+// New makes the server, the users connect to listener by tcp, to webListener by the browser and to sshListener by ssh.
+// the websocket is opened only from the page at webHost, or at the host of the request when it is empty.
+// the agents made by newAgent fill the empty seats after botWait, never when it is 0.
+// Every board is dealt from seed to reproduce a game, for the debug and the tests, a random seed for each board when it is 0.
+// The paifu of every match is written in paifuDir, not written when it is empty.
+func New(listener net.Listener, webListener net.Listener, webHost string, sshListener net.Listener, hostKey ssh.Signer, botWait time.Duration, seed int64, paifuDir string, newAgent func() (agent.Agent, error)) Server {
 	m := northpole.New()
 	ts := storage.NewBoardStorage()
 
 	return &serverImpl{
-		listener:     listener,
-		matches:      m,
-		boardStorage: ts,
+		listener:       listener,
+		webListener:    webListener,
+		webHost:        webHost,
+		sshListener:    sshListener,
+		hostKey:        hostKey,
+		identities:     &identities{ids: map[string]bool{}},
+		botWait:        botWait,
+		seed:           seed,
+		paifuDir:       paifuDir,
+		newAgent:       newAgent,
+		matches:        m,
+		privates:       northpole.New(),
+		lobbyStorage:   storage.NewLobbyStorage(),
+		boardStorage:   ts,
+		sessionStorage: storage.NewSessionStorage(),
 	}
 }
 
 func (s *serverImpl) Run() {
+	if s.webListener != nil {
+		go s.runWeb()
+	}
+	if s.sshListener != nil {
+		go s.runSSH()
+	}
 	for {
 		conn, err := s.listener.Accept()
 		if err != nil {
 			log.Fatal(err)
 		}
+		go s.serveTelnet(conn)
+	}
+}
+
+// serveTelnet plays the tcp connection, the telnet client gets the line editing by the server.
+// a read is a line, not a part of it.
+func (s *serverImpl) serveTelnet(conn net.Conn) {
+	tc, err := telnet.New(conn)
+	if err != nil {
+		log.Println(err)
+		if err := conn.Close(); err != nil {
+			log.Println(err)
+		}
+		return
+	}
+	read := func(buffer []byte) error {
+		for {
+			line, err := tc.ReadLine()
+			if err != nil {
+				return err
+			}
+			if fill(buffer, line) {
+				return nil
+			}
+			if err := tc.Write(lineTooLong); err != nil {
+				return err
+			}
+		}
+	}
+	s.handler(utils.NewUUID(), tc.Write, read, tc.Close).Run()
+}
+
+var lineTooLong = "the line is too long, type it again\n"
 
-		write := func(mess string) error {
-			_, err := conn.Write([]byte(mess))
+// fill copies the line with the line break to the buffer of the read, false when it does not fit.
+// the line of the multibyte runes is longer than the buffer of the usecases by the bytes.
+func fill(buffer []byte, line string) bool {
+	if len(line)+1 > len(buffer) {
+		return false
+	}
+	copy(buffer, line+"\n")
+	return true
+}
+
+// handler plays the connection of a user, the same for every kind of the connection.
+func (s *serverImpl) handler(id uuid.UUID, write func(string) error, read func([]byte) error, close func() error) handler.Handler {
+	matchUsecase := usecase.NewMatchUsecase(s.matches, s.privates, s.lobbyStorage, write, read, s.createBoard, s.fillBots, s.botWait)
+	gameUsecase := usecase.NewGameUsecase(s.boardStorage, s.sessionStorage, write, read)
+	replayUsecase := usecase.NewReplayUsecase(s.paifuDir, write, read)
+	return handler.New(id, matchUsecase, gameUsecase, replayUsecase, close)
+}
+
+// createBoard makes the board of the rule for the room full of the users.
+func (s *serverImpl) createBoard(id string, rule *match.Rule) error {
+	seed := s.seed
+	if seed == 0 {
+		var err error
+		if seed, err = utils.NewSeed(); err != nil {
 			return err
 		}
-		read := func(buffer []byte) error {
-			_, err := conn.Read(buffer)
+	}
+	log.Printf("board %s seed %d\n", id, seed)
+	var pf paifu.Paifu
+	if s.paifuDir != "" {
+		var err error
+		if pf, err = paifu.NewFile(s.paifuDir, id); err != nil {
 			return err
 		}
+	}
+	m := match.New(rule, board.MaxNumberOfUsers)
+	taku := board.New(board.MaxNumberOfUsers, m, seed, yama.NewWithRand, pf)
+	s.boardStorage.Add(id, taku)
+	return nil
+}
 
-		callback := func(id string) error {
-			yama := yama.New()
-			taku := board.New(board.MaxNumberOfUsers, yama)
-			s.boardStorage.Add(id, taku)
-			return nil
+// fillBots seats the bots to the empty seats of the room.
+// every bot takes its seat before the next one counts the empty seats.
+func (s *serverImpl) fillBots(r room.Room) {
+	s.fillLock.Lock()
+	defer s.fillLock.Unlock()
+	// the bots have no connection
+	write := func(string) error { return nil }
+	read := func([]byte) error { return nil }
+	for r.IsOpen() && r.CurrentNumberOfUsers() < r.MaxNumberOfUsers() {
+		matchUsecase := usecase.NewMatchUsecase(s.matches, s.privates, s.lobbyStorage, write, read, s.createBoard, s.fillBots, s.botWait)
+		gameUsecase := usecase.NewGameUsecase(s.boardStorage, s.sessionStorage, write, read)
+		a, err := s.newAgent()
+		if err != nil {
+			log.Println(err)
+			return
 		}
-		close := func() error {
-			return conn.Close()
+		h := handler.NewBot(utils.NewUUID(), r, a, matchUsecase, gameUsecase)
+		if err := h.Join(); err != nil {
+			log.Println(err)
+			return
 		}
-
-		matchUsecase := usecase.NewMatchUsecase(s.matches, write, read, callback)
-		gameUsecase := usecase.NewGameUsecase(s.boardStorage, write, read)
-		id := utils.NewUUID()
-		h := handler.New(id, matchUsecase, gameUsecase, close)
 		go h.Run()
 	}
 }
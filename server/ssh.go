@@ -0,0 +1,250 @@
+package server
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// LoadHostKey reads the host key of the ssh server, a new key is made and saved when the file does not exist.
+func LoadHostKey(path string) (ssh.Signer, error) {
+	raw, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+		raw = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+		if err := ioutil.WriteFile(path, raw, 0600); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	return ssh.ParsePrivateKey(raw)
+}
+
+// sshConfig accepts every user, the public key is the identity of the player.
+// the user without a key gets a new identity per connection, a user name is no proof of the player.
+func sshConfig(hostKey ssh.Signer) *ssh.ServerConfig {
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(c ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			return &ssh.Permissions{Extensions: map[string]string{"identity": "key " + ssh.FingerprintSHA256(key)}}, nil
+		},
+		// no question to the user without a key
+		KeyboardInteractiveCallback: func(_ ssh.ConnMetadata, _ ssh.KeyboardInteractiveChallenge) (*ssh.Permissions, error) {
+			return &ssh.Permissions{Extensions: map[string]string{"identity": "guest " + uuid.New().String()}}, nil
+		},
+	}
+	config.AddHostKey(hostKey)
+	return config
+}
+
+// identities are the players connected by ssh, an identity plays a session at a time.
+type identities struct {
+	sync.Mutex
+	ids map[string]bool
+}
+
+func (is *identities) add(id string) bool {
+	is.Lock()
+	defer is.Unlock()
+	if is.ids[id] {
+		return false
+	}
+	is.ids[id] = true
+	return true
+}
+
+func (is *identities) remove(id string) {
+	is.Lock()
+	defer is.Unlock()
+	delete(is.ids, id)
+}
+
+// runSSH accepts the ssh connections on the ssh listener.
+func (s *serverImpl) runSSH() {
+	config := sshConfig(s.hostKey)
+	for {
+		conn, err := s.sshListener.Accept()
+		if err != nil {
+			log.Fatal(err)
+		}
+		go s.serveSSH(conn, config)
+	}
+}
+
+func (s *serverImpl) serveSSH(conn net.Conn, config *ssh.ServerConfig) {
+	sconn, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	defer sconn.Close()
+	go ssh.DiscardRequests(reqs)
+
+	identity := sconn.Permissions.Extensions["identity"]
+	for nc := range chans {
+		if nc.ChannelType() != "session" {
+			if err := nc.Reject(ssh.UnknownChannelType, "unknown channel type"); err != nil {
+				log.Println(err)
+			}
+			continue
+		}
+		ch, requests, err := nc.Accept()
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		go s.serveSession(identity, ch, requests)
+	}
+}
+
+type ptyRequest struct {
+	Term    string
+	Columns uint32
+	Rows    uint32
+	Width   uint32
+	Height  uint32
+	Modes   string
+}
+
+type windowChange struct {
+	Columns uint32
+	Rows    uint32
+	Width   uint32
+	Height  uint32
+}
+
+// setSize sets the size of the terminal to wrap the line being edited, the default 80x24 is kept for no size.
+func setSize(term *terminal.Terminal, columns uint32, rows uint32) {
+	if columns == 0 || rows == 0 {
+		return
+	}
+	if err := term.SetSize(int(columns), int(rows)); err != nil {
+		log.Println(err)
+	}
+}
+
+// serveSession plays the shell of the session, the terminal edits the lines when a pty is requested.
+// without a pty the session is read and written as it is, for the programs on the json protocol.
+func (s *serverImpl) serveSession(identity string, ch ssh.Channel, requests <-chan *ssh.Request) {
+	defer ch.Close()
+	// the requests set the terminal while the session is played, the terminal locks its own state
+	var term *terminal.Terminal
+	var termLock sync.Mutex
+	getTerm := func() *terminal.Terminal {
+		termLock.Lock()
+		defer termLock.Unlock()
+		return term
+	}
+	var once sync.Once
+	shell := make(chan bool)
+	go func() {
+		for req := range requests {
+			ok := true
+			switch req.Type {
+			case "pty-req":
+				pty := ptyRequest{}
+				if err := ssh.Unmarshal(req.Payload, &pty); err != nil {
+					ok = false
+					break
+				}
+				// the pty is requested before the shell
+				t := terminal.NewTerminal(ch, "")
+				setSize(t, pty.Columns, pty.Rows)
+				termLock.Lock()
+				term = t
+				termLock.Unlock()
+			case "window-change":
+				wc := windowChange{}
+				t := getTerm()
+				if err := ssh.Unmarshal(req.Payload, &wc); err != nil || t == nil {
+					ok = false
+					break
+				}
+				setSize(t, wc.Columns, wc.Rows)
+			case "shell":
+				once.Do(func() { shell <- true })
+			default:
+				ok = false
+			}
+			if req.WantReply {
+				if err := req.Reply(ok, nil); err != nil {
+					log.Println(err)
+				}
+			}
+		}
+		// the session is closed without a shell
+		once.Do(func() { close(shell) })
+	}()
+	if !<-shell {
+		return
+	}
+
+	if !s.identities.add(identity) {
+		if _, err := ch.Write([]byte("already playing on another session\r\n")); err != nil {
+			log.Println(err)
+		}
+		return
+	}
+	defer s.identities.remove(identity)
+
+	write := func(mess string) error {
+		if mess == "" {
+			// the dead check of the match usecase writes nothing in a loop, a keepalive a second
+			time.Sleep(time.Second)
+			_, err := ch.SendRequest("keepalive@openssh.com", true, nil)
+			return err
+		}
+		if term := getTerm(); term != nil {
+			_, err := term.Write([]byte(mess))
+			return err
+		}
+		_, err := ch.Write([]byte(mess))
+		return err
+	}
+	read := func(buffer []byte) error {
+		term := getTerm()
+		if term == nil {
+			_, err := ch.Read(buffer)
+			return err
+		}
+		for {
+			line, err := term.ReadLine()
+			if err != nil {
+				return err
+			}
+			if fill(buffer, line) {
+				return nil
+			}
+			if _, err := term.Write([]byte(lineTooLong)); err != nil {
+				return err
+			}
+		}
+	}
+	close := func() error {
+		if _, err := ch.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{0})); err != nil {
+			log.Println(err)
+		}
+		return ch.Close()
+	}
+	// the same identity is the same player
+	id := uuid.NewSHA1(uuid.NameSpaceOID, []byte(identity))
+	s.handler(id, write, read, close).Run()
+}
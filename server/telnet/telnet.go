@@ -0,0 +1,335 @@
+package telnet
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Conn is the connection of a telnet client, or of a raw tcp client like netcat.
+// The telnet client gets the line edited and echoed by the server, the raw client edits the line by itself.
+type Conn interface {
+	// ReadLine returns the line typed by the user without the line break
+	ReadLine() (string, error)
+	Write(string) error
+	// Size is the window size of the telnet client, 0 when it is unknown
+	Size() (int, int)
+	Close() error
+}
+
+var (
+	// the wait for the answer of the negotiation, a raw client answers nothing
+	NegotiationWait = 300 * time.Millisecond
+	// the number of the lines kept in the history
+	MaxHistory = 50
+	// the runes of a line, the rest typed is dropped
+	MaxLine = 1024
+)
+
+const (
+	iac  = 255
+	dont = 254
+	do   = 253
+	wont = 252
+	will = 251
+	sb   = 250
+	se   = 240
+
+	optEcho = 1
+	optSGA  = 3
+	optNAWS = 31
+
+	// the bytes of a subnegotiation kept, the window size needs 5
+	maxSubnegotiation = 64
+
+	esc = 27
+)
+
+type connImpl struct {
+	conn net.Conn
+	r    *bufio.Reader
+	// guards the writes and the line being edited
+	sync.Mutex
+	// the client has answered the negotiation
+	isTelnet bool
+	// the server echoes the input, the client agreed to it
+	isEcho        bool
+	width, height int
+	line          []rune
+	history       []string
+	// the line of the history shown, len(history) is the new line
+	historyIdx int
+	isCR       bool
+}
+
+// New negotiates the echo, the suppress go ahead and the window size, and waits for the answer for a while.
+func New(conn net.Conn) (Conn, error) {
+	c := &connImpl{conn: conn, r: bufio.NewReader(conn)}
+	if _, err := conn.Write([]byte{iac, will, optEcho, iac, will, optSGA, iac, do, optNAWS}); err != nil {
+		return nil, err
+	}
+	if err := conn.SetReadDeadline(time.Now().Add(NegotiationWait)); err != nil {
+		return nil, err
+	}
+	bytes, err := c.r.Peek(1)
+	if err == nil && bytes[0] == iac {
+		c.isTelnet = true
+	} else if ne, ok := err.(net.Error); err != nil && !(ok && ne.Timeout()) {
+		return nil, err
+	}
+	return c, conn.SetReadDeadline(time.Time{})
+}
+
+func (c *connImpl) Close() error {
+	return c.conn.Close()
+}
+
+func (c *connImpl) Size() (int, int) {
+	c.Lock()
+	defer c.Unlock()
+	return c.width, c.height
+}
+
+// Write sends the line breaks as CRLF to the telnet client, and echoes the line being edited again after the message.
+func (c *connImpl) Write(mess string) error {
+	c.Lock()
+	defer c.Unlock()
+	if c.isTelnet {
+		mess = strings.ReplaceAll(strings.ReplaceAll(mess, "\r\n", "\n"), "\n", "\r\n")
+	}
+	if c.isEcho && mess != "" {
+		mess += string(c.line)
+	}
+	_, err := c.conn.Write([]byte(mess))
+	return err
+}
+
+func (c *connImpl) ReadLine() (string, error) {
+	for {
+		b, err := c.r.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		isCR := c.isCR
+		c.isCR = false
+		switch {
+		case b == iac:
+			if err := c.command(); err != nil {
+				return "", err
+			}
+		case b == '\r' || (b == '\n' && !isCR):
+			c.isCR = b == '\r'
+			return c.enter()
+		case b == '\n' || b == 0:
+			// the rest of CRLF or CR NUL
+		case b == 127 || b == '\b':
+			c.edit(func() { c.erase(1) })
+		case b == 21:
+			// ctrl-u
+			c.edit(func() { c.erase(len(c.line)) })
+		case b == 23:
+			// ctrl-w
+			c.edit(func() { c.erase(wordLen(c.line)) })
+		case b == 4 && len(c.line) == 0:
+			// ctrl-d
+			return "", io.EOF
+		case b == esc:
+			if err := c.escape(); err != nil {
+				return "", err
+			}
+		case b < 32:
+			// the control noise
+		default:
+			if err := c.r.UnreadByte(); err != nil {
+				return "", err
+			}
+			r, _, err := c.r.ReadRune()
+			if err != nil {
+				return "", err
+			}
+			if r == utf8.RuneError {
+				continue
+			}
+			c.edit(func() {
+				if len(c.line) >= MaxLine {
+					return
+				}
+				c.line = append(c.line, r)
+				c.echo(string(r))
+			})
+		}
+	}
+}
+
+func (c *connImpl) edit(f func()) {
+	c.Lock()
+	defer c.Unlock()
+	f()
+}
+
+func (c *connImpl) enter() (string, error) {
+	c.Lock()
+	defer c.Unlock()
+	line := string(c.line)
+	c.line = []rune{}
+	c.echo("\r\n")
+	if strings.TrimSpace(line) != "" && (len(c.history) == 0 || c.history[len(c.history)-1] != line) {
+		c.history = append(c.history, line)
+		if len(c.history) > MaxHistory {
+			c.history = c.history[1:]
+		}
+	}
+	c.historyIdx = len(c.history)
+	return line, nil
+}
+
+// erase removes the last n runes of the line, from the screen by their width.
+func (c *connImpl) erase(n int) {
+	if n > len(c.line) {
+		n = len(c.line)
+	}
+	width := 0
+	for _, r := range c.line[len(c.line)-n:] {
+		width += runeWidth(r)
+	}
+	c.line = c.line[:len(c.line)-n]
+	c.echo(strings.Repeat("\b", width) + strings.Repeat(" ", width) + strings.Repeat("\b", width))
+}
+
+func (c *connImpl) echo(str string) {
+	if !c.isEcho {
+		return
+	}
+	if _, err := c.conn.Write([]byte(str)); err != nil {
+		// the read finds the closed connection
+		return
+	}
+}
+
+// escape reads the arrow keys, up and down walk the history.
+func (c *connImpl) escape() error {
+	b, err := c.r.ReadByte()
+	if err != nil {
+		return err
+	}
+	if b != '[' && b != 'O' {
+		return nil
+	}
+	if b, err = c.r.ReadByte(); err != nil {
+		return err
+	}
+	c.edit(func() {
+		idx := c.historyIdx
+		switch b {
+		case 'A':
+			idx--
+		case 'B':
+			idx++
+		}
+		if !c.isEcho || idx == c.historyIdx || idx < 0 || idx > len(c.history) {
+			return
+		}
+		c.historyIdx = idx
+		c.erase(len(c.line))
+		if idx < len(c.history) {
+			c.line = []rune(c.history[idx])
+			c.echo(c.history[idx])
+		}
+	})
+	return nil
+}
+
+// command reads the telnet command after IAC.
+func (c *connImpl) command() error {
+	b, err := c.r.ReadByte()
+	if err != nil {
+		return err
+	}
+	switch b {
+	case will, wont, do, dont:
+		opt, err := c.r.ReadByte()
+		if err != nil {
+			return err
+		}
+		return c.option(b, opt)
+	case sb:
+		return c.subnegotiation()
+	}
+	// IAC IAC is the byte 255, no use in the commands
+	return nil
+}
+
+func (c *connImpl) option(verb byte, opt byte) error {
+	c.Lock()
+	defer c.Unlock()
+	c.isTelnet = true
+	switch {
+	case opt == optEcho && (verb == do || verb == dont):
+		c.isEcho = verb == do
+		return nil
+	case opt == optSGA && (verb == do || verb == dont):
+		return nil
+	case opt == optNAWS && (verb == will || verb == wont):
+		return nil
+	case verb == do:
+		_, err := c.conn.Write([]byte{iac, wont, opt})
+		return err
+	case verb == will:
+		_, err := c.conn.Write([]byte{iac, dont, opt})
+		return err
+	}
+	return nil
+}
+
+// subnegotiation reads up to IAC SE, the window size by NAWS. the data past the cap is dropped.
+func (c *connImpl) subnegotiation() error {
+	data := []byte{}
+	for {
+		b, err := c.r.ReadByte()
+		if err != nil {
+			return err
+		}
+		if b == iac {
+			if b, err = c.r.ReadByte(); err != nil {
+				return err
+			}
+			if b == se {
+				break
+			}
+			// IAC IAC in the data
+		}
+		if len(data) < maxSubnegotiation {
+			data = append(data, b)
+		}
+	}
+	if len(data) == 5 && data[0] == optNAWS {
+		c.Lock()
+		c.width, c.height = int(data[1])<<8|int(data[2]), int(data[3])<<8|int(data[4])
+		c.Unlock()
+	}
+	return nil
+}
+
+// wordLen is the length of the last word of the line with the spaces after it.
+func wordLen(line []rune) int {
+	n := len(line)
+	for n > 0 && line[n-1] == ' ' {
+		n--
+	}
+	for n > 0 && line[n-1] != ' ' {
+		n--
+	}
+	return len(line) - n
+}
+
+// runeWidth is 2 for the wide characters like the jihais.
+func runeWidth(r rune) int {
+	if r >= 0x1100 && (r <= 0x115f || (r >= 0x2e80 && r <= 0xa4cf) || (r >= 0xac00 && r <= 0xd7a3) || (r >= 0xf900 && r <= 0xfaff) || (r >= 0xfe30 && r <= 0xfe4f) || (r >= 0xff00 && r <= 0xff60) || (r >= 0xffe0 && r <= 0xffe6)) {
+		return 2
+	}
+	return 1
+}
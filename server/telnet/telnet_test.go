@@ -0,0 +1,196 @@
+package telnet
+
+import (
+	"io/ioutil"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	negotiation  = []byte{iac, will, optEcho, iac, will, optSGA, iac, do, optNAWS}
+	telnetAnswer = []byte{iac, do, optEcho, iac, do, optSGA, iac, will, optNAWS}
+)
+
+// dial makes the server side of a pipe, the client answers the negotiation with answer.
+// out gets all the bytes sent to the client after the client is closed.
+func dial(t *testing.T, answer []byte) (Conn, net.Conn, chan []byte) {
+	server, client := net.Pipe()
+	out := make(chan []byte, 1)
+	go func() {
+		bytes, _ := ioutil.ReadAll(client)
+		out <- bytes
+	}()
+	cc := make(chan Conn, 1)
+	go func() {
+		c, err := New(server)
+		assert.NoError(t, err)
+		cc <- c
+	}()
+	if answer != nil {
+		_, err := client.Write(answer)
+		assert.NoError(t, err)
+	}
+	return <-cc, client, out
+}
+
+func TestReadLine(t *testing.T) {
+	NegotiationWait = 50 * time.Millisecond
+	cases := []struct {
+		name      string
+		inAnswer  []byte
+		inInput   string
+		outTelnet bool
+		outLines  []string
+		outEcho   string
+		outWidth  int
+		outHeight int
+	}{
+		{
+			name:      "success: telnet client",
+			inAnswer:  append(append([]byte{}, telnetAnswer...), iac, sb, optNAWS, 0, 80, 0, 24, iac, se, iac, do, 24),
+			inInput:   "abc\r\n",
+			outTelnet: true,
+			outLines:  []string{"abc"},
+			outEcho:   string([]byte{iac, wont, 24}) + "abc\r\n",
+			outWidth:  80,
+			outHeight: 24,
+		},
+		{
+			name:      "success: telnet client without the echo",
+			inAnswer:  []byte{iac, dont, optEcho, iac, will, 24},
+			inInput:   "abc\r\n",
+			outTelnet: true,
+			outLines:  []string{"abc"},
+			outEcho:   string([]byte{iac, dont, 24}),
+		},
+		{
+			name:     "success: raw client",
+			inInput:  "abc\r\nde\n",
+			outLines: []string{"abc", "de"},
+		},
+		{
+			name:      "success: window size with IAC IAC",
+			inAnswer:  append(append([]byte{}, telnetAnswer...), iac, sb, optNAWS, 0, iac, iac, 0, 24, iac, se),
+			inInput:   "a\r\n",
+			outTelnet: true,
+			outLines:  []string{"a"},
+			outEcho:   "a\r\n",
+			outWidth:  255,
+			outHeight: 24,
+		},
+		{
+			name:      "success: CRLF and CR NUL",
+			inAnswer:  telnetAnswer,
+			inInput:   "a\r\nb\r\x00c\n",
+			outTelnet: true,
+			outLines:  []string{"a", "b", "c"},
+			outEcho:   "a\r\nb\r\nc\r\n",
+		},
+		{
+			name:      "success: backspace",
+			inAnswer:  telnetAnswer,
+			inInput:   "abd\x7fc\r\n",
+			outTelnet: true,
+			outLines:  []string{"abc"},
+			outEcho:   "abd\b \bc\r\n",
+		},
+		{
+			name:      "success: ctrl-u and ctrl-w",
+			inAnswer:  telnetAnswer,
+			inInput:   "ab\x15c\r\nab cd\x17ef\r\n",
+			outTelnet: true,
+			outLines:  []string{"c", "ab ef"},
+			outEcho:   "ab\b\b  \b\bc\r\nab cd\b\b  \b\bef\r\n",
+		},
+		{
+			name:      "success: history",
+			inAnswer:  telnetAnswer,
+			inInput:   "one\r\ntwo\r\n\x1b[A\x1b[A\r\n\x1b[A\x1b[B\r\n",
+			outTelnet: true,
+			outLines:  []string{"one", "two", "one", ""},
+			outEcho:   "one\r\ntwo\r\ntwo\b\b\b   \b\b\bone\r\none\b\b\b   \b\b\b\r\n",
+		},
+		{
+			name:     "success: the line past the cap is dropped",
+			inInput:  strings.Repeat("a", MaxLine+10) + "\n",
+			outLines: []string{strings.Repeat("a", MaxLine)},
+		},
+		{
+			name:      "success: the subnegotiation past the cap is dropped",
+			inAnswer:  append(append(append([]byte{}, telnetAnswer...), iac, sb, optNAWS), append([]byte(strings.Repeat("a", 1000)), iac, se)...),
+			inInput:   "x\r\n",
+			outTelnet: true,
+			outLines:  []string{"x"},
+			outEcho:   "x\r\n",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			conn, client, out := dial(t, c.inAnswer)
+			go client.Write([]byte(c.inInput))
+			for _, want := range c.outLines {
+				line, err := conn.ReadLine()
+				assert.NoError(t, err)
+				assert.Equal(t, want, line)
+			}
+			assert.Equal(t, c.outTelnet, conn.(*connImpl).isTelnet)
+			width, height := conn.Size()
+			assert.Equal(t, c.outWidth, width)
+			assert.Equal(t, c.outHeight, height)
+			assert.NoError(t, client.Close())
+			assert.Equal(t, string(negotiation)+c.outEcho, string(<-out))
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	NegotiationWait = 50 * time.Millisecond
+	cases := []struct {
+		name     string
+		inAnswer []byte
+		inInput  string
+		inMess   string
+		outEcho  string
+	}{
+		{
+			name:     "success: telnet client gets CRLF and the line being edited",
+			inAnswer: telnetAnswer,
+			inInput:  "ab",
+			inMess:   "x\ny\r\n",
+			outEcho:  "abx\r\ny\r\nab",
+		},
+		{
+			name:    "success: raw client",
+			inInput: "ab",
+			inMess:  "x\ny\n",
+			outEcho: "x\ny\n",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			conn, client, out := dial(t, c.inAnswer)
+			go client.Write([]byte(c.inInput))
+			// the input is read up to the line break which never comes
+			go conn.ReadLine()
+			for {
+				impl := conn.(*connImpl)
+				impl.Lock()
+				n := len(impl.line)
+				impl.Unlock()
+				if n == len(c.inInput) {
+					break
+				}
+				time.Sleep(time.Millisecond)
+			}
+			assert.NoError(t, conn.Write(c.inMess))
+			assert.NoError(t, client.Close())
+			assert.Equal(t, string(negotiation)+c.outEcho, string(<-out))
+		})
+	}
+}
@@ -4,6 +4,12 @@ import "errors"
 
 var (
 	MatchUsecaseRoomChannelClosedErr = errors.New("the room channel closed")
+	MatchUsecaseInvalidNameErr       = errors.New("the name is one word up to 16 letters")
+	MatchUsecaseInvalidRuleErr       = errors.New("the rule is tonpuusen or hanchan, and bots to fill the seats")
+	MatchUsecaseRoomNotFoundErr      = errors.New("no open room of the code")
+	MatchUsecaseInvalidCommandErr    = errors.New("unknown command in the lobby")
 	GameUsecaseBoardChannelClosedErr = errors.New("the board channel closed")
 	GameUsecaseInvalidActionErr      = errors.New("invalid action")
+	GameUsecaseDisconnectedErr       = errors.New("the connection is lost")
+	GameUsecaseSessionNotFoundErr    = errors.New("the session not found, start a new game")
 )
@@ -2,39 +2,73 @@ package usecase
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
 	"log"
+	"mahjong/model/agent"
 	"mahjong/model/board"
 	"mahjong/model/hai"
 	"mahjong/model/player"
+	"mahjong/model/protocol"
 	"mahjong/model/view"
 	"mahjong/storage"
+	"mahjong/utils"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 type GameUsecase interface {
 	JoinBoard(string, player.Player) (chan board.Board, error)
+	Entrance() ([]string, error)
+	Negotiate([]string) ([]string, Protocol, error)
+	Resume(string) (string, player.Player, chan board.Board, error)
 	InputController(string, player.Player)
+	Input(board.Board, player.Player, []byte) error
+	SeatAgent(string, player.Player, agent.Agent) error
+	AgentController(string, player.Player, chan board.Board, agent.Agent) error
 	OutputController(string, player.Player, chan board.Board) error
 }
 
 type gameUsecaseImpl struct {
-	BoardStorage storage.BoardStorage
-	read         func([]byte) error
-	write        func(string) error
+	BoardStorage   storage.BoardStorage
+	SessionStorage storage.SessionStorage
+	read           func([]byte) error
+	write          func(string) error
+	// the session token of the connection
+	token string
+	// closed when the connection is lost
+	quit chan struct{}
+	// the format of the messages on the connection
+	protocol Protocol
+	// the last messages sent in the json protocol, the actions of the state are taken by the id
+	sync.Mutex
+	lastMessages string
+	lastState    *protocol.State
 }
 
+// Protocol is the format of the messages on the connection.
+type Protocol string
+
 var (
 	num = regexp.MustCompile(`\d+`)
 	str = regexp.MustCompile(`\w+`)
+
+	TextProtocol Protocol = "text"
+	// JSONProtocol is a json message a line both ways, see PROTOCOL.md
+	JSONProtocol Protocol = "json"
 )
 
-func NewGameUsecase(ts storage.BoardStorage, write func(string) error, read func([]byte) error) GameUsecase {
+func NewGameUsecase(ts storage.BoardStorage, ss storage.SessionStorage, write func(string) error, read func([]byte) error) GameUsecase {
 	return &gameUsecaseImpl{
-		BoardStorage: ts,
-		read:         read,
-		write:        write,
+		BoardStorage:   ts,
+		SessionStorage: ss,
+		read:           read,
+		write:          write,
+		quit:           make(chan struct{}),
+		protocol:       TextProtocol,
 	}
 }
 
@@ -74,6 +108,8 @@ func (gu *gameUsecaseImpl) CommandParser(raw []byte) (*InputCommand, error) {
 		ic.actionType = board.Pon
 	case "kan":
 		ic.actionType = board.Kan
+	case "kakan":
+		ic.actionType = board.Kakan
 	case "ron":
 		ic.actionType = board.Ron
 	case "no":
@@ -92,249 +128,272 @@ func (gu *gameUsecaseImpl) CommandParser(raw []byte) (*InputCommand, error) {
 }
 
 func (gu *gameUsecaseImpl) Normal(b board.Board, p player.Player, ic *InputCommand) error {
-	err := p.Dahai(ic.hai)
-	if err != nil {
-		return err
-	}
-	return b.TurnEnd()
+	return b.Discard(p, ic.hai)
 }
 
 func (gu *gameUsecaseImpl) Tsumo(b board.Board, p player.Player, ic *InputCommand) error {
-	ok, err := p.CanTsumoAgari()
-	if err != nil {
-		return err
-	}
-	if !ok {
+	err := b.TsumoAgari(p)
+	if err == board.BoardActionInvalidErr {
 		return GameUsecaseInvalidActionErr
 	}
-	if err := b.SetWinner(p); err != nil {
-		return err
-	}
-	b.Broadcast()
-	return nil
+	return err
 }
 
 func (gu *gameUsecaseImpl) Riichi(b board.Board, p player.Player, ic *InputCommand) error {
-	ok, err := p.CanRiichi()
-	if err != nil {
-		return err
-	}
-	if !ok {
-		return GameUsecaseInvalidActionErr
-	}
-	hais, err := p.Tehai().RiichiHais(p.Tsumohai())
-	if err != nil {
+	var h *hai.Hai
+	if err := b.View(func() error {
+		hais, err := p.Tehai().RiichiHais(p.Tsumohai())
+		if err != nil {
+			return err
+		}
+		if ic.actionIndex >= len(hais) || ic.actionIndex < 0 {
+			return GameUsecaseInvalidActionErr
+		}
+		h = hais[ic.actionIndex]
+		return nil
+	}); err != nil {
 		return err
 	}
-	if len(hais) == 0 {
+	err := b.DeclareRiichi(p, h)
+	if err == board.BoardActionInvalidErr {
 		return GameUsecaseInvalidActionErr
 	}
-
-	if ic.actionIndex >= len(hais) || ic.actionIndex < 0 {
-		return GameUsecaseInvalidActionErr
-	}
-	err = p.Riichi(hais[ic.actionIndex])
-	if err != nil {
-		log.Println(err)
-	}
-	return b.TurnEnd()
+	return err
 }
 
 func (gu *gameUsecaseImpl) AnKan(b board.Board, p player.Player, ic *InputCommand) error {
-	ok, err := p.CanAnKan()
-	if err != nil {
-		return err
-	}
-	if !ok {
-		return GameUsecaseInvalidActionErr
-	}
-	pairs, err := p.Tehai().AnKanPairs(p.Tsumohai())
-	if err != nil {
-		return err
-	}
-
-	if len(pairs) == 0 {
-		return GameUsecaseInvalidActionErr
-	}
-	if ic.actionIndex >= len(pairs) || ic.actionIndex < 0 {
-		return GameUsecaseInvalidActionErr
-	}
-	if err := p.AnKan(pairs[ic.actionIndex]); err != nil {
-		return err
-	}
-	if err := p.Tsumo(); err != nil {
-		return err
-	}
-	b.Broadcast()
-	return nil
-}
-
-func (gu *gameUsecaseImpl) Chii(b board.Board, p player.Player, ic *InputCommand) error {
-	return b.TakeAction(p, func(inHai *hai.Hai) error {
-		ok, err := p.CanChii(inHai)
+	var pair [4]*hai.Hai
+	if err := b.View(func() error {
+		ok, err := p.CanAnKan()
 		if err != nil {
 			return err
 		}
 		if !ok {
 			return GameUsecaseInvalidActionErr
 		}
-		pairs, err := p.Tehai().ChiiPairs(inHai)
+		pairs, err := p.Tehai().AnKanPairs(p.Tsumohai())
 		if err != nil {
 			return err
 		}
-		if err != nil {
-			return err
-		}
-		if len(pairs) == 0 {
-			return GameUsecaseInvalidActionErr
-		}
 		if ic.actionIndex >= len(pairs) || ic.actionIndex < 0 {
 			return GameUsecaseInvalidActionErr
 		}
-		return p.Chii(inHai, pairs[ic.actionIndex])
-	})
+		pair = pairs[ic.actionIndex]
+		return nil
+	}); err != nil {
+		return err
+	}
+	return b.AnKan(p, pair)
 }
 
-func (gu *gameUsecaseImpl) Pon(b board.Board, p player.Player, ic *InputCommand) error {
-	return b.TakeAction(p, func(inHai *hai.Hai) error {
-		ok, err := p.CanPon(inHai)
-		if err != nil {
-			return err
-		}
-		if !ok {
+func (gu *gameUsecaseImpl) Kakan(b board.Board, p player.Player, ic *InputCommand) error {
+	var h *hai.Hai
+	if err := b.View(func() error {
+		hais := p.KakanHais()
+		if ic.actionIndex >= len(hais) || ic.actionIndex < 0 {
 			return GameUsecaseInvalidActionErr
 		}
-		pairs, err := p.Tehai().PonPairs(inHai)
-		if err != nil {
-			return err
-		}
-		if err != nil {
-			return err
-		}
-		if len(pairs) == 0 {
-			return GameUsecaseInvalidActionErr
-		}
-		if ic.actionIndex >= len(pairs) || ic.actionIndex < 0 {
-			return GameUsecaseInvalidActionErr
-		}
-		return p.Pon(inHai, pairs[ic.actionIndex])
-	})
+		h = hais[ic.actionIndex]
+		return nil
+	}); err != nil {
+		return err
+	}
+	return b.Kakan(p, h)
+}
+
+func (gu *gameUsecaseImpl) Chii(b board.Board, p player.Player, ic *InputCommand) error {
+	return gu.call(b, p, board.Chii, ic)
+}
+
+func (gu *gameUsecaseImpl) Pon(b board.Board, p player.Player, ic *InputCommand) error {
+	return gu.call(b, p, board.Pon, ic)
 }
 
 func (gu *gameUsecaseImpl) MinKan(b board.Board, p player.Player, ic *InputCommand) error {
-	return b.TakeAction(p, func(inHai *hai.Hai) error {
-		ok, err := p.CanMinKan(inHai)
-		if err != nil {
-			return err
-		}
-		if !ok {
-			return GameUsecaseInvalidActionErr
-		}
-		pairs, err := p.Tehai().MinKanPairs(inHai)
-		if err != nil {
-			return err
-		}
-		if err != nil {
-			return err
-		}
-		if len(pairs) == 0 {
-			return GameUsecaseInvalidActionErr
-		}
-		if ic.actionIndex >= len(pairs) || ic.actionIndex < 0 {
-			return GameUsecaseInvalidActionErr
-		}
-		return p.MinKan(inHai, pairs[ic.actionIndex])
-	})
+	return gu.call(b, p, board.Kan, ic)
 }
 
-func (gu *gameUsecaseImpl) Ron(b board.Board, p player.Player, ic *InputCommand) error {
-	return b.TakeAction(p, func(inHai *hai.Hai) error {
-		ok, err := p.CanRon(inHai)
-		if err != nil {
-			return err
-		}
-		if !ok {
-			return GameUsecaseInvalidActionErr
-		}
+// call checks the offer and picks the pair with the board locked, the window can close before the input.
+func (gu *gameUsecaseImpl) call(b board.Board, p player.Player, actionType board.ActionType, ic *InputCommand) error {
+	err := b.Call(p, actionType, ic.actionIndex)
+	if err == board.BoardActionInvalidErr || err == board.BoardIndexOutOfRangeErr {
+		return GameUsecaseInvalidActionErr
+	}
+	return err
+}
 
-		if err := p.Tehai().Add(inHai); err != nil {
-			return err
-		}
-		if err := b.SetWinner(p); err != nil {
-			return err
-		}
-		b.Broadcast()
-		return nil
-	})
+func (gu *gameUsecaseImpl) Ron(b board.Board, p player.Player, ic *InputCommand) error {
+	// the ron offered on the discard or on the kakan, checked with the board locked
+	err := b.RonAgari(p)
+	if err == board.BoardActionInvalidErr {
+		return GameUsecaseInvalidActionErr
+	}
+	return err
 }
 
 func (gu *gameUsecaseImpl) InputController(id string, p player.Player) {
 	b, err := gu.BoardStorage.Find(id)
 	if err != nil {
+		// the board is removed at the end of the match
 		log.Println(err)
+		return
 	}
 
 	for {
 		buffer := make([]byte, 1024)
 		if err := gu.read(buffer); err != nil {
-			// dead check
+			// dead check, the seat is kept for the player to resume
 			log.Println(err)
-			if err := gu.BoardStorage.Remove(id); err != nil {
+			close(gu.quit)
+			if err := b.Disconnect(p); err != nil {
 				log.Println(err)
 			}
-			if err := b.LeavePlayer(p); err != nil {
+			break
+		}
+
+		raws := [][]byte{buffer}
+		if gu.protocol == JSONProtocol {
+			// a message a line, a read can have some of them
+			raws = bytes.Split(bytes.Trim(buffer, "\x00"), []byte("\n"))
+		}
+		for _, raw := range raws {
+			if err := gu.input(b, p, raw); err != nil {
 				log.Println(err)
+				if err == board.BoardPlayerNotFoundErr {
+					return
+				}
 			}
-			break
 		}
+	}
+}
 
-		command, err := gu.CommandParser(buffer)
-		if err != nil {
+// input translates the json message to the command, the errors go back to the client in the json protocol.
+func (gu *gameUsecaseImpl) input(b board.Board, p player.Player, raw []byte) error {
+	if gu.protocol != JSONProtocol {
+		return gu.Input(b, p, raw)
+	}
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return nil
+	}
+	gu.Lock()
+	command, err := protocol.ParseCommand(raw, gu.lastState)
+	gu.Unlock()
+	if err == nil {
+		err = gu.Input(b, p, []byte(command))
+	}
+	if err != nil && err != board.BoardPlayerNotFoundErr {
+		if err := gu.writeJSON(protocol.NewError(err)); err != nil {
 			log.Println(err)
-			continue
 		}
+	}
+	return err
+}
+
+// Input plays the command typed by the user, or decided by the agent.
+func (gu *gameUsecaseImpl) Input(b board.Board, p player.Player, raw []byte) error {
+	// the board at one moment, the actions check the turn again with the board locked
+	var isResult, isMyTurn, isWaiting, isNextTurn bool
+	if err := b.View(func() error {
 		turnIdx, err := b.MyTurn(p)
 		if err != nil {
+			return err
+		}
+		isResult = b.Result() != nil
+		isMyTurn = b.CurrentTurn() == turnIdx
+		isWaiting = len(b.ActionPlayers()) != 0
+		isNextTurn = b.NextTurn() == turnIdx
+		return nil
+	}); err != nil {
+		return err
+	}
+	if isResult {
+		// any input to go to the next hand
+		return b.Ready(p)
+	}
+
+	command, err := gu.CommandParser(raw)
+	if err != nil {
+		return err
+	}
+	if isMyTurn {
+		// my turn
+		if isWaiting {
+			// waiting for the others
+			return nil
+		}
+		switch command.actionType {
+		case board.Normal:
+			return gu.Normal(b, p, command)
+		case board.Tsumo:
+			return gu.Tsumo(b, p, command)
+		case board.Riichi:
+			return gu.Riichi(b, p, command)
+		case board.Kan:
+			return gu.AnKan(b, p, command)
+		case board.Kakan:
+			return gu.Kakan(b, p, command)
+		}
+		return nil
+	}
+
+	// not my turn
+	if isNextTurn && command.actionType == board.Chii {
+		return gu.Chii(b, p, command)
+	}
+	switch command.actionType {
+	case board.Pon:
+		return gu.Pon(b, p, command)
+	case board.Kan:
+		return gu.MinKan(b, p, command)
+	case board.Ron:
+		return gu.Ron(b, p, command)
+	case board.Cancel:
+		return b.CancelAction(p)
+	}
+	return nil
+}
+
+// SeatAgent seats the agent to the board, it plays without any connection.
+func (gu *gameUsecaseImpl) SeatAgent(id string, p player.Player, a agent.Agent) error {
+	channel, err := gu.JoinBoard(id, p)
+	if err != nil {
+		return err
+	}
+	return gu.AgentController(id, p, channel, a)
+}
+
+// AgentController lets the agent play the seat with the same commands as the user.
+func (gu *gameUsecaseImpl) AgentController(id string, p player.Player, channel chan board.Board, a agent.Agent) error {
+	defer func() {
+		if err := gu.SessionStorage.Remove(gu.token); err != nil {
 			log.Println(err)
-			break
 		}
-		if b.CurrentTurn() == turnIdx {
-			// my turn
-			switch command.actionType {
-			case board.Normal:
-				err = gu.Normal(b, p, command)
-			case board.Tsumo:
-				err = gu.Tsumo(b, p, command)
-			case board.Riichi:
-				err = gu.Riichi(b, p, command)
-			case board.Kan:
-				err = gu.AnKan(b, p, command)
-			default:
-				continue
+		if c, ok := a.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				log.Println(err)
 			}
-		} else {
-			// not my turn
-			if b.NextTurn() == turnIdx && command.actionType == board.Chii {
-				err = gu.Chii(b, p, command)
-			} else {
-				switch command.actionType {
-				case board.Pon:
-					err = gu.Pon(b, p, command)
-				case board.Kan:
-					err = gu.MinKan(b, p, command)
-				case board.Ron:
-					err = gu.Ron(b, p, command)
-				case board.Cancel:
-					err = b.CancelAction(p)
-				default:
-					continue
-				}
+		}
+	}()
+	for b := range channel {
+		// every board in the channel is the same, decide on the latest state
+		for len(channel) != 0 {
+			if _, ok := <-channel; !ok {
+				return nil
 			}
 		}
+		command, err := agent.Decide(a, b, p)
 		if err != nil {
 			log.Println(err)
+			continue
+		}
+		if command == "" {
+			continue
+		}
+		if err := gu.Input(b, p, []byte(command)); err != nil {
+			log.Println(err)
 		}
 	}
+	return nil
 }
 
 func (gu *gameUsecaseImpl) ChiiChoice(b board.Board, p player.Player) (string, error) {
@@ -409,6 +468,18 @@ func (gu *gameUsecaseImpl) AnKanChoice(b board.Board, p player.Player) (string,
 	return str, nil
 }
 
+func (gu *gameUsecaseImpl) KakanChoice(b board.Board, p player.Player) (string, error) {
+	str := ""
+	hais := p.KakanHais()
+	if len(hais) != 0 {
+		str += "\nkakan>> "
+		for i, h := range hais {
+			str += strconv.Itoa(i) + ": (" + h.Name() + ") "
+		}
+	}
+	return str, nil
+}
+
 func (gu *gameUsecaseImpl) RiichiChoice(b board.Board, p player.Player) (string, error) {
 	str := ""
 	ok, err := p.CanRiichi()
@@ -440,99 +511,279 @@ func (gu *gameUsecaseImpl) TsumoAgariChoice(b board.Board, p player.Player) (str
 	return str, nil
 }
 
+func (gu *gameUsecaseImpl) WaitingString(b board.Board, p player.Player) (string, error) {
+	waitingPlayers := b.WaitingPlayers()
+	names := []string{}
+	for _, wp := range waitingPlayers {
+		if wp == p {
+			// the player has to answer
+			return "", nil
+		}
+		name, err := view.SeatName(p, wp, b)
+		if err != nil {
+			return "", err
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return "", nil
+	}
+	return "\nwaiting for " + strings.Join(names, ", ") + " ...", nil
+}
+
+func (gu *gameUsecaseImpl) ThinkingTimeString(b board.Board, p player.Player) (string, error) {
+	base, reserve, err := b.ThinkingTime(p)
+	if err != nil {
+		return "", err
+	}
+	if base == 0 && reserve == 0 {
+		// no time limit
+		return "", nil
+	}
+	return fmt.Sprintf("\ntime %ds + %ds", int(base.Seconds()), int(reserve.Seconds())), nil
+}
+
 func (gu *gameUsecaseImpl) OutputController(id string, p player.Player, channel chan board.Board) error {
 	for {
-		b, ok := <-channel
+		var b board.Board
+		var ok bool
+		select {
+		case b, ok = <-channel:
+		case <-gu.quit:
+			// the channel is handed over to the resumed connection
+			return GameUsecaseDisconnectedErr
+		}
 		if !ok {
 			return GameUsecaseBoardChannelClosedErr
 		}
 
-		winner := b.Winner()
-		if winner != nil {
-			str := "GAME SET!!\n"
-
-			str = view.TehaiOpen(winner).String()
-			if err := gu.write(str); err != nil {
-				log.Println(err)
-			}
-			if err := gu.BoardStorage.Remove(id); err != nil {
-				log.Println(err)
-			}
-			if err := b.LeavePlayer(p); err != nil {
-				log.Println(err)
-			}
+		// the match can end while the board is written
+		var isEnd bool
+		if err := b.View(func() error {
+			isEnd = b.Result() != nil && b.Match().IsEnd()
 			return nil
+		}); err != nil {
+			return err
+		}
+		var err error
+		if gu.protocol == JSONProtocol {
+			err = gu.JSONOutput(id, p, b, isEnd)
+		} else {
+			err = gu.TextOutput(id, p, b, isEnd)
+		}
+		if !isEnd {
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			log.Println(err)
 		}
+		if err := gu.BoardStorage.Remove(id); err != nil {
+			log.Println(err)
+		}
+		if err := gu.SessionStorage.Remove(gu.token); err != nil {
+			log.Println(err)
+		}
+		if err := b.LeavePlayer(p); err != nil {
+			log.Println(err)
+		}
+		return nil
+	}
+}
+
+// TextOutput writes the board seen from the player with the choices, the result of the match when isEnd.
+// the screen is made with the board locked, the timers and the other players change it.
+func (gu *gameUsecaseImpl) TextOutput(id string, p player.Player, b board.Board, isEnd bool) error {
+	// the thinking time takes the lock by itself
+	thinking, err := gu.ThinkingTimeString(b, p)
+	if err != nil {
+		return err
+	}
+	var str string
+	if err := b.View(func() error {
+		var err error
+		str, err = gu.textScreen(id, p, b, isEnd, thinking)
+		return err
+	}); err != nil {
+		return err
+	}
+	return gu.write(str)
+}
 
-		str, err := view.BoardString(p, b)
+func (gu *gameUsecaseImpl) textScreen(id string, p player.Player, b board.Board, isEnd bool, thinking string) (string, error) {
+	if b.Result() != nil {
+		str, err := view.ResultString(p, b)
 		if err != nil {
-			return err
+			return "", err
 		}
-		turnIdx, err := b.MyTurn(p)
+		if !isEnd {
+			return str + thinking + "\nnext>> ", nil
+		}
+		str += view.MatchResultString(p, b)
+		// the paifu is found by the id
+		str += "board id: " + id + "\n"
+		return str, nil
+	}
+
+	str, err := view.BoardString(p, b)
+	if err != nil {
+		return "", err
+	}
+	turnIdx, err := b.MyTurn(p)
+	if err != nil {
+		return "", err
+	}
+
+	if b.CurrentTurn() == turnIdx {
+		// my turn
+
+		// ankan
+		ankan, err := gu.AnKanChoice(b, p)
 		if err != nil {
-			return err
+			return "", err
 		}
+		str += ankan
 
-		if b.CurrentTurn() == turnIdx {
-			// my turn
+		// kakan
+		kakan, err := gu.KakanChoice(b, p)
+		if err != nil {
+			return "", err
+		}
+		str += kakan
 
-			// ankan
-			ankan, err := gu.AnKanChoice(b, p)
-			if err != nil {
-				return err
-			}
-			str += ankan
+		// riichi
+		riichi, err := gu.RiichiChoice(b, p)
+		if err != nil {
+			return "", err
+		}
+		str += riichi
+		// tsumo agari
+		tsumo, err := gu.TsumoAgariChoice(b, p)
+		if err != nil {
+			return "", err
+		}
+		str += tsumo
 
-			// riichi
-			riichi, err := gu.RiichiChoice(b, p)
-			if err != nil {
-				return err
+	} else {
+		// not my turn
+		// naki
+		actions, err := b.MyAction(p)
+		if err != nil {
+			return "", err
+		}
+
+		for _, action := range actions {
+			choice := ""
+			switch action {
+			case board.Chii:
+				choice, err = gu.ChiiChoice(b, p)
+			case board.Pon:
+				choice, err = gu.PonChoice(b, p)
+			case board.Kan:
+				choice, err = gu.MinKanChoice(b, p)
+			case board.Ron:
+				choice = "\nron>> "
 			}
-			str += riichi
-			// tsumo agari
-			tsumo, err := gu.TsumoAgariChoice(b, p)
 			if err != nil {
-				return err
+				return "", err
 			}
-			str += tsumo
+			str += choice
+		}
+	}
 
-		} else {
-			// not my turn
-			// naki
-			actions, err := b.MyAction(p)
+	// waiting for the calls
+	waiting, err := gu.WaitingString(b, p)
+	if err != nil {
+		return "", err
+	}
+	str += waiting
+
+	// thinking time
+	str += thinking
+
+	str += "\n"
+
+	if len(b.ActionPlayers()) == 0 && b.CurrentTurn() == turnIdx {
+		str += ">>"
+	}
+
+	return str, nil
+}
+
+// JSONOutput writes the messages of the json protocol, the result of the hand and the state after it.
+// the same messages as the last are not sent again.
+func (gu *gameUsecaseImpl) JSONOutput(id string, p player.Player, b board.Board, isEnd bool) error {
+	messages := []interface{}{}
+	// the result and the match end are read with the board locked, the state locks it by itself
+	if err := b.View(func() error {
+		if b.Result() != nil {
+			result, err := protocol.NewResult(b, p)
 			if err != nil {
 				return err
 			}
-
-			for _, action := range actions {
-				choice := ""
-				switch action {
-				case board.Chii:
-					choice, err = gu.ChiiChoice(b, p)
-				case board.Pon:
-					choice, err = gu.PonChoice(b, p)
-				case board.Kan:
-					choice, err = gu.MinKanChoice(b, p)
-				case board.Ron:
-					choice = "\nron>> "
-				}
-				if err != nil {
-					return err
-				}
-				str += choice
+			messages = append(messages, result)
+		}
+		if isEnd {
+			matchEnd, err := protocol.NewMatchEnd(b, p, id)
+			if err != nil {
+				return err
 			}
+			messages = append(messages, matchEnd)
 		}
-
-		str += "\n"
-
-		if len(b.ActionPlayers()) == 0 && b.CurrentTurn() == turnIdx {
-			str += ">>"
+		return nil
+	}); err != nil {
+		return err
+	}
+	var state *protocol.State
+	if !isEnd {
+		var err error
+		if state, err = protocol.NewState(b, p); err != nil {
+			return err
 		}
+		messages = append(messages, state)
+	}
 
-		if err := gu.write(str); err != nil {
+	gu.Lock()
+	defer gu.Unlock()
+	str, err := jsonLines(messages)
+	if err != nil || str == gu.lastMessages {
+		return err
+	}
+	gu.lastMessages = str
+	if state != nil {
+		if gu.lastState != nil {
+			state.Seq = gu.lastState.Seq
+		}
+		state.Seq++
+		gu.lastState = state
+		if str, err = jsonLines(messages); err != nil {
 			return err
 		}
 	}
+	return gu.write(str)
+}
+
+func (gu *gameUsecaseImpl) writeJSON(v interface{}) error {
+	str, err := jsonLines([]interface{}{v})
+	if err != nil {
+		return err
+	}
+	return gu.write(str)
+}
+
+// jsonLines is the messages a line each.
+func jsonLines(messages []interface{}) (string, error) {
+	str := ""
+	for _, m := range messages {
+		raw, err := json.Marshal(m)
+		if err != nil {
+			return "", err
+		}
+		str += string(raw) + "\n"
+	}
+	return str, nil
 }
 
 func (gu *gameUsecaseImpl) JoinBoard(id string, c player.Player) (chan board.Board, error) {
@@ -541,5 +792,76 @@ func (gu *gameUsecaseImpl) JoinBoard(id string, c player.Player) (chan board.Boa
 		return nil, err
 	}
 
-	return b.JoinPlayer(c)
+	channel, err := b.JoinPlayer(c)
+	if err != nil {
+		return nil, err
+	}
+
+	token := utils.NewUUID().String()
+	if err := gu.SessionStorage.Add(token, &storage.Session{BoardID: id, Player: c}); err != nil {
+		return nil, err
+	}
+	gu.token = token
+	if gu.protocol == JSONProtocol {
+		return channel, gu.writeJSON(protocol.NewSession(token))
+	}
+	if err := gu.write("session token: " + token + "\ntype `resume " + token + "` on a new connection to get back to the table\n"); err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
+
+// Entrance reads the first input of the connection, split into words.
+func (gu *gameUsecaseImpl) Entrance() ([]string, error) {
+	message := lobbyMessage + ">> "
+	if err := gu.write(message); err != nil {
+		return nil, err
+	}
+	buffer := make([]byte, 1024)
+	if err := gu.read(buffer); err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(bytes.Trim(buffer, "\x00"))), nil
+}
+
+// Negotiate switches to the json protocol when the first word is `json`, and returns the rest of the words.
+func (gu *gameUsecaseImpl) Negotiate(fields []string) ([]string, Protocol, error) {
+	if len(fields) == 0 || fields[0] != string(JSONProtocol) {
+		return fields, gu.protocol, nil
+	}
+	gu.protocol = JSONProtocol
+	return fields[1:], gu.protocol, gu.writeJSON(protocol.NewHello())
+}
+
+// Resume re-attaches the player of the session token to the board.
+// The player is nil when the session is not found and the user starts a new game.
+func (gu *gameUsecaseImpl) Resume(token string) (string, player.Player, chan board.Board, error) {
+	s, err := gu.SessionStorage.Find(token)
+	if err != nil {
+		log.Println(err)
+		if gu.protocol == JSONProtocol {
+			return "", nil, nil, gu.writeJSON(protocol.NewError(GameUsecaseSessionNotFoundErr))
+		}
+		return "", nil, nil, gu.write("the session not found, start a new game\n")
+	}
+	b, err := gu.BoardStorage.Find(s.BoardID)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	channel, err := b.Reconnect(s.Player)
+	if err == board.BoardPlayerConnectedErr {
+		// the user is told before the connection is closed
+		werr := gu.write(err.Error() + "\n")
+		if gu.protocol == JSONProtocol {
+			werr = gu.writeJSON(protocol.NewError(err))
+		}
+		if werr != nil {
+			log.Println(werr)
+		}
+	}
+	if err != nil {
+		return "", nil, nil, err
+	}
+	gu.token = token
+	return s.BoardID, s.Player, channel, nil
 }
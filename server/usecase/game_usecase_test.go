@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"io"
+	"mahjong/model/board"
+	"mahjong/model/kawa"
+	"mahjong/model/match"
+	"mahjong/model/naki"
+	"mahjong/model/player"
+	"mahjong/model/tehai"
+	"mahjong/model/yama"
+	"mahjong/storage"
+	"mahjong/utils"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInputController(t *testing.T) {
+	bs := storage.NewBoardStorage()
+	ss := storage.NewSessionStorage()
+	read := func([]byte) error { return io.EOF }
+	gu := NewGameUsecase(bs, ss, func(string) error { return nil }, read)
+	p := player.New(utils.NewUUID(), kawa.New(), tehai.New(), naki.New())
+	// no board of the id, it returns without reading
+	gu.InputController("none", p)
+}
+
+func TestOutputController(t *testing.T) {
+	cases := []struct {
+		name       string
+		inProtocol Protocol
+		outLast    string
+	}{
+		{
+			name:       "success: text",
+			inProtocol: TextProtocol,
+			outLast:    "board id: test",
+		},
+		{
+			name:       "success: json",
+			inProtocol: JSONProtocol,
+			outLast:    `"type":"match_end"`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// nobody answers, the timers play the whole match while the screens are written
+			rule := *match.Tonpuusen
+			rule.BaseTime = time.Millisecond
+			rule.ReserveTime = 0
+			id := "test"
+			bs := storage.NewBoardStorage()
+			ss := storage.NewSessionStorage()
+			m := match.New(&rule, board.MaxNumberOfUsers)
+			assert.NoError(t, bs.Add(id, board.New(board.MaxNumberOfUsers, m, 1, yama.NewWithRand, nil)))
+
+			outs := make([][]string, board.MaxNumberOfUsers)
+			errs := make(chan error, board.MaxNumberOfUsers)
+			for i := 0; i < board.MaxNumberOfUsers; i++ {
+				i := i
+				write := func(mess string) error {
+					outs[i] = append(outs[i], mess)
+					return nil
+				}
+				gu := NewGameUsecase(bs, ss, write, func([]byte) error { return nil }).(*gameUsecaseImpl)
+				gu.protocol = c.inProtocol
+				p := player.New(utils.NewUUID(), kawa.New(), tehai.New(), naki.New())
+				channel, err := gu.JoinBoard(id, p)
+				assert.NoError(t, err)
+				go func() { errs <- gu.OutputController(id, p, channel) }()
+			}
+			for i := 0; i < board.MaxNumberOfUsers; i++ {
+				assert.NoError(t, <-errs)
+			}
+			for _, out := range outs {
+				assert.True(t, strings.Contains(out[len(out)-1], c.outLast))
+			}
+		})
+	}
+}
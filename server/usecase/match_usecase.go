@@ -1,65 +1,350 @@
 package usecase
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
 	"mahjong/model/board"
+	"mahjong/model/match"
+	"mahjong/model/protocol"
+	"mahjong/storage"
 	"mahjong/utils"
 	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/k-jun/northpole"
 	"github.com/k-jun/northpole/room"
-	"github.com/k-jun/northpole/storage"
+	npstorage "github.com/k-jun/northpole/storage"
 	"github.com/k-jun/northpole/user"
 )
 
 type MatchUsecase interface {
-	JoinRandomRoom(user.User) (string, error)
+	Lobby(user.User, []string) (string, []string, error)
+	JoinRandomRoom(user.User, bool) (string, error)
+	JoinRoom(user.User, room.Room) (chan room.Room, error)
+	SetProtocol(Protocol)
+	// the nickname set in the lobby, empty for no name
+	Name() string
 }
 
 type matchUsecaseImpl struct {
-	matches  northpole.Match
+	matches northpole.Match
+	// the private rooms, apart from the matches not to be joined by the quick match
+	privates northpole.Match
+	lobbies  storage.LobbyStorage
 	write    func(string) error
 	read     func([]byte) error
-	callback func(string) error
+	callback func(string, *match.Rule) error
+	// fill seats the bots to the empty seats of the room
+	fill func(room.Room)
+	// the bots fill the room after the wait, never when it is 0
+	botWait time.Duration
+	// the format of the messages on the connection
+	protocol Protocol
+	name     string
+	// the commands read in the json protocol and not run yet
+	pending []string
 }
 
-func NewMatchUsecase(matches northpole.Match, write func(string) error, read func([]byte) error, callback func(string) error) MatchUsecase {
+var (
+	// the rule of the rooms of the quick match
+	QuickRule = match.Tonpuusen
+	// the length of the join code of a private room
+	CodeLength = 6
+	// the max length of the nickname
+	MaxNameLength = 16
+
+	lobbyMessage = "press enter to play, type `bot` to play with bots at once, `resume <token>` to get back to the table,\n" +
+		"`replay <board id>` to step through a recorded match, put `json` first for the json protocol.\n" +
+		"in the lobby, `name <nickname>` names you, `rooms` lists the open rooms,\n" +
+		"`create [tonpuusen|hanchan] [bots]` makes a private room, the bots fill it after the wait with `bots`,\n" +
+		"and `join <code>` joins the private room of the code\n"
+)
+
+func NewMatchUsecase(matches northpole.Match, privates northpole.Match, lobbies storage.LobbyStorage, write func(string) error, read func([]byte) error, callback func(string, *match.Rule) error, fill func(room.Room), botWait time.Duration) MatchUsecase {
 	return &matchUsecaseImpl{
 		matches:  matches,
+		privates: privates,
+		lobbies:  lobbies,
 		read:     read,
 		write:    write,
 		callback: callback,
+		fill:     fill,
+		botWait:  botWait,
+		protocol: TextProtocol,
 	}
 
 }
 
-func (uc *matchUsecaseImpl) JoinRandomRoom(u user.User) (string, error) {
-	rc, err := uc.matches.JoinRandomRoom(u)
-	if err != nil {
-		if err == storage.RoomStorageRoomNotFound {
-			rc, err = uc.CreateRoom(u)
+// Lobby runs the commands of the lobby from the fields of the first line until the user joins a room.
+// The room id is empty when the fields are the command of the handler, resume or replay, and they are returned.
+func (uc *matchUsecaseImpl) Lobby(u user.User, fields []string) (string, []string, error) {
+	for {
+		if len(fields) == 2 && (fields[0] == "resume" || fields[0] == "replay") {
+			return "", fields, nil
+		}
+		roomID, err := uc.command(u, fields)
+		if err != nil {
+			return "", nil, err
+		}
+		if roomID != "" {
+			return roomID, nil, nil
+		}
+		if fields, err = uc.readFields(); err != nil {
+			return "", nil, err
+		}
+	}
+}
+
+// command runs a command of the lobby, the room id is empty while the user stays in the lobby.
+func (uc *matchUsecaseImpl) command(u user.User, fields []string) (string, error) {
+	if len(fields) == 0 {
+		return uc.JoinRandomRoom(u, false)
+	}
+	args := fields[1:]
+	switch fields[0] {
+	case "quick":
+		return uc.JoinRandomRoom(u, false)
+	case "bot":
+		return uc.JoinRandomRoom(u, true)
+	case "name":
+		if len(args) != 1 || utf8.RuneCountInString(args[0]) > MaxNameLength {
+			return "", uc.lobbyError(MatchUsecaseInvalidNameErr)
+		}
+		uc.name = args[0]
+		if uc.protocol == JSONProtocol {
+			return "", uc.writeJSON(protocol.NewName(uc.name))
+		}
+		return "", uc.write("you are " + uc.name + "\n>> ")
+	case "rooms":
+		return "", uc.writeRooms()
+	case "create":
+		rule, withBots, err := parseRoomRule(args)
+		if err != nil {
+			return "", uc.lobbyError(err)
+		}
+		return uc.CreatePrivateRoom(u, rule, withBots)
+	case "join":
+		if len(args) != 1 {
+			return "", uc.lobbyError(MatchUsecaseRoomNotFoundErr)
+		}
+		return uc.JoinPrivateRoom(u, strings.ToUpper(args[0]))
+	}
+	if uc.protocol == JSONProtocol {
+		return "", uc.lobbyError(MatchUsecaseInvalidCommandErr)
+	}
+	return "", uc.write(lobbyMessage + ">> ")
+}
+
+// parseRoomRule reads the settings of a private room, the rule name and `bots` in any order.
+func parseRoomRule(args []string) (*match.Rule, bool, error) {
+	rule := QuickRule
+	withBots := false
+	for _, arg := range args {
+		if arg == "bots" {
+			withBots = true
+			continue
+		}
+		r, err := match.AtoRule(arg)
+		if err != nil {
+			return nil, false, MatchUsecaseInvalidRuleErr
+		}
+		rule = r
+	}
+	return rule, withBots, nil
+}
+
+// readFields reads the next command of the lobby, a json command in the json protocol.
+func (uc *matchUsecaseImpl) readFields() ([]string, error) {
+	for len(uc.pending) == 0 {
+		buffer := make([]byte, 1024)
+		if err := uc.read(buffer); err != nil {
+			return nil, err
+		}
+		raw := bytes.Trim(buffer, "\x00")
+		if uc.protocol != JSONProtocol {
+			return strings.Fields(string(raw)), nil
+		}
+		for _, line := range bytes.Split(raw, []byte("\n")) {
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			command, err := protocol.ParseCommand(line, nil)
 			if err != nil {
-				return "", err
+				if err := uc.writeJSON(protocol.NewError(err)); err != nil {
+					return nil, err
+				}
+				continue
 			}
+			uc.pending = append(uc.pending, command)
+		}
+	}
+	command := uc.pending[0]
+	uc.pending = uc.pending[1:]
+	return strings.Fields(command), nil
+}
+
+// lobbyError tells the error of the command, the user stays in the lobby.
+func (uc *matchUsecaseImpl) lobbyError(err error) error {
+	if uc.protocol == JSONProtocol {
+		return uc.writeJSON(protocol.NewError(err))
+	}
+	return uc.write(err.Error() + "\n>> ")
+}
+
+func (uc *matchUsecaseImpl) writeRooms() error {
+	rooms := []*protocol.LobbyRoom{}
+	for _, r := range uc.lobbies.List() {
+		rooms = append(rooms, &protocol.LobbyRoom{
+			Rule:      r.Rule.Name,
+			Current:   r.Room.CurrentNumberOfUsers(),
+			Max:       r.Room.MaxNumberOfUsers(),
+			Names:     r.Names,
+			IsPrivate: r.Code != "",
+		})
+	}
+	if uc.protocol == JSONProtocol {
+		return uc.writeJSON(protocol.NewRooms(rooms))
+	}
+	if len(rooms) == 0 {
+		return uc.write("no open rooms, press enter to make one\n>> ")
+	}
+	message := ""
+	for i, r := range rooms {
+		message += fmt.Sprintf("%d %-9s %d/%d %s", i+1, r.Rule, r.Current, r.Max, strings.Join(r.Names, ", "))
+		if r.IsPrivate {
+			message += " (private)"
 		}
+		message += "\n"
+	}
+	return uc.write(message + ">> ")
+}
+
+func (uc *matchUsecaseImpl) Name() string {
+	return uc.name
+}
+
+// lobbyName is the name listed in the lobby.
+func (uc *matchUsecaseImpl) lobbyName() string {
+	if uc.name == "" {
+		return "guest"
+	}
+	return uc.name
+}
+
+// JoinRandomRoom waits for the room to be full, withBots fills it with the bots at once.
+func (uc *matchUsecaseImpl) JoinRandomRoom(u user.User, withBots bool) (string, error) {
+	rc, err := uc.matches.JoinRandomRoom(u)
+	if err == npstorage.RoomStorageRoomNotFound {
+		rc, err = uc.CreateRoom(u)
+	}
+	if err != nil {
+		return "", err
+	}
+	return uc.wait(u, rc, withBots, uc.botWait != 0)
+}
+
+// CreatePrivateRoom makes a room of the rule joined by the code, withBots fills it with the bots after the wait.
+func (uc *matchUsecaseImpl) CreatePrivateRoom(u user.User, rule *match.Rule, withBots bool) (string, error) {
+	code, err := utils.NewCode(CodeLength)
+	if err != nil {
+		return "", err
+	}
+	r := room.New(utils.NewUUID().String(), board.MaxNumberOfUsers, uc.boardCallback(rule))
+	if err := uc.lobbies.Add(&storage.LobbyRoom{Room: r, Rule: rule, Code: code}); err != nil {
+		return "", err
+	}
+	rc, err := uc.privates.CreateRoom(u, r)
+	if err != nil {
+		return "", err
+	}
+	if uc.protocol == JSONProtocol {
+		err = uc.writeJSON(protocol.NewPrivate(code, rule.Name))
+	} else {
+		err = uc.write("private room code: " + code + " (" + rule.Name + "), the others type `join " + code + "` to play with you\n")
+	}
+	if err != nil {
+		return "", err
+	}
+	return uc.wait(u, rc, false, withBots && uc.botWait != 0)
+}
+
+// JoinPrivateRoom joins the private room of the code and waits for it to be full.
+func (uc *matchUsecaseImpl) JoinPrivateRoom(u user.User, code string) (string, error) {
+	r, err := uc.lobbies.Find(code)
+	if err != nil {
+		return "", uc.lobbyError(MatchUsecaseRoomNotFoundErr)
+	}
+	rc, err := uc.privates.JoinRoom(u, r.Room)
+	if err != nil {
+		// the room is full or closed after it was found
+		log.Println(err)
+		return "", uc.lobbyError(MatchUsecaseRoomNotFoundErr)
 	}
+	return uc.wait(u, rc, false, false)
+}
 
+// wait waits for the room to be full, the bots fill it at once withBots, or after the wait with fillsLater.
+func (uc *matchUsecaseImpl) wait(u user.User, rc chan room.Room, withBots bool, fillsLater bool) (string, error) {
 	room, _ := <-rc
+	if err := uc.lobbies.Join(room.ID(), u.ID(), uc.lobbyName()); err != nil && err != storage.LobbyStorageNotExistErr {
+		return "", err
+	}
 	go uc.deadCheck(u, room)
-	if err := uc.write(roomStatus(room)); err != nil {
+	if err := uc.writeStatus(room); err != nil {
 		return "", err
 	}
+	switch {
+	case withBots:
+		uc.fill(room)
+	case fillsLater:
+		timer := time.AfterFunc(uc.botWait, func() { uc.fill(room) })
+		defer timer.Stop()
+	}
 
 	for {
 		_, isOpen := <-rc
 		if !isOpen {
 			return room.ID(), nil
 		}
-		if err := uc.write(roomStatus(room)); err != nil {
+		if err := uc.writeStatus(room); err != nil {
 			return "", err
 		}
 	}
 }
 
+// SetProtocol sets the format of the messages negotiated by the game usecase.
+func (uc *matchUsecaseImpl) SetProtocol(p Protocol) {
+	uc.protocol = p
+}
+
+func (uc *matchUsecaseImpl) writeStatus(r room.Room) error {
+	if uc.protocol != JSONProtocol {
+		return uc.write(roomStatus(r))
+	}
+	return uc.writeJSON(protocol.NewRoom(r.CurrentNumberOfUsers(), r.MaxNumberOfUsers()))
+}
+
+func (uc *matchUsecaseImpl) writeJSON(v interface{}) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return uc.write(string(raw) + "\n")
+}
+
+// JoinRoom joins the room, quick or private, the channel is closed when it is full.
+func (uc *matchUsecaseImpl) JoinRoom(u user.User, r room.Room) (chan room.Room, error) {
+	rc, err := uc.matches.JoinRoom(u, r)
+	if err == npstorage.RoomStorageRoomNotFound {
+		rc, err = uc.privates.JoinRoom(u, r)
+	}
+	return rc, err
+}
+
 func (uc *matchUsecaseImpl) deadCheck(u user.User, room room.Room) {
 	for {
 		// if it's read, first input from user was read in here
@@ -67,7 +352,7 @@ func (uc *matchUsecaseImpl) deadCheck(u user.User, room room.Room) {
 		if err := uc.write(""); err != nil {
 			if room != nil {
 				// connection end
-				uc.matches.LeaveRoom(u, room)
+				uc.leave(u, room)
 			}
 			break
 		}
@@ -77,12 +362,33 @@ func (uc *matchUsecaseImpl) deadCheck(u user.User, room room.Room) {
 	}
 }
 
+// leave takes the user out of the room, quick or private.
+func (uc *matchUsecaseImpl) leave(u user.User, r room.Room) {
+	uc.lobbies.Leave(r.ID(), u.ID())
+	if err := uc.matches.LeaveRoom(u, r); err != npstorage.RoomStorageRoomNotFound {
+		return
+	}
+	if err := uc.privates.LeaveRoom(u, r); err != nil {
+		log.Println(err)
+	}
+}
+
 func (uc *matchUsecaseImpl) CreateRoom(u user.User) (chan room.Room, error) {
 	newId := utils.NewUUID()
-	newRoom := room.New(newId.String(), board.MaxNumberOfUsers, uc.callback)
+	newRoom := room.New(newId.String(), board.MaxNumberOfUsers, uc.boardCallback(QuickRule))
+	if err := uc.lobbies.Add(&storage.LobbyRoom{Room: newRoom, Rule: QuickRule}); err != nil {
+		return nil, err
+	}
 	return uc.matches.CreateRoom(u, newRoom)
 }
 
+// boardCallback makes the board of the rule when the room is full.
+func (uc *matchUsecaseImpl) boardCallback(rule *match.Rule) func(string) error {
+	return func(id string) error {
+		return uc.callback(id, rule)
+	}
+}
+
 func roomStatus(r room.Room) string {
 	message := "current number of users : " + strconv.Itoa(r.CurrentNumberOfUsers()) + "\n"
 	message += "max number of users     : " + strconv.Itoa(r.MaxNumberOfUsers()) + "\n"
@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mahjong/model/paifu"
+	"mahjong/model/replay"
+	"mahjong/model/view"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type ReplayUsecase interface {
+	// Replay steps through the paifu of the board id
+	Replay(string) error
+	// Play steps through the events by the commands of the user
+	Play([]*paifu.Event) error
+}
+
+type replayUsecaseImpl struct {
+	paifuDir string
+	write    func(string) error
+	read     func([]byte) error
+}
+
+var (
+	replayHelp = "enter or `n` next, `b` back, `h <hand>` jump to the hand, `j <step>` jump to the step,\n" +
+		"`s <seat>` see from the seat, `o` open all the hands, `q` quit\n"
+)
+
+func NewReplayUsecase(paifuDir string, write func(string) error, read func([]byte) error) ReplayUsecase {
+	return &replayUsecaseImpl{
+		paifuDir: paifuDir,
+		write:    write,
+		read:     read,
+	}
+}
+
+func (ru *replayUsecaseImpl) Replay(id string) error {
+	// only the files in the directory
+	if ru.paifuDir == "" || id == "" || filepath.Base(id) != id {
+		return ru.write("the paifu not found\n")
+	}
+	events, err := paifu.ReadFile(paifu.Path(ru.paifuDir, id))
+	if err != nil {
+		return ru.write("the paifu not found\n")
+	}
+	return ru.Play(events)
+}
+
+func (ru *replayUsecaseImpl) Play(events []*paifu.Event) error {
+	r, err := replay.New(events)
+	if err != nil {
+		return err
+	}
+	seat, isOpen := 0, false
+	message := replayHelp
+	for {
+		str, err := ru.String(r, seat, isOpen)
+		if err != nil {
+			return err
+		}
+		if err := ru.write(str + message + "replay>> "); err != nil {
+			return err
+		}
+		message = ""
+
+		buffer := make([]byte, 1024)
+		if err := ru.read(buffer); err != nil {
+			return err
+		}
+		fields := strings.Fields(string(bytes.Trim(buffer, "\x00")))
+		command, arg := "n", -1
+		if len(fields) != 0 {
+			command = fields[0]
+		}
+		if len(fields) > 1 {
+			if arg, err = strconv.Atoi(fields[1]); err != nil {
+				arg = -1
+			}
+		}
+
+		switch command {
+		case "n":
+			err = r.Forward()
+		case "b":
+			err = r.Back()
+		case "h":
+			if arg < 1 || arg > len(r.Hands()) {
+				err = replay.ReplayOutOfRangeErr
+				break
+			}
+			err = r.Jump(r.Hands()[arg-1])
+		case "j":
+			err = r.Jump(arg)
+		case "s":
+			if arg < 0 || arg >= len(r.Board().Players()) {
+				err = replay.ReplayOutOfRangeErr
+				break
+			}
+			seat = arg
+		case "o":
+			isOpen = !isOpen
+		case "q":
+			return nil
+		default:
+			message = replayHelp
+		}
+		if err == replay.ReplayOutOfRangeErr {
+			message = err.Error() + "\n"
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// String is the board seen from the seat after the step, with the event of the step.
+func (ru *replayUsecaseImpl) String(r replay.Replay, seat int, isOpen bool) (string, error) {
+	b := r.Board()
+	p := b.Players()[seat].Player
+	str := ""
+	var err error
+	switch {
+	case b.Result() != nil:
+		str, err = view.ResultString(p, b)
+	case isOpen:
+		str, err = view.OpenBoardString(p, b)
+	default:
+		str, err = view.BoardString(p, b)
+	}
+	if err != nil {
+		return "", err
+	}
+	e := r.Events()[r.Step()]
+	bytes, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	hand := 0
+	for i, h := range r.Hands() {
+		if h <= r.Step() {
+			hand = i + 1
+		}
+	}
+	str += fmt.Sprintf("seat %d  hand %d/%d  step %d/%d\n%s\n", seat, hand, len(r.Hands()), r.Step(), len(r.Events())-1, string(bytes))
+	return str, nil
+}
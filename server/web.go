@@ -0,0 +1,75 @@
+package server
+
+import (
+	"io"
+	"log"
+	"mahjong/utils"
+	"net/http"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// runWeb serves the browser client at / and its websocket at /ws.
+func (s *serverImpl) runWeb() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if _, err := io.WriteString(w, webPage); err != nil {
+			log.Println(err)
+		}
+	})
+	mux.Handle("/ws", websocket.Server{Handler: s.serveWebSocket, Handshake: s.checkOrigin})
+	log.Fatal(http.Serve(s.webListener, mux))
+}
+
+// checkOrigin refuses the websocket opened by the page of the other sites, the browser of a visitor plays for them otherwise.
+func (s *serverImpl) checkOrigin(config *websocket.Config, r *http.Request) error {
+	origin, err := websocket.Origin(config, r)
+	if err != nil {
+		return err
+	}
+	if origin == nil {
+		return WebOriginErr
+	}
+	host := s.webHost
+	if host == "" {
+		host = r.Host
+	}
+	if origin.Host != host {
+		return WebOriginErr
+	}
+	return nil
+}
+
+// serveWebSocket plays the connection of the browser, a write is a message and a message is a read.
+func (s *serverImpl) serveWebSocket(ws *websocket.Conn) {
+	// Write sends a ping frame, the messages are sent as the text frames apart from it
+	ws.PayloadType = websocket.PingFrame
+	write := func(mess string) error {
+		if mess == "" {
+			// the dead check of the match usecase writes nothing in a loop, a ping a second
+			time.Sleep(time.Second)
+			_, err := ws.Write(nil)
+			return err
+		}
+		return websocket.Message.Send(ws, mess)
+	}
+	read := func(buffer []byte) error {
+		mess := ""
+		if err := websocket.Message.Receive(ws, &mess); err != nil {
+			return err
+		}
+		copy(buffer, mess)
+		return nil
+	}
+	close := func() error {
+		return ws.Close()
+	}
+	// the connection is closed when it returns
+	s.handler(utils.NewUUID(), write, read, close).Run()
+}
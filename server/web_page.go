@@ -0,0 +1,173 @@
+package server
+
+// webPage is the browser client, it plays on the json protocol over the websocket.
+var webPage = `<!doctype html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>cli-mahjong</title>
+<style>
+body { font-family: monospace; background: #0b3d2e; color: #eee; margin: 1em; }
+button { font-family: monospace; font-size: 1em; margin: 2px; min-width: 2.4em; }
+.hai { display: inline-block; border: 1px solid #ccc; background: #f8f6ee; color: #111; padding: 2px 4px; margin: 1px; border-radius: 3px; }
+.seat { margin: .4em 0; padding: .3em; border: 1px solid #2f6f57; }
+.turn { border-color: #ffd54f; }
+.riichi { color: #ff8a80; }
+#result { margin: .5em 0; color: #ffd54f; }
+#log { color: #ff8a80; }
+</style>
+</head>
+<body>
+<div id="entrance">
+<input id="name" placeholder="nickname" size="16">
+<button onclick="command('name ' + el('name').value)">name</button>
+<div id="rooms"></div>
+<button onclick="command('rooms')">refresh</button>
+<button onclick="command('')">play</button>
+<button onclick="command('bot')">play with bots</button>
+<br>
+<select id="rule"><option>tonpuusen</option><option>hanchan</option></select>
+<label><input id="bots" type="checkbox">bots</label>
+<button onclick="command('create ' + el('rule').value + (el('bots').checked ? ' bots' : ''))">create private room</button>
+<input id="code" placeholder="code" size="8">
+<button onclick="command('join ' + el('code').value)">join</button>
+<br>
+<input id="token" placeholder="session token" size="38">
+<button onclick="command('resume ' + el('token').value)">resume</button>
+</div>
+<div id="status"></div>
+<div id="table"></div>
+<div id="result"></div>
+<div id="actions"></div>
+<div id="log"></div>
+<script>
+var ws, seq = 0, seatNames = ["you", "shimocha", "toimen", "kamicha"];
+
+function el(id) { return document.getElementById(id); }
+
+// escape makes the nickname typed by the user the text in the html
+function escape(str) {
+  var d = document.createElement("div");
+  d.textContent = str;
+  return d.innerHTML;
+}
+
+function hais(names) {
+  return names.map(function (n) { return '<span class="hai">' + n + '</span>'; }).join("");
+}
+
+// the lobby is open on the load, the buttons send its commands
+function connect() {
+  ws = new WebSocket((location.protocol == "https:" ? "wss://" : "ws://") + location.host + "/ws");
+  ws.onopen = function () { ws.send("json rooms"); };
+  ws.onmessage = function (e) {
+    // the greeting is text, the messages are json a line
+    e.data.split("\n").forEach(function (line) {
+      if (line.charAt(0) == "{") handle(JSON.parse(line));
+    });
+  };
+  ws.onclose = function () { el("status").textContent += " (disconnected)"; };
+}
+
+function command(c) {
+  el("log").textContent = "";
+  ws.send(JSON.stringify({type: "command", command: c}));
+}
+
+function rooms(m) {
+  var box = el("rooms");
+  box.innerHTML = "";
+  m.rooms.forEach(function (r) {
+    var d = document.createElement("div");
+    d.textContent = r.rule + " " + r.current + "/" + r.max + " " + r.names.join(", ") + (r.is_private ? " (private)" : "");
+    box.appendChild(d);
+  });
+  if (!m.rooms.length) box.textContent = "no open rooms";
+}
+
+function handle(m) {
+  if (m.type == "room" || m.type == "session" || m.type == "state") el("entrance").style.display = "none";
+  switch (m.type) {
+  case "hello": el("status").textContent = "protocol version " + m.version; break;
+  case "name": el("status").textContent = "you are " + m.name; break;
+  case "rooms": rooms(m); break;
+  case "private": el("status").textContent = "private room code: " + m.code + " (" + m.rule + ")"; break;
+  case "room": el("result").textContent = "waiting for the players " + m.current + "/" + m.max; break;
+  case "session": el("status").textContent += ", session token: " + m.token; break;
+  case "state": state(m); break;
+  case "result": result(m); break;
+  case "match_end": matchEnd(m); break;
+  case "error": el("log").textContent = m.message; break;
+  }
+}
+
+function state(m) {
+  seq = m.seq;
+  var s = m.state;
+  var str = "<div>" + s.bakaze + " " + s.kyoku + " kyoku " + s.honba + " honba, kyoutaku " + s.kyoutaku + ", dora " + hais(s.dora) + "</div>";
+  // toimen, kamicha and shimocha, the player at the bottom
+  [2, 3, 1, 0].forEach(function (i) {
+    var seat = s.seats[i];
+    str += '<div class="seat' + (m.turn == i ? " turn" : "") + '">' + seatNames[i] + (seat.name ? " " + escape(seat.name) : "") + " " + seat.jikaze + " " + seat.point;
+    if (seat.is_riichi) str += ' <span class="riichi">riichi</span>';
+    if (m.waiting.indexOf(i) >= 0) str += " thinking ...";
+    str += "<br>kawa " + hais(seat.kawa) + "<br>naki " + seat.naki.map(hais).join(" ") + "</div>";
+  });
+  el("table").innerHTML = str;
+  if (!m.actions.some(function (a) { return a.type == "next"; })) el("result").innerHTML = "";
+  actions(m);
+}
+
+function actions(m) {
+  var s = m.state, box = el("actions"), discards = {};
+  box.innerHTML = "";
+  m.actions.forEach(function (a) { if (a.type == "noaction") discards[a.hais[0]] = a.id; });
+  // the tehai, a click on the hai discards it
+  s.tehai.concat(s.tsumohai ? [s.tsumohai] : []).forEach(function (n, i) {
+    var b = document.createElement("button");
+    b.textContent = n;
+    if (i == s.tehai.length) b.style.marginLeft = "1em";
+    if (n in discards) b.onclick = take(discards[n]); else b.disabled = true;
+    box.appendChild(b);
+  });
+  box.appendChild(document.createElement("br"));
+  m.actions.forEach(function (a) {
+    if (a.type == "noaction") return;
+    var b = document.createElement("button");
+    b.textContent = a.type + (a.hais.length ? " " + a.hais.join(" ") : "");
+    b.onclick = take(a.id);
+    box.appendChild(b);
+  });
+  if (m.base_time || m.reserve_time) box.appendChild(document.createTextNode(" time " + m.base_time + "s + " + m.reserve_time + "s"));
+}
+
+function take(id) {
+  return function () {
+    el("log").textContent = "";
+    ws.send(JSON.stringify({type: "action", seq: seq, id: id}));
+  };
+}
+
+function result(m) {
+  var str = "ryuukyoku";
+  if (!m.is_ryuukyoku) {
+    str = seatNames[m.winner] + (m.loser < 0 ? " tsumo " : " ron from " + seatNames[m.loser] + " ") + hais(m.tehai) + " " + hais([m.agari_hai]);
+    if (m.ura_dora.length) str += "<br>ura dora " + hais(m.ura_dora);
+    m.yakus.forEach(function (y) { str += "<br>" + y.name + " " + y.han + " han"; });
+    str += "<br>" + m.fu + " fu " + m.han + " han " + m.limit;
+  }
+  str += "<br>" + m.points.map(function (p, i) { return seatNames[i] + " " + (p > 0 ? "+" : "") + p; }).join(", ");
+  el("result").innerHTML = str;
+}
+
+function matchEnd(m) {
+  el("actions").innerHTML = "";
+  var str = "<br>GAME SET!!";
+  m.ranks.forEach(function (r, i) { str += "<br>" + r + " " + seatNames[i] + " " + m.scores[i]; });
+  el("result").innerHTML += str + "<br>board id: " + m.board_id;
+}
+connect();
+</script>
+</body>
+</html>
+`
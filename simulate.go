@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"mahjong/model/agent"
+	"mahjong/model/board"
+	"mahjong/model/match"
+	"mahjong/simulator"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+)
+
+type agentFlags []string
+
+func (a *agentFlags) String() string {
+	return strings.Join(*a, ", ")
+}
+
+func (a *agentFlags) Set(s string) error {
+	*a = append(*a, s)
+	return nil
+}
+
+// simulate plays the agents against each other headless and prints the statistics.
+func simulate(args []string) {
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	n := fs.Int("n", 100, "the number of games")
+	isHand := fs.Bool("hand", false, "a game is a single hand instead of a whole match")
+	ruleName := fs.String("rule", match.Tonpuusen.Name, "the rule of the match")
+	seed := fs.Int64("seed", time.Now().UnixNano(), "the seed of the walls, the same seed plays the same games")
+	paifuDir := fs.String("paifu", "", "the directory to write the paifu of every game, named by the seed")
+	parallel := fs.Int("parallel", runtime.NumCPU(), "the number of games played at once")
+	agents := agentFlags{}
+	fs.Var(&agents, "agent", "the command line of the agent program, `bot` for the baseline bot. repeat it for every seat, the rest are bots")
+	if err := fs.Parse(args); err != nil {
+		log.Fatal(err)
+	}
+
+	rule, err := match.AtoRule(*ruleName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(agents) > board.MaxNumberOfUsers {
+		log.Fatalf("at most %d agents", board.MaxNumberOfUsers)
+	}
+	for len(agents) < board.MaxNumberOfUsers {
+		agents = append(agents, "bot")
+	}
+	names := []string{}
+	newAgents := []func() (agent.Agent, error){}
+	for i, command := range agents {
+		names = append(names, fmt.Sprintf("%d:%s", i+1, strings.Fields(command)[0]))
+		newAgents = append(newAgents, agentFunc(command))
+	}
+
+	s, err := simulator.New(rule, names, newAgents, *seed, *parallel, *paifuDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	start := time.Now()
+	stats, err := s.Run(*n, *isHand)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Fprintf(os.Stdout, "seed: %d  rule: %s  elapsed: %s\n%s", *seed, rule.Name, time.Since(start).Round(time.Millisecond), stats)
+}
@@ -0,0 +1,371 @@
+package simulator
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"mahjong/model/agent"
+	"mahjong/model/board"
+	"mahjong/model/kawa"
+	"mahjong/model/match"
+	"mahjong/model/naki"
+	"mahjong/model/paifu"
+	"mahjong/model/player"
+	"mahjong/model/tehai"
+	"mahjong/model/yama"
+	"mahjong/server/usecase"
+	"mahjong/storage"
+	"mahjong/utils"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Simulator plays the games between the agents on the boards directly, without any connection.
+type Simulator interface {
+	// Run plays n games, a game is a single hand when isHand, a whole match otherwise
+	Run(n int, isHand bool) (*Stats, error)
+}
+
+type simulatorImpl struct {
+	rule      *match.Rule
+	names     []string
+	newAgents []func() (agent.Agent, error)
+	seed      int64
+	parallel  int
+	paifuDir  string
+}
+
+// New makes the simulator seating the agents of newAgents, named by names, in the order of the slice.
+// The game i is dealt from seed + i, so the same seed plays the same games.
+// The paifu of the game is written in paifuDir named by its seed, not written when it is empty.
+func New(rule *match.Rule, names []string, newAgents []func() (agent.Agent, error), seed int64, parallel int, paifuDir string) (Simulator, error) {
+	if len(names) != board.MaxNumberOfUsers || len(newAgents) != board.MaxNumberOfUsers || parallel < 1 {
+		return nil, SimulatorInvalidArgumentErr
+	}
+	// no thinking time, the agents answer as fast as they can
+	r := *rule
+	r.BaseTime, r.ReserveTime = 0, 0
+	return &simulatorImpl{
+		rule:      &r,
+		names:     names,
+		newAgents: newAgents,
+		seed:      seed,
+		parallel:  parallel,
+		paifuDir:  paifuDir,
+	}, nil
+}
+
+func (s *simulatorImpl) Run(n int, isHand bool) (*Stats, error) {
+	total := newStats(s.names, isHand)
+	games := make(chan int)
+	errs := make(chan error, s.parallel)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < s.parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			agents, err := s.agents()
+			defer closeAgents(agents)
+			if err != nil {
+				errs <- err
+				return
+			}
+			for idx := range games {
+				stats, err := s.play(idx, agents, isHand)
+				if err != nil {
+					errs <- err
+					return
+				}
+				mu.Lock()
+				total.merge(stats)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	var err error
+	for i := 0; i < n && err == nil; i++ {
+		select {
+		case games <- i:
+		case err = <-errs:
+		}
+	}
+	close(games)
+	wg.Wait()
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case err := <-errs:
+		return nil, err
+	default:
+	}
+	return total, nil
+}
+
+func (s *simulatorImpl) agents() ([]agent.Agent, error) {
+	agents := []agent.Agent{}
+	for _, newAgent := range s.newAgents {
+		a, err := newAgent()
+		if err != nil {
+			return agents, err
+		}
+		agents = append(agents, a)
+	}
+	return agents, nil
+}
+
+func closeAgents(agents []agent.Agent) {
+	for _, a := range agents {
+		if c, ok := a.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				log.Println(err)
+			}
+		}
+	}
+}
+
+// play runs the game idx to the end and returns the record of it.
+func (s *simulatorImpl) play(idx int, agents []agent.Agent, isHand bool) (*Stats, error) {
+	seed := s.seed + int64(idx)
+	var pf paifu.Paifu
+	if s.paifuDir != "" {
+		var err error
+		if pf, err = paifu.NewFile(s.paifuDir, strconv.FormatInt(seed, 10)); err != nil {
+			return nil, err
+		}
+	}
+	m := match.New(s.rule, board.MaxNumberOfUsers)
+	b := board.New(board.MaxNumberOfUsers, m, seed, yama.NewWithRand, pf)
+
+	// the agents have no connection
+	write := func(string) error { return nil }
+	read := func([]byte) error { return nil }
+	gu := usecase.NewGameUsecase(storage.NewBoardStorage(), storage.NewSessionStorage(), write, read)
+
+	players := []player.Player{}
+	channels := []chan board.Board{}
+	for range agents {
+		p := player.New(utils.NewUUID(), kawa.New(), tehai.New(), naki.New())
+		channel, err := b.JoinPlayer(p)
+		if err != nil {
+			return nil, err
+		}
+		players = append(players, p)
+		channels = append(channels, channel)
+	}
+
+	stats := newStats(s.names, isHand)
+	stats.Games = 1
+	// the points of the seats after the last hand
+	last := []int{}
+	for range players {
+		last = append(last, s.rule.StartPoint)
+	}
+	errs := make([]error, len(players))
+	// closed on the first error, the board waits for the seat forever with no thinking time
+	done := make(chan struct{})
+	var once sync.Once
+	fail := func(i int, err error) {
+		errs[i] = err
+		once.Do(func() { close(done) })
+	}
+	var wg sync.WaitGroup
+	for i := range players {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			isRecorded := false
+			for {
+				var b board.Board
+				select {
+				case b = <-channels[i]:
+				case <-done:
+					return
+				}
+				// every board in the channel is the same, decide on the latest state
+				for len(channels[i]) != 0 {
+					<-channels[i]
+				}
+				// the result read with the board locked, the others play meanwhile
+				isOver := false
+				if err := b.View(func() error {
+					if b.Result() == nil {
+						isRecorded = false
+						return nil
+					}
+					if isRecorded {
+						return nil
+					}
+					isRecorded = true
+					isOver = isHand || b.Match().IsEnd()
+					// the first seat keeps the record
+					if i == 0 {
+						return stats.record(b, players, last)
+					}
+					return nil
+				}); err != nil {
+					fail(i, err)
+					return
+				}
+				if isOver {
+					return
+				}
+
+				command, err := agent.Decide(agents[i], b, players[i])
+				if err != nil {
+					fail(i, err)
+					return
+				}
+				if command == "" {
+					continue
+				}
+				// a seat sending the wrong commands never ends the game
+				if err := gu.Input(b, players[i], []byte(command)); err != nil {
+					fail(i, err)
+					return
+				}
+			}
+		}(i)
+	}
+	// the channels are not closed, a late broadcast can still be on the way
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return stats, nil
+}
+
+// Stats is the aggregate of the games, Seats are in the order of the agents.
+type Stats struct {
+	IsHand     bool
+	Games      int
+	Hands      int
+	Ryuukyokus int
+	Discards   int
+	// the riichi sticks left on the table at the end of the hands played one by one, paid by the seats and taken by nobody
+	// the top of a match takes the ones left at the end
+	Sticks int
+	Seats  []*SeatStats
+}
+
+type SeatStats struct {
+	Name    string
+	Wins    int
+	DealIns int
+	// the sum of the point changes of every hand, with the riichi sticks paid and taken
+	Point int
+	// the sum of the final points and ranks of the matches
+	FinalPoint int
+	Rank       int
+}
+
+func newStats(names []string, isHand bool) *Stats {
+	s := &Stats{IsHand: isHand, Seats: []*SeatStats{}}
+	for _, name := range names {
+		s.Seats = append(s.Seats, &SeatStats{Name: name})
+	}
+	return s
+}
+
+// record counts the result of the hand on b, players and last, the points after the last hand, are in the order of Seats.
+func (s *Stats) record(b board.Board, players []player.Player, last []int) error {
+	result := b.Result()
+	s.Hands++
+	if result.IsRyuukyoku {
+		s.Ryuukyokus++
+	}
+	for _, tp := range b.Players() {
+		s.Discards += len(tp.Kawa().Hais())
+	}
+
+	isEnd := !s.IsHand && b.Match().IsEnd()
+	if s.IsHand {
+		s.Sticks += b.Match().Kyoutaku()
+	}
+	points := []int{}
+	for _, tp := range b.Players() {
+		points = append(points, tp.Point())
+	}
+	for i, p := range players {
+		turnIdx, err := b.MyTurn(p)
+		if err != nil {
+			return err
+		}
+		seat := s.Seats[i]
+		seat.Point += p.Point() - last[i]
+		last[i] = p.Point()
+		if result.Winner == p {
+			seat.Wins++
+		}
+		if result.Loser == p {
+			seat.DealIns++
+		}
+		if isEnd {
+			seat.FinalPoint += p.Point()
+			seat.Rank += rank(points, turnIdx)
+		}
+	}
+	return nil
+}
+
+func (s *Stats) merge(o *Stats) {
+	s.Games += o.Games
+	s.Hands += o.Hands
+	s.Ryuukyokus += o.Ryuukyokus
+	s.Discards += o.Discards
+	s.Sticks += o.Sticks
+	for i, seat := range s.Seats {
+		seat.Wins += o.Seats[i].Wins
+		seat.DealIns += o.Seats[i].DealIns
+		seat.Point += o.Seats[i].Point
+		seat.FinalPoint += o.Seats[i].FinalPoint
+		seat.Rank += o.Seats[i].Rank
+	}
+}
+
+// rank is 1 for the top, points are by turn index and the tie goes to the one closer to the first oya.
+func rank(points []int, idx int) int {
+	order := []int{}
+	for i := range points {
+		order = append(order, i)
+	}
+	sort.SliceStable(order, func(i, j int) bool { return points[order[i]] > points[order[j]] })
+	for i, o := range order {
+		if o == idx {
+			return i + 1
+		}
+	}
+	return len(points)
+}
+
+func (s *Stats) String() string {
+	str := fmt.Sprintf("games: %d  hands: %d\n", s.Games, s.Hands)
+	if s.Hands == 0 {
+		return str
+	}
+	str += fmt.Sprintf("draw rate: %.1f%%  hand length: %.1f discards\n", percent(s.Ryuukyokus, s.Hands), float64(s.Discards)/float64(s.Hands))
+	// the points of the seats fall short of the start by the sticks
+	str += fmt.Sprintf("riichi sticks left on the table: %d\n\n", s.Sticks)
+	str += fmt.Sprintf("%-16s %8s %8s %10s", "agent", "win", "deal-in", "point/hand")
+	if !s.IsHand {
+		str += fmt.Sprintf(" %8s %6s", "final", "rank")
+	}
+	str += "\n"
+	for _, seat := range s.Seats {
+		str += fmt.Sprintf("%-16s %7.1f%% %7.1f%% %+10.0f", seat.Name, percent(seat.Wins, s.Hands), percent(seat.DealIns, s.Hands), float64(seat.Point)/float64(s.Hands))
+		if !s.IsHand {
+			str += fmt.Sprintf(" %8.0f %6.2f", float64(seat.FinalPoint)/float64(s.Games), float64(seat.Rank)/float64(s.Games))
+		}
+		str += "\n"
+	}
+	return str
+}
+
+func percent(n int, total int) float64 {
+	return float64(n) * 100 / float64(total)
+}
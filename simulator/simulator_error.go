@@ -0,0 +1,7 @@
+package simulator
+
+import "errors"
+
+var (
+	SimulatorInvalidArgumentErr = errors.New("invalid argument")
+)
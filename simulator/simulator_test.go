@@ -0,0 +1,139 @@
+package simulator
+
+import (
+	"errors"
+	"mahjong/model/agent"
+	"mahjong/model/bot"
+	"mahjong/model/match"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newBots() []func() (agent.Agent, error) {
+	newAgents := []func() (agent.Agent, error){}
+	for i := 0; i < 4; i++ {
+		newAgents = append(newAgents, func() (agent.Agent, error) { return bot.New(), nil })
+	}
+	return newAgents
+}
+
+func TestNew(t *testing.T) {
+	cases := []struct {
+		name       string
+		inNames    []string
+		inParallel int
+		outError   error
+	}{
+		{
+			name:       "success",
+			inNames:    []string{"a", "b", "c", "d"},
+			inParallel: 1,
+		},
+		{
+			name:       "failure: not enough names",
+			inNames:    []string{"a"},
+			inParallel: 1,
+			outError:   SimulatorInvalidArgumentErr,
+		},
+		{
+			name:       "failure: no parallel",
+			inNames:    []string{"a", "b", "c", "d"},
+			inParallel: 0,
+			outError:   SimulatorInvalidArgumentErr,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := New(match.Tonpuusen, c.inNames, newBots(), 1, c.inParallel, "")
+			assert.Equal(t, c.outError, err)
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	names := []string{"a", "b", "c", "d"}
+	s1, err := New(match.Tonpuusen, names, newBots(), 1, 2, "")
+	assert.NoError(t, err)
+	stats1, err := s1.Run(4, true)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, stats1.Games)
+	assert.Equal(t, 4, stats1.Hands)
+
+	// the same seed plays the same hands
+	s2, err := New(match.Tonpuusen, names, newBots(), 1, 1, "")
+	assert.NoError(t, err)
+	stats2, err := s2.Run(4, true)
+	assert.NoError(t, err)
+	assert.Equal(t, stats1, stats2)
+
+	// the points paid for the sticks left on the table are taken by nobody
+	sum := 0
+	for _, seat := range stats1.Seats {
+		sum += seat.Point
+	}
+	assert.Equal(t, -stats1.Sticks*match.KyoutakuPoint, sum)
+
+	// the top of a match takes the sticks left at the end, the final points keep the start points
+	s3, err := New(match.Tonpuusen, names, newBots(), 1, 2, "")
+	assert.NoError(t, err)
+	stats3, err := s3.Run(8, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, stats3.Sticks)
+	final := 0
+	for _, seat := range stats3.Seats {
+		final += seat.FinalPoint
+	}
+	assert.Equal(t, 8*len(names)*match.Tonpuusen.StartPoint, final)
+}
+
+func TestRunAgentError(t *testing.T) {
+	cases := []struct {
+		name     string
+		inAgent  agent.Agent
+		outError error
+	}{
+		{
+			name:     "failure: the agent fails",
+			inAgent:  &agent.AgentMock{ErrorMock: errors.New("agent error")},
+			outError: errors.New("agent error"),
+		},
+		{
+			name:     "failure: the agent chooses no action",
+			inAgent:  &agent.AgentMock{IntMock: -1},
+			outError: agent.AgentInvalidChoiceErr,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			newAgents := newBots()
+			newAgents[2] = func() (agent.Agent, error) { return c.inAgent, nil }
+			s, err := New(match.Tonpuusen, []string{"a", "b", "c", "d"}, newAgents, 1, 1, "")
+			assert.NoError(t, err)
+			// the other seats do not wait for the failed one
+			_, err = s.Run(4, false)
+			assert.Equal(t, c.outError, err)
+		})
+	}
+}
+
+func TestRank(t *testing.T) {
+	cases := []struct {
+		name     string
+		inPoints []int
+		inIndex  int
+		outRank  int
+	}{
+		{name: "success: top", inPoints: []int{25000, 40000, 20000, 15000}, inIndex: 1, outRank: 1},
+		{name: "success: last", inPoints: []int{25000, 40000, 20000, 15000}, inIndex: 3, outRank: 4},
+		{name: "success: tie", inPoints: []int{25000, 25000, 25000, 25000}, inIndex: 2, outRank: 3},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.outRank, rank(c.inPoints, c.inIndex))
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"mahjong/model/match"
+	"sync"
+
+	"github.com/k-jun/northpole/room"
+)
+
+// LobbyRoom is a room listed in the lobby, the room itself is kept in the room storage of northpole.
+type LobbyRoom struct {
+	Room room.Room
+	Rule *match.Rule
+	// the join code of a private room, empty for the quick match
+	Code string
+	// the nicknames of the users waiting in the room
+	Names []string
+}
+
+type LobbyStorage interface {
+	Add(*LobbyRoom) error
+	// Find returns the open private room of the join code
+	Find(string) (*LobbyRoom, error)
+	// List returns the open rooms in the order they were made
+	List() []*LobbyRoom
+	Join(roomID string, userID string, name string) error
+	// Leave takes the user out of the names, nothing to do for the room closed
+	Leave(roomID string, userID string)
+}
+
+type lobbyUser struct {
+	id   string
+	name string
+}
+
+type lobbyEntry struct {
+	room  *LobbyRoom
+	users []*lobbyUser
+}
+
+type lobbyStorageImpl struct {
+	sync.Mutex
+	entries []*lobbyEntry
+}
+
+func NewLobbyStorage() LobbyStorage {
+	return &lobbyStorageImpl{entries: []*lobbyEntry{}}
+}
+
+// prune drops the rooms closed by the start of the match or by the last user leaving.
+func (ls *lobbyStorageImpl) prune() {
+	entries := []*lobbyEntry{}
+	for _, e := range ls.entries {
+		if e.room.Room.IsOpen() {
+			entries = append(entries, e)
+		}
+	}
+	ls.entries = entries
+}
+
+func (ls *lobbyStorageImpl) find(roomID string) *lobbyEntry {
+	for _, e := range ls.entries {
+		if e.room.Room.ID() == roomID {
+			return e
+		}
+	}
+	return nil
+}
+
+func (ls *lobbyStorageImpl) Add(r *LobbyRoom) error {
+	ls.Lock()
+	defer ls.Unlock()
+	ls.prune()
+	for _, e := range ls.entries {
+		if e.room.Room.ID() == r.Room.ID() || (r.Code != "" && e.room.Code == r.Code) {
+			return LobbyStorageAlreadyExistErr
+		}
+	}
+	ls.entries = append(ls.entries, &lobbyEntry{room: r, users: []*lobbyUser{}})
+	return nil
+}
+
+func (ls *lobbyStorageImpl) Find(code string) (*LobbyRoom, error) {
+	ls.Lock()
+	defer ls.Unlock()
+	ls.prune()
+	for _, e := range ls.entries {
+		if code != "" && e.room.Code == code {
+			return e.copy(), nil
+		}
+	}
+	return nil, LobbyStorageNotExistErr
+}
+
+func (ls *lobbyStorageImpl) List() []*LobbyRoom {
+	ls.Lock()
+	defer ls.Unlock()
+	ls.prune()
+	rooms := []*LobbyRoom{}
+	for _, e := range ls.entries {
+		rooms = append(rooms, e.copy())
+	}
+	return rooms
+}
+
+func (ls *lobbyStorageImpl) Join(roomID string, userID string, name string) error {
+	ls.Lock()
+	defer ls.Unlock()
+	e := ls.find(roomID)
+	if e == nil {
+		return LobbyStorageNotExistErr
+	}
+	e.users = append(e.users, &lobbyUser{id: userID, name: name})
+	return nil
+}
+
+func (ls *lobbyStorageImpl) Leave(roomID string, userID string) {
+	ls.Lock()
+	defer ls.Unlock()
+	e := ls.find(roomID)
+	if e == nil {
+		return
+	}
+	for i, u := range e.users {
+		if u.id == userID {
+			e.users = append(e.users[:i], e.users[i+1:]...)
+			return
+		}
+	}
+}
+
+// copy is the room with the names at the time, not changed by the users joining later.
+func (e *lobbyEntry) copy() *LobbyRoom {
+	r := *e.room
+	r.Names = []string{}
+	for _, u := range e.users {
+		r.Names = append(r.Names, u.name)
+	}
+	return &r
+}
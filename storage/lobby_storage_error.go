@@ -0,0 +1,8 @@
+package storage
+
+import "errors"
+
+var (
+	LobbyStorageAlreadyExistErr = errors.New("a room having the id or the code already exist")
+	LobbyStorageNotExistErr     = errors.New("a room having the id or the code not exist")
+)
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"mahjong/model/player"
+	"sync"
+)
+
+// Session is the seat a session token points to.
+type Session struct {
+	BoardID string
+	Player  player.Player
+}
+
+type SessionStorage interface {
+	Add(string, *Session) error
+	Remove(string) error
+	Find(string) (*Session, error)
+}
+
+type sessionStorageImpl struct {
+	sync.Mutex
+	sessions map[string]*Session
+}
+
+func NewSessionStorage() SessionStorage {
+	return &sessionStorageImpl{sessions: make(map[string]*Session)}
+}
+
+func (ss *sessionStorageImpl) Add(token string, s *Session) error {
+	ss.Lock()
+	defer ss.Unlock()
+	if ss.sessions[token] != nil {
+		return SessionStorageAlreadyExistErr
+	}
+
+	ss.sessions[token] = s
+	return nil
+}
+
+func (ss *sessionStorageImpl) Remove(token string) error {
+	ss.Lock()
+	defer ss.Unlock()
+	if ss.sessions[token] == nil {
+		return SessionStorageNotExistErr
+	}
+
+	delete(ss.sessions, token)
+	return nil
+}
+
+func (ss *sessionStorageImpl) Find(token string) (*Session, error) {
+	ss.Lock()
+	defer ss.Unlock()
+	if ss.sessions[token] == nil {
+		return nil, SessionStorageNotExistErr
+	}
+
+	return ss.sessions[token], nil
+}
@@ -0,0 +1,8 @@
+package storage
+
+import "errors"
+
+var (
+	SessionStorageAlreadyExistErr = errors.New("a session having the token already exist")
+	SessionStorageNotExistErr     = errors.New("a session having the token not exist")
+)
@@ -1,7 +1,10 @@
 package utils
 
 import (
+	"crypto/rand"
+	"encoding/binary"
 	"mahjong/model/hai"
+	"math/big"
 
 	"github.com/google/uuid"
 )
@@ -10,6 +13,29 @@ func NewUUID() uuid.UUID {
 	return uuid.New()
 }
 
+// the letters of the join codes, without the ones mistaken for each other like 0 and O
+var codeLetters = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// NewCode is a random join code of n letters.
+func NewCode(n int) (string, error) {
+	code := ""
+	for i := 0; i < n; i++ {
+		idx, err := rand.Int(rand.Reader, big.NewInt(int64(len(codeLetters))))
+		if err != nil {
+			return "", err
+		}
+		code += string(codeLetters[idx.Int64()])
+	}
+	return code, nil
+}
+
+// NewSeed is a random seed of the wall, not to be guessed from the time of the game.
+func NewSeed() (int64, error) {
+	var seed int64
+	err := binary.Read(rand.Reader, binary.LittleEndian, &seed)
+	return seed, err
+}
+
 func DrawTehai(hais []*hai.Hai) string {
 	l := len(hais)
 	str := ""
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"log"
+	"mahjong/model/yama"
+)
+
+// verify checks the wall and the salt revealed after the hand against the commitment shown at the start.
+func verify(args []string) {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	commitment := fs.String("commitment", "", "the commitment shown at the start of the hand")
+	salt := fs.String("salt", "", "the salt revealed after the hand")
+	wall := fs.String("wall", "", "the wall revealed after the hand, the names of the hais separated by spaces")
+	if err := fs.Parse(args); err != nil {
+		log.Fatal(err)
+	}
+
+	s, err := hex.DecodeString(*salt)
+	if err != nil {
+		log.Fatal(err)
+	}
+	w, err := yama.AtoWall(*wall)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := yama.Verify(*commitment, w, s); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("ok, the wall matches the commitment")
+}